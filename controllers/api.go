@@ -1,17 +1,35 @@
 package controllers
 
 import (
+	"errors"
+	"librarysystem/middleware"
 	"librarysystem/models"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// APIBooksGet 获取所有图书
+// APIBooksGet 分页获取图书，总数和下一页游标通过 X-Total-Count、X-Next-Cursor 响应头返回
 func APIBooksGet(c *gin.Context) {
-	books := models.Books
-	c.JSON(http.StatusOK, books)
+	query := parseBookQuery(c, models.DefaultPageSize)
+	if query.Query == "" {
+		// 兼容旧版页面脚本使用的 query 参数
+		query.Query = c.Query("query")
+	}
+
+	page, err := models.FindBooks(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.Itoa(page.Total))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.JSON(http.StatusOK, page.Books)
 }
 
 // APIBookGet 获取单本图书
@@ -37,9 +55,73 @@ func APICategoriesGet(c *gin.Context) {
 	c.JSON(http.StatusOK, categories)
 }
 
-// APIBorrowRecordsGet 获取借阅记录
+// parseBorrowFilter 从查询参数解析借阅记录筛选条件，日期格式为 2006-01-02，to 包含当天
+func parseBorrowFilter(c *gin.Context) (models.BorrowFilter, error) {
+	var filter models.BorrowFilter
+
+	if v := c.Query("user_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return filter, errors.New("无效的用户ID")
+		}
+		filter.UserID = id
+	}
+	if v := c.Query("book_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			return filter, errors.New("无效的图书ID")
+		}
+		filter.BookID = id
+	}
+	filter.Status = models.BorrowStatus(c.Query("status"))
+
+	if v := c.Query("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, errors.New("无效的开始日期")
+		}
+		filter.From = from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return filter, errors.New("无效的结束日期")
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	return filter, filter.Validate()
+}
+
+// scopeBorrowFilter 按用户角色限制查询范围，读者只能查询自己的借阅记录
+func scopeBorrowFilter(user *models.User, filter *models.BorrowFilter) error {
+	if isLibrarian(user) {
+		return nil
+	}
+	if filter.UserID != 0 && filter.UserID != user.ID {
+		return errors.New("无权查看其他用户的借阅记录")
+	}
+	filter.UserID = user.ID
+	return nil
+}
+
+// APIBorrowRecordsGet 获取借阅记录，需要API令牌，读者只能查看自己的记录
 func APIBorrowRecordsGet(c *gin.Context) {
-	records := models.BorrowRecords
+	filter, err := parseBorrowFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := scopeBorrowFilter(middleware.APIUser(c), &filter); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	records, err := models.FindBorrowRecords(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询借阅记录失败"})
+		return
+	}
 
 	// 创建用户名和图书标题映射
 	userMap := make(map[int]string)
@@ -73,7 +155,7 @@ func APIBorrowRecordsGet(c *gin.Context) {
 		BookTitle string `json:"book_title"`
 	}
 
-	var response []RecordResponse
+	response := []RecordResponse{}
 	for _, record := range records {
 		response = append(response, RecordResponse{
 			BorrowRecord: record,
@@ -83,4 +165,4 @@ func APIBorrowRecordsGet(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
@@ -0,0 +1,554 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+)
+
+// TokenRequest 签发API令牌请求
+type TokenRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Name     string `json:"name"`
+}
+
+// BookRequest 创建或更新图书请求，校验规则与 BookForm 一致
+type BookRequest struct {
+	Title         string          `json:"title" binding:"required"`
+	Author        string          `json:"author" binding:"required_without=Contributors"`
+	ISBN          string          `json:"isbn" binding:"required"`
+	PublishedYear int             `json:"published_year" binding:"required,min=1000,max=2100"`
+	Category      string          `json:"category" binding:"required"`
+	Description   string          `json:"description" binding:"required"`
+	CoverURL      string          `json:"cover_url" binding:"omitempty,url"`
+	Quantity      *int            `json:"quantity" binding:"required,min=0"`
+	Contributors  []CreditRequest `json:"contributors" binding:"omitempty,dive"`
+	Subjects      []string        `json:"subjects"`                        // 主题词，省略时保持不变
+	Tags          []string        `json:"tags"`                            // 标签，省略时保持不变
+	CallNumber    *string         `json:"call_number"`                     // 索书号，省略时保持不变
+	ShelfLocation *string         `json:"shelf_location"`                  // 默认架位，省略时保持不变
+	Series        *string         `json:"series"`                          // 所属丛书，省略时保持不变，空字符串表示移出丛书
+	Volume        int             `json:"volume" binding:"min=0,max=9999"` // 丛书中的卷次，为0时自动编排
+}
+
+// validate 在写入图书之前校验请求中的责任者、标签、索书号和丛书，
+// 避免图书已经保存而附属信息写入失败；bookID 为0表示新建图书
+func (req *BookRequest) validate(bookID int) error {
+	if err := req.validateShelving(); err != nil {
+		return err
+	}
+	if credits := requestCreditsInput(req.Contributors); credits != nil {
+		if err := models.ValidateBookCredits(credits); err != nil {
+			return err
+		}
+	}
+	if tags := requestTagsInput(*req); tags != nil {
+		if err := models.ValidateBookTags(tags); err != nil {
+			return err
+		}
+	}
+	if req.Series != nil {
+		if err := models.ValidateBookSeries(bookID, *req.Series, req.Volume); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorText 请求中列出了作者时以 contributors 为准，否则使用 author 字段
+func (req *BookRequest) authorText() string {
+	var names []string
+	for _, credit := range req.Contributors {
+		if credit.Role == string(models.CreditAuthor) {
+			names = append(names, credit.Name)
+		}
+	}
+	if len(names) == 0 {
+		return req.Author
+	}
+	return strings.Join(names, "、")
+}
+
+// BorrowRequest 借阅请求，按条码或图书ID借出
+type BorrowRequest struct {
+	UserID  int    `json:"user_id"`
+	BookID  int    `json:"book_id"`
+	Barcode string `json:"barcode"`
+}
+
+// UserRequest 创建用户请求
+type UserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Role     string `json:"role" binding:"required"`
+}
+
+// RoleRequest 修改用户角色请求
+type RoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// apiData 返回统一格式的成功响应
+func apiData(c *gin.Context, status int, data interface{}) {
+	c.JSON(status, gin.H{"data": data})
+}
+
+// apiModelError 将业务层错误转换为错误响应
+func apiModelError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrBookNotFound),
+		errors.Is(err, models.ErrUserNotFound),
+		errors.Is(err, models.ErrBorrowRecordNotFound),
+		errors.Is(err, models.ErrAPITokenNotFound),
+		errors.Is(err, models.ErrContributorNotFound),
+		errors.Is(err, models.ErrTagNotFound),
+		errors.Is(err, models.ErrSeriesNotFound),
+		errors.Is(err, models.ErrReviewNotFound):
+		middleware.APIError(c, http.StatusNotFound, "not_found", err.Error())
+	default:
+		middleware.APIError(c, http.StatusUnprocessableEntity, "unprocessable", err.Error())
+	}
+}
+
+// apiParamID 读取路径中的ID参数，无效时返回错误响应
+func apiParamID(c *gin.Context, what string) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_id", "无效的"+what+"ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// isLibrarian 判断用户是否具有图书管理员权限
+func isLibrarian(user *models.User) bool {
+	return user.Role == models.RoleLibrarian || user.Role == models.RoleAdmin
+}
+
+// APIv1TokenPost 处理POST /api/v1/auth/token
+func APIv1TokenPost(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请提供用户名和密码")
+		return
+	}
+
+	// 验证用户名和密码
+	user, err := models.GetUserByUsername(req.Username)
+	if err != nil || !user.CheckPassword(req.Password) {
+		middleware.APIError(c, http.StatusUnauthorized, "invalid_credentials", "用户名或密码错误")
+		return
+	}
+
+	// 签发令牌，明文只返回这一次
+	plain, token, err := models.CreateAPIToken(user.ID, req.Name)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusCreated, gin.H{
+		"token":      plain,
+		"token_info": token,
+		"user":       user,
+	})
+}
+
+// APIv1TokensGet 处理GET /api/v1/auth/tokens
+func APIv1TokensGet(c *gin.Context) {
+	user := middleware.APIUser(c)
+	apiData(c, http.StatusOK, models.GetAPITokensByUserID(user.ID))
+}
+
+// APIv1TokenDelete 处理DELETE /api/v1/auth/tokens/:id
+func APIv1TokenDelete(c *gin.Context) {
+	id, ok := apiParamID(c, "令牌")
+	if !ok {
+		return
+	}
+
+	if err := models.RevokeAPIToken(id, middleware.APIUser(c).ID); err != nil {
+		apiModelError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// APIv1MeGet 处理GET /api/v1/me
+func APIv1MeGet(c *gin.Context) {
+	user := middleware.APIUser(c)
+	balance, err := models.GetFineBalance(user.ID)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询罚款余额失败")
+		return
+	}
+	apiData(c, http.StatusOK, gin.H{
+		"user":         user,
+		"fine_balance": balance,
+	})
+}
+
+// APIv1BooksGet 处理GET /api/v1/books，支持页码或游标分页，并返回各维度的命中统计
+func APIv1BooksGet(c *gin.Context) {
+	query := parseBookQuery(c, models.DefaultPageSize)
+	page, err := models.FindBooks(query)
+	if err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+
+	facets, err := models.FindBookFacets(query)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "统计图书分布失败")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": page.Books,
+		"meta": gin.H{
+			"total":       page.Total,
+			"page":        page.Page,
+			"size":        page.Size,
+			"total_pages": page.TotalPages,
+			"next_cursor": page.NextCursor,
+		},
+		"facets": facets,
+	})
+}
+
+// APIv1BookGet 处理GET /api/v1/books/:id
+func APIv1BookGet(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	book, err := models.GetBookByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusOK, gin.H{
+		"book":               book,
+		"available_quantity": book.GetAvailableQuantity(),
+		"copies":             models.GetCopiesByBookID(id),
+		"contributors":       models.GetBookCredits(id),
+		"tags":               models.GetBookTags(id),
+		"series":             models.GetBookSeries(id),
+	})
+}
+
+// APIv1CategoriesGet 处理GET /api/v1/categories
+func APIv1CategoriesGet(c *gin.Context) {
+	apiData(c, http.StatusOK, models.GetAllCategories())
+}
+
+// APIv1BookPost 处理POST /api/v1/books
+func APIv1BookPost(c *gin.Context) {
+	var req BookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请正确填写所有必填字段")
+		return
+	}
+	if err := req.validate(0); err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	created, err := models.CreateBook(req.Title, req.authorText(), req.ISBN, req.PublishedYear,
+		req.Category, req.Description, req.CoverURL, *req.Quantity)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	book, err := apiSetBookLinks(created, req)
+	if err != nil {
+		// 附属信息保存失败时撤销添加
+		rollbackNewBook(created.ID)
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusCreated, book)
+}
+
+// apiSetBookLinks 按请求保存责任者、标签、索书号和丛书，出错时注明未保存的部分
+func apiSetBookLinks(book *models.Book, req BookRequest) (*models.Book, error) {
+	if err := apiSetBookCredits(book.ID, req.Contributors); err != nil {
+		return nil, fmt.Errorf("保存责任者失败: %w", err)
+	}
+	if err := apiSetBookTags(book.ID, req); err != nil {
+		return nil, fmt.Errorf("保存标签失败: %w", err)
+	}
+	book, err := apiSetBookShelving(book, req)
+	if err != nil {
+		return nil, fmt.Errorf("保存索书号失败: %w", err)
+	}
+	if err := apiSetBookSeries(book.ID, req); err != nil {
+		return nil, fmt.Errorf("保存丛书失败: %w", err)
+	}
+	return book, nil
+}
+
+// APIv1BookPut 处理PUT /api/v1/books/:id
+func APIv1BookPut(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	var req BookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请正确填写所有必填字段")
+		return
+	}
+	if err := req.validate(id); err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	book, err := models.UpdateBook(id, req.Title, req.authorText(), req.ISBN, req.PublishedYear,
+		req.Category, req.Description, req.CoverURL, *req.Quantity)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	if book, err = apiSetBookLinks(book, req); err != nil {
+		apiModelError(c, fmt.Errorf("图书信息已更新，但%w", err))
+		return
+	}
+
+	apiData(c, http.StatusOK, book)
+}
+
+// APIv1BookDelete 处理DELETE /api/v1/books/:id
+func APIv1BookDelete(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteBook(id); err != nil {
+		apiModelError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// APIv1BorrowsGet 处理GET /api/v1/borrows，支持 user_id、book_id、status、from、to 筛选，读者只能查看自己的借阅记录
+func APIv1BorrowsGet(c *gin.Context) {
+	filter, err := parseBorrowFilter(c)
+	if err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	if err := scopeBorrowFilter(middleware.APIUser(c), &filter); err != nil {
+		middleware.APIError(c, http.StatusForbidden, "forbidden", err.Error())
+		return
+	}
+
+	records, err := models.FindBorrowRecords(filter)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询借阅记录失败")
+		return
+	}
+
+	apiData(c, http.StatusOK, records)
+}
+
+// APIv1BorrowGet 处理GET /api/v1/borrows/:id
+func APIv1BorrowGet(c *gin.Context) {
+	record, ok := apiBorrowRecord(c)
+	if !ok {
+		return
+	}
+
+	apiData(c, http.StatusOK, gin.H{
+		"record":   record,
+		"renewals": models.GetRenewalsByRecordID(record.ID),
+		"fines":    models.GetFineEntriesByRecordID(record.ID),
+	})
+}
+
+// APIv1BorrowPost 处理POST /api/v1/borrows，读者只能为自己借阅
+func APIv1BorrowPost(c *gin.Context) {
+	user := middleware.APIUser(c)
+
+	var req BorrowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请求格式错误")
+		return
+	}
+
+	if req.UserID == 0 {
+		req.UserID = user.ID
+	}
+	if req.UserID != user.ID && !isLibrarian(user) {
+		middleware.APIError(c, http.StatusForbidden, "forbidden", "只能为自己办理借阅")
+		return
+	}
+
+	var record *models.BorrowRecord
+	var err error
+	switch {
+	case req.Barcode != "":
+		record, err = models.CreateBorrowRecordByBarcode(req.UserID, req.Barcode, time.Now())
+	case req.BookID != 0:
+		record, err = models.CreateBorrowRecord(req.UserID, req.BookID, time.Now())
+	default:
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请提供图书ID或条码")
+		return
+	}
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusCreated, record)
+}
+
+// APIv1ReturnPost 处理POST /api/v1/borrows/:id/return
+func APIv1ReturnPost(c *gin.Context) {
+	id, ok := apiParamID(c, "借阅记录")
+	if !ok {
+		return
+	}
+
+	record, err := models.ReturnBook(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusOK, gin.H{
+		"record": record,
+		"fines":  models.GetFineEntriesByRecordID(id),
+	})
+}
+
+// APIv1RenewPost 处理POST /api/v1/borrows/:id/renew
+func APIv1RenewPost(c *gin.Context) {
+	record, ok := apiBorrowRecord(c)
+	if !ok {
+		return
+	}
+
+	record, err := models.RenewBorrowRecord(record.ID, middleware.APIUser(c).ID)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusOK, record)
+}
+
+// apiBorrowRecord 读取路径中的借阅记录，并校验当前用户是否有权访问
+func apiBorrowRecord(c *gin.Context) (*models.BorrowRecord, bool) {
+	id, ok := apiParamID(c, "借阅记录")
+	if !ok {
+		return nil, false
+	}
+
+	record, err := models.GetBorrowRecordByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return nil, false
+	}
+
+	user := middleware.APIUser(c)
+	if record.UserID != user.ID && !isLibrarian(user) {
+		middleware.APIError(c, http.StatusForbidden, "forbidden", "无权操作此借阅记录")
+		return nil, false
+	}
+	return record, true
+}
+
+// APIv1UsersGet 处理GET /api/v1/users
+func APIv1UsersGet(c *gin.Context) {
+	apiData(c, http.StatusOK, models.GetAllUsers())
+}
+
+// APIv1UserGet 处理GET /api/v1/users/:id
+func APIv1UserGet(c *gin.Context) {
+	id, ok := apiParamID(c, "用户")
+	if !ok {
+		return
+	}
+
+	user, err := models.GetUserByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	balance, err := models.GetFineBalance(id)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询罚款余额失败")
+		return
+	}
+
+	apiData(c, http.StatusOK, gin.H{
+		"user":           user,
+		"fine_balance":   balance,
+		"active_borrows": models.GetActiveBorrowRecordsByUserID(id),
+	})
+}
+
+// APIv1UserPost 处理POST /api/v1/users
+func APIv1UserPost(c *gin.Context) {
+	var req UserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请正确填写用户名、邮箱、密码和角色")
+		return
+	}
+
+	user, err := models.CreateUser(req.Username, req.Email, req.Password, models.UserRole(req.Role))
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusCreated, user)
+}
+
+// APIv1UserRolePut 处理PUT /api/v1/users/:id/role
+func APIv1UserRolePut(c *gin.Context) {
+	id, ok := apiParamID(c, "用户")
+	if !ok {
+		return
+	}
+
+	var req RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请提供角色")
+		return
+	}
+
+	// 防止管理员取消自己的管理员权限
+	if id == middleware.APIUser(c).ID && models.UserRole(req.Role) != models.RoleAdmin {
+		middleware.APIError(c, http.StatusUnprocessableEntity, "unprocessable", "不能修改自己的管理员角色")
+		return
+	}
+
+	user, err := models.GetUserByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	if err := user.UpdateRole(models.UserRole(req.Role)); err != nil {
+		apiModelError(c, err)
+		return
+	}
+
+	apiData(c, http.StatusOK, user)
+}
@@ -1,8 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"librarysystem/models"
@@ -17,11 +23,240 @@ type BookForm struct {
 	PublishedYear int    `form:"published_year" binding:"required,min=1000,max=2100"`
 	Category      string `form:"category" binding:"required"`
 	Description   string `form:"description" binding:"required"`
-	CoverURL      string `form:"cover_url" binding:"required,url"`
-	Quantity      int    `form:"quantity" binding:"required,min=1"`
+	CoverURL      string `form:"cover_url" binding:"omitempty,url"`
+	Quantity      *int   `form:"quantity" binding:"required,min=0"`
+	Translators   string `form:"translators"`
+	Editors       string `form:"editors"`
+	Subjects      string `form:"subjects"`
+	Tags          string `form:"tags"`
+	CallNumber    string `form:"call_number"`
+	ShelfLocation string `form:"shelf_location"`
+	Series        string `form:"series"`
+	Volume        int    `form:"volume" binding:"min=0,max=9999"`
+	RemoveCover   bool   `form:"remove_cover"`
 	CSRFToken     string `form:"csrf_token"`
 }
 
+// validate 在写入图书之前校验译者、编者、标签、索书号和丛书，bookID 为0表示新建图书
+func (form *BookForm) validate(bookID int) error {
+	if err := models.ValidateShelving(form.CallNumber, form.ShelfLocation); err != nil {
+		return err
+	}
+	if err := models.ValidateBookCredits(bookCreditsInput(*form)); err != nil {
+		return err
+	}
+	if err := models.ValidateBookTags(bookTagsInput(*form)); err != nil {
+		return err
+	}
+	return models.ValidateBookSeries(bookID, form.Series, form.Volume)
+}
+
+// saveBookLinks 保存表单中的译者、编者、标签、索书号和丛书，出错时注明未保存的部分
+func saveBookLinks(bookID int, form BookForm) error {
+	if err := models.SetBookCredits(bookID, bookCreditsInput(form)); err != nil {
+		return fmt.Errorf("保存译者、编者失败: %w", err)
+	}
+	if err := models.SetBookTags(bookID, bookTagsInput(form)); err != nil {
+		return fmt.Errorf("保存标签失败: %w", err)
+	}
+	if _, err := models.SetBookShelving(bookID, form.CallNumber, form.ShelfLocation); err != nil {
+		return fmt.Errorf("保存索书号失败: %w", err)
+	}
+	if err := models.SetBookSeries(bookID, form.Series, form.Volume); err != nil {
+		return fmt.Errorf("保存丛书失败: %w", err)
+	}
+	return nil
+}
+
+// rollbackNewBook 附属信息保存失败时删除刚创建的图书，避免留下不完整的记录
+func rollbackNewBook(bookID int) {
+	if err := models.DeleteBook(bookID); err != nil {
+		log.Printf("删除未保存完整的图书 %d 失败: %v", bookID, err)
+	}
+}
+
+// renderBookForm 保留已填写的内容重新显示图书表单，ISBN相关错误标注在ISBN输入框上，id 为0时为添加图书
+func renderBookForm(c *gin.Context, mg *utils.SessionManager, form BookForm, id int, err error) {
+	book := &models.Book{
+		ID:            id,
+		Title:         form.Title,
+		Author:        form.Author,
+		ISBN:          form.ISBN,
+		PublishedYear: form.PublishedYear,
+		Category:      form.Category,
+		Description:   form.Description,
+		CoverURL:      form.CoverURL,
+		CallNumber:    form.CallNumber,
+		ShelfLocation: form.ShelfLocation,
+	}
+	if form.Quantity != nil {
+		book.Quantity = *form.Quantity
+	}
+	// 编辑时继续显示已上传的封面
+	if existing, _ := models.GetBookByID(id); existing != nil {
+		book.CoverImage = existing.CoverImage
+	}
+
+	data := gin.H{
+		"title":       "编辑图书",
+		"book":        book,
+		"csrf_token":  mg.GenerateCSRFToken(c),
+		"is_add":      id == 0,
+		"translators": form.Translators,
+		"editors":     form.Editors,
+		"subjects":    form.Subjects,
+		"tags":        form.Tags,
+		"series":      form.Series,
+		"volume":      form.Volume,
+	}
+	if id == 0 {
+		data["title"] = "添加图书"
+	}
+	bookSeriesFormData(data, nil)
+	classificationFormData(data, nil, form.Category)
+	if errors.Is(err, models.ErrInvalidISBN) || errors.Is(err, models.ErrDuplicateISBN) {
+		data["isbn_error"] = err.Error()
+	} else {
+		data["error"] = err.Error()
+	}
+	c.HTML(http.StatusUnprocessableEntity, "admin/edit_book.html", data)
+}
+
+// parseBookQuery 从查询参数解析图书列表的筛选、排序和分页条件
+func parseBookQuery(c *gin.Context, size int) models.BookQuery {
+	query := models.BookQuery{
+		Query:    c.Query("q"),
+		Category: c.Query("category"),
+		Author:   c.Query("author"),
+		Sort:     models.BookSort(c.Query("sort")),
+		Desc:     c.Query("order") == "desc",
+		Size:     size,
+		Cursor:   c.Query("cursor"),
+	}
+
+	query.Decade, _ = strconv.Atoi(c.Query("decade"))
+	query.YearFrom, _ = strconv.Atoi(c.Query("year_from"))
+	query.YearTo, _ = strconv.Atoi(c.Query("year_to"))
+	query.Page, _ = strconv.Atoi(c.Query("page"))
+	if s, err := strconv.Atoi(c.Query("size")); err == nil && s > 0 {
+		query.Size = s
+	}
+
+	switch c.Query("available") {
+	case "1", "true", "on":
+		query.AvailableOnly = true
+	}
+	for _, tag := range c.QueryArray("tag") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			query.Tags = append(query.Tags, tag)
+		}
+	}
+	// 按分类浏览时包含全部下级分类
+	if code := c.Query("class"); code != "" {
+		query.ClassIDs = classSubtreeIDs(code)
+	}
+	return query
+}
+
+// pageURL 生成保留当前筛选条件的分页链接前缀，模板中在其后追加页码
+func pageURL(c *gin.Context) template.URL {
+	values := url.Values{}
+	for key, value := range c.Request.URL.Query() {
+		if key != "page" && key != "cursor" {
+			values[key] = value
+		}
+	}
+
+	prefix := c.Request.URL.Path + "?"
+	if encoded := values.Encode(); encoded != "" {
+		prefix += encoded + "&"
+	}
+	return template.URL(prefix + "page=")
+}
+
+// FacetGroup 页面上一个统计维度的筛选项
+type FacetGroup struct {
+	Title   string
+	Options []FacetOption
+}
+
+// FacetOption 统计维度上的一个可点击取值，已选中时点击取消该筛选
+type FacetOption struct {
+	models.FacetCount
+	URL template.URL
+}
+
+// facetURL 生成切换某个筛选参数后的链接，value 为空时删除该参数
+func facetURL(c *gin.Context, key, value string) template.URL {
+	values := c.Request.URL.Query()
+	values.Del("page")
+	values.Del("cursor")
+	if value == "" {
+		values.Del(key)
+	} else {
+		values.Set(key, value)
+	}
+
+	if encoded := values.Encode(); encoded != "" {
+		return template.URL(c.Request.URL.Path + "?" + encoded)
+	}
+	return template.URL(c.Request.URL.Path)
+}
+
+// facetGroups 将统计结果转换为页面筛选项
+func facetGroups(c *gin.Context, facets *models.BookFacets) []FacetGroup {
+	build := func(title, key string, counts []models.FacetCount) FacetGroup {
+		group := FacetGroup{Title: title}
+		for _, count := range counts {
+			value := count.Value
+			if count.Selected {
+				value = ""
+			}
+			if key == "available" {
+				// 只支持筛选可借图书
+				if count.Value != "available" {
+					group.Options = append(group.Options, FacetOption{FacetCount: count})
+					continue
+				}
+				if value != "" {
+					value = "1"
+				}
+			}
+			group.Options = append(group.Options, FacetOption{FacetCount: count, URL: facetURL(c, key, value)})
+		}
+		return group
+	}
+
+	return []FacetGroup{
+		build("分类", "category", facets.Category),
+		build("作者", "author", facets.Author),
+		build("出版年代", "decade", facets.Decade),
+		build("可借状态", "available", facets.Availability),
+	}
+}
+
+// bookListData 图书列表页面公用的筛选和分页数据
+func bookListData(c *gin.Context, query models.BookQuery, page *models.BookPage) gin.H {
+	return gin.H{
+		"books":        page.Books,
+		"page":         page,
+		"page_url":     pageURL(c),
+		"current_page": page.Page,
+		"total_pages":  page.TotalPages,
+		"query":        query.Query,
+		"category":     query.Category,
+		"class":        c.Query("class"),
+		"tag_filters":  tagFilters(c, query.Tags),
+		"author":       query.Author,
+		"decade":       c.Query("decade"),
+		"year_from":    c.Query("year_from"),
+		"year_to":      c.Query("year_to"),
+		"available":    query.AvailableOnly,
+		"sort":         string(query.Sort),
+		"order":        c.Query("order"),
+	}
+}
+
 // IndexGet 处理GET /
 func IndexGet(c *gin.Context) {
 	// 获取特色图书（前4本）
@@ -41,36 +276,39 @@ func IndexGet(c *gin.Context) {
 		"title":          "首页",
 		"featured_books": featuredBooks,
 		"categories":     categories,
+		"tag_cloud":      models.GetTagCloud(40),
 	})
 }
 
 // BooksGet 处理GET /books
 func BooksGet(c *gin.Context) {
-	// 获取查询参数
-	query := c.Query("q")
-	category := c.Query("category")
-
-	// 根据查询参数获取图书
-	var books []*models.Book
-	if query != "" {
-		books = models.SearchBooks(query)
-	} else if category != "" {
-		books = models.GetBooksByCategory(category)
-	} else {
-		books = models.GetAllBooks()
+	// 解析筛选、排序和分页参数
+	query := parseBookQuery(c, 12)
+
+	// 分页查询图书
+	page, err := models.FindBooks(query)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": err.Error(),
+		})
+		return
 	}
 
-	// 获取所有分类
-	categories := models.GetAllCategories()
+	// 统计各维度的分布
+	facets, err := models.FindBookFacets(query)
+	if err != nil {
+		log.Printf("统计图书分布失败: %v", err)
+		facets = &models.BookFacets{}
+	}
 
 	// 渲染图书列表页面
-	c.HTML(http.StatusOK, "book_list.html", gin.H{
-		"title":      "图书列表",
-		"books":      books,
-		"categories": categories,
-		"query":      query,
-		"category":   category,
-	})
+	data := bookListData(c, query, page)
+	data["title"] = "图书列表"
+	data["facets"] = facetGroups(c, facets)
+	data["category_facets"] = facets.Category
+	data["selected_category"] = query.Category
+	classBrowseData(c, query, data)
+	c.HTML(http.StatusOK, "book_list.html", data)
 }
 
 // BookDetailGet 处理GET /books/:id
@@ -104,26 +342,43 @@ func BookDetailGet(c *gin.Context) {
 	userRole := mg.GetUserRoleFromSession(c)
 
 	// 渲染图书详情页面
-	c.HTML(http.StatusOK, "book_detail.html", gin.H{
-		"title":           book.Title,
-		"book":            book,
-		"available":       available,
-		"availableCount":  availableCount,
-		"user_id":         userID,
-		"user_role":       userRole,
-	})
+	data := gin.H{
+		"title":             book.Title,
+		"book":              book,
+		"available":         available,
+		"availableCount":    availableCount,
+		"user_id":           userID,
+		"user_role":         userRole,
+		"contributors":      models.GetBookCredits(id),
+		"book_tags":         models.GetBookTags(id),
+		"copy_locations":    copyLocations(book),
+		"book_series":       models.GetBookSeries(id),
+		"recommended_books": models.RelatedBooks(id, 4),
+	}
+	bookReviewData(c, mg, data, id, userID)
+	c.HTML(http.StatusOK, "book_detail.html", data)
 }
 
 // AdminBooksGet 处理GET /admin/books
 func AdminBooksGet(c *gin.Context) {
-	// 获取所有图书
-	books := models.GetAllBooks()
+	// 解析筛选、排序和分页参数
+	query := parseBookQuery(c, models.DefaultPageSize)
+
+	// 分页查询图书
+	page, err := models.FindBooks(query)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// 渲染管理员图书管理页面
-	c.HTML(http.StatusOK, "admin/books.html", gin.H{
-		"title": "图书管理",
-		"books": books,
-	})
+	data := bookListData(c, query, page)
+	data["title"] = "图书管理"
+	data["categories"] = models.GetAllCategories()
+	data["export_url"] = exportURL(c)
+	c.HTML(http.StatusOK, "admin/books.html", data)
 }
 
 // AdminAddBookGet 处理GET /admin/add-book
@@ -136,12 +391,15 @@ func AdminAddBookGet(c *gin.Context) {
 	errorMsg := mg.GetFlashMessage(c, "error")
 
 	// 渲染添加图书页面
-	c.HTML(http.StatusOK, "admin/edit_book.html", gin.H{
+	data := gin.H{
 		"title":      "添加图书",
 		"csrf_token": token,
 		"error":      errorMsg,
 		"is_add":     true,
-	})
+	}
+	bookSeriesFormData(data, nil)
+	classificationFormData(data, nil, "")
+	c.HTML(http.StatusOK, "admin/edit_book.html", data)
 }
 
 // AdminAddBookPost 处理POST /admin/add-book
@@ -162,6 +420,17 @@ func AdminAddBookPost(c *gin.Context) {
 		return
 	}
 
+	// 先处理封面图片并校验索书号等附属信息，无效时不创建图书
+	images, err := readCoverUpload(c, "cover_file")
+	if err != nil {
+		renderBookForm(c, mg, form, 0, err)
+		return
+	}
+	if err := form.validate(0); err != nil {
+		renderBookForm(c, mg, form, 0, err)
+		return
+	}
+
 	// 创建新图书
 	book, err := models.CreateBook(
 		form.Title,
@@ -171,14 +440,27 @@ func AdminAddBookPost(c *gin.Context) {
 		form.Category,
 		form.Description,
 		form.CoverURL,
-		form.Quantity,
+		*form.Quantity,
 	)
 	if err != nil {
-		// 图书创建失败
-		mg.SetFlashMessage(c, "error", err.Error())
-		c.Redirect(http.StatusFound, "/admin/add-book")
+		// 图书创建失败，保留已填写的内容
+		renderBookForm(c, mg, form, 0, err)
 		return
 	}
+	if err := saveBookLinks(book.ID, form); err != nil {
+		// 附属信息保存失败时撤销添加，保留已填写的内容
+		rollbackNewBook(book.ID)
+		renderBookForm(c, mg, form, 0, err)
+		return
+	}
+	if images != nil {
+		if _, err := models.SetBookCover(book.ID, images); err != nil {
+			log.Printf("保存图书封面失败: %v", err)
+			mg.SetFlashMessage(c, "warning", "图书已添加，但封面保存失败："+book.Title)
+			c.Redirect(http.StatusFound, "/admin/books")
+			return
+		}
+	}
 
 	// 设置成功消息
 	mg.SetFlashMessage(c, "success", "图书添加成功："+book.Title)
@@ -216,13 +498,22 @@ func AdminEditBookGet(c *gin.Context) {
 	errorMsg := mg.GetFlashMessage(c, "error")
 
 	// 渲染编辑图书页面
-	c.HTML(http.StatusOK, "admin/edit_book.html", gin.H{
-		"title":      "编辑图书",
-		"book":       book,
-		"csrf_token": token,
-		"error":      errorMsg,
-		"is_add":     false,
-	})
+	credits := models.GetBookCredits(id)
+	tags := models.GetBookTags(id)
+	data := gin.H{
+		"title":       "编辑图书",
+		"book":        book,
+		"csrf_token":  token,
+		"error":       errorMsg,
+		"is_add":      false,
+		"translators": models.BookCreditNames(credits, models.CreditTranslator),
+		"editors":     models.BookCreditNames(credits, models.CreditEditor),
+		"subjects":    models.BookTagNames(tags, models.TagSubject),
+		"tags":        models.BookTagNames(tags, models.TagTopic),
+	}
+	bookSeriesFormData(data, book)
+	classificationFormData(data, book, "")
+	c.HTML(http.StatusOK, "admin/edit_book.html", data)
 }
 
 // AdminEditBookPost 处理POST /admin/edit-book/:id
@@ -253,6 +544,16 @@ func AdminEditBookPost(c *gin.Context) {
 		return
 	}
 
+	images, err := readCoverUpload(c, "cover_file")
+	if err != nil {
+		renderBookForm(c, mg, form, id, err)
+		return
+	}
+	if err := form.validate(id); err != nil {
+		renderBookForm(c, mg, form, id, err)
+		return
+	}
+
 	// 更新图书
 	book, err := models.UpdateBook(
 		id,
@@ -263,12 +564,28 @@ func AdminEditBookPost(c *gin.Context) {
 		form.Category,
 		form.Description,
 		form.CoverURL,
-		form.Quantity,
+		*form.Quantity,
 	)
 	if err != nil {
-		// 图书更新失败
-		mg.SetFlashMessage(c, "error", err.Error())
-		c.Redirect(http.StatusFound, "/admin/edit-book/"+idStr)
+		// 图书更新失败，保留已填写的内容
+		renderBookForm(c, mg, form, id, err)
+		return
+	}
+	if err := saveBookLinks(id, form); err != nil {
+		renderBookForm(c, mg, form, id, fmt.Errorf("图书信息已更新，但%w", err))
+		return
+	}
+
+	// 上传新封面时替换原封面，否则按需删除
+	if images != nil {
+		_, err = models.SetBookCover(id, images)
+	} else if form.RemoveCover {
+		err = models.RemoveBookCover(id)
+	}
+	if err != nil {
+		log.Printf("更新图书封面失败: %v", err)
+		mg.SetFlashMessage(c, "warning", "图书已更新，但封面保存失败："+book.Title)
+		c.Redirect(http.StatusFound, "/admin/books")
 		return
 	}
 
@@ -306,14 +623,23 @@ func AdminDeleteBookGet(c *gin.Context) {
 	c.Redirect(http.StatusFound, "/admin/books")
 }
 
-// LibrarianBooksGet 处理GET /librarian/books
+// LibrarianBooksGet 处理GET /librarian/books，支持按索书号排序以便按排架顺序核对
 func LibrarianBooksGet(c *gin.Context) {
-	// 获取所有图书
-	books := models.GetAllBooks()
+	// 解析筛选、排序和分页参数
+	query := parseBookQuery(c, models.DefaultPageSize)
+
+	// 分页查询图书
+	page, err := models.FindBooks(query)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// 获取每本书的借阅情况
 	bookStatus := make(map[int]map[string]interface{})
-	for _, book := range books {
+	for _, book := range page.Books {
 		activeRecords := models.GetActiveBorrowRecordsByBookID(book.ID)
 		bookStatus[book.ID] = map[string]interface{}{
 			"total":           book.Quantity,
@@ -324,9 +650,9 @@ func LibrarianBooksGet(c *gin.Context) {
 	}
 
 	// 渲染图书管理员图书管理页面
-	c.HTML(http.StatusOK, "librarian/books.html", gin.H{
-		"title":       "图书库存管理",
-		"books":       books,
-		"book_status": bookStatus,
-	})
+	data := bookListData(c, query, page)
+	data["title"] = "图书库存管理"
+	data["categories"] = models.GetAllCategories()
+	data["book_status"] = bookStatus
+	c.HTML(http.StatusOK, "librarian/books.html", data)
 }
\ No newline at end of file
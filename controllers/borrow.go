@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,13 +11,22 @@ import (
 	"librarysystem/utils"
 )
 
-// BorrowForm 借阅表单结构
+// BorrowForm 借阅表单结构，填写条码时按条码借出指定副本
 type BorrowForm struct {
 	UserID    int    `form:"user_id" binding:"required"`
-	BookID    int    `form:"book_id" binding:"required"`
+	BookID    int    `form:"book_id"`
+	Barcode   string `form:"barcode"`
 	CSRFToken string `form:"csrf_token"`
 }
 
+// BorrowView 借阅记录展示信息
+type BorrowView struct {
+	Record *models.BorrowRecord
+	User   *models.User
+	Book   *models.Book
+	Copy   *models.BookCopy
+}
+
 // AdminUsersGet 处理GET /admin/users
 func AdminUsersGet(c *gin.Context) {
 	// 获取所有用户
@@ -103,16 +113,34 @@ func LibrarianBorrowGet(c *gin.Context) {
 		}
 	}
 
+	// 补充借阅记录的用户、图书和副本信息，最新的排在前面
+	borrows := make([]BorrowView, 0, len(allRecords))
+	for i := len(allRecords) - 1; i >= 0; i-- {
+		record := allRecords[i]
+		view := BorrowView{
+			Record: record,
+			User:   userMap[record.UserID],
+			Book:   bookMap[record.BookID],
+		}
+		if record.CopyID != 0 {
+			view.Copy, _ = models.GetCopyByID(record.CopyID)
+		}
+		borrows = append(borrows, view)
+	}
+
 	// 渲染借阅管理页面
 	c.HTML(http.StatusOK, "librarian/borrow.html", gin.H{
 		"title":           "借阅管理",
 		"all_records":     allRecords,
+		"borrows":         borrows,
 		"users":           users,
 		"books":           books,
 		"user_map":        userMap,
 		"book_map":        bookMap,
 		"csrf_token":      token,
 		"available_books": availableBooks,
+		"success":         mg.GetFlashMessage(c, "success"),
+		"error":           mg.GetFlashMessage(c, "error"),
 	})
 }
 
@@ -122,7 +150,7 @@ func LibrarianCreateBorrowPost(c *gin.Context) {
 	var form BorrowForm
 	if err := c.ShouldBind(&form); err != nil {
 		// 表单验证失败
-		mg.SetFlashMessage(c, "error", "请选择用户和图书")
+		mg.SetFlashMessage(c, "error", "请选择读者")
 		c.Redirect(http.StatusFound, "/librarian/borrow")
 		return
 	}
@@ -134,12 +162,19 @@ func LibrarianCreateBorrowPost(c *gin.Context) {
 		return
 	}
 
-	// 设置借阅日期和到期日期
-	now := time.Now()
-	dueDate := now.AddDate(0, 0, 14) // 14天后到期
-
-	// 创建借阅记录
-	_, err := models.CreateBorrowRecord(form.UserID, form.BookID, now, dueDate)
+	// 创建借阅记录，到期日由流通规则决定
+	var record *models.BorrowRecord
+	var err error
+	switch {
+	case form.Barcode != "":
+		record, err = models.CreateBorrowRecordByBarcode(form.UserID, form.Barcode, time.Now())
+	case form.BookID != 0:
+		record, err = models.CreateBorrowRecord(form.UserID, form.BookID, time.Now())
+	default:
+		mg.SetFlashMessage(c, "error", "请选择图书或填写副本条码")
+		c.Redirect(http.StatusFound, "/librarian/borrow")
+		return
+	}
 	if err != nil {
 		// 创建失败
 		mg.SetFlashMessage(c, "error", err.Error())
@@ -148,7 +183,7 @@ func LibrarianCreateBorrowPost(c *gin.Context) {
 	}
 
 	// 设置成功消息
-	mg.SetFlashMessage(c, "success", "借阅记录已创建")
+	mg.SetFlashMessage(c, "success", "借阅记录已创建，到期日为"+record.DueDate.Format("2006-01-02"))
 	
 	// 重定向回借阅管理页面
 	c.Redirect(http.StatusFound, "/librarian/borrow")
@@ -172,32 +207,58 @@ func LibrarianReturnBookGet(c *gin.Context) {
 	if err != nil {
 		mg.SetFlashMessage(c, "error", err.Error())
 	} else {
-		mg.SetFlashMessage(c, "success", "图书已成功归还")
+		message := "图书已成功归还"
+		for _, fine := range models.GetFineEntriesByRecordID(id) {
+			if fine.Type == models.FineCharge {
+				message += "，逾期罚款" + models.FormatAmount(fine.Amount) + "元"
+			}
+		}
+		mg.SetFlashMessage(c, "success", message)
 	}
 
 	// 重定向回借阅管理页面
 	c.Redirect(http.StatusFound, "/librarian/borrow")
 }
 
-// ReaderBooksGet 处理GET /reader/books
-func ReaderBooksGet(c *gin.Context) {
+// LibrarianRenewGet 处理GET /librarian/renew/:id
+func LibrarianRenewGet(c *gin.Context) {
 	mg := utils.NewSessionManager(c)
-	// 获取查询参数
-	query := c.Query("q")
-	category := c.Query("category")
-
-	// 根据查询参数获取图书
-	var books []*models.Book
-	if query != "" {
-		books = models.SearchBooks(query)
-	} else if category != "" {
-		books = models.GetBooksByCategory(category)
+	// 获取借阅记录ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的借阅记录ID",
+		})
+		return
+	}
+
+	// 续借图书
+	record, err := models.RenewBorrowRecord(id, mg.GetUserIDFromSession(c))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
 	} else {
-		books = models.GetAllBooks()
+		mg.SetFlashMessage(c, "success", "续借成功，新的到期日为"+record.DueDate.Format("2006-01-02"))
 	}
 
-	// 获取所有分类
-	categories := models.GetAllCategories()
+	// 重定向回借阅管理页面
+	c.Redirect(http.StatusFound, "/librarian/borrow")
+}
+
+// ReaderBooksGet 处理GET /reader/books
+func ReaderBooksGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 解析筛选、排序和分页参数
+	query := parseBookQuery(c, 12)
+
+	// 分页查询图书
+	page, err := models.FindBooks(query)
+	if err != nil {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
 	// 获取用户ID
 	userID := mg.GetUserIDFromSession(c)
@@ -210,14 +271,20 @@ func ReaderBooksGet(c *gin.Context) {
 	}
 
 	// 渲染读者图书列表页面
-	c.HTML(http.StatusOK, "reader/books.html", gin.H{
-		"title":           "查找图书",
-		"books":           books,
-		"categories":      categories,
-		"query":           query,
-		"category":        category,
-		"borrowed_books":  borrowedBookIDs,
-	})
+	// 统计各维度的分布
+	facets, err := models.FindBookFacets(query)
+	if err != nil {
+		log.Printf("统计图书分布失败: %v", err)
+		facets = &models.BookFacets{}
+	}
+
+	data := bookListData(c, query, page)
+	data["title"] = "查找图书"
+	data["facets"] = facetGroups(c, facets)
+	data["categories"] = models.GetAllCategories()
+	data["current_category"] = query.Category
+	data["borrowed_books"] = borrowedBookIDs
+	c.HTML(http.StatusOK, "reader/books.html", data)
 }
 
 // ReaderBorrowGet 处理GET /reader/borrow/:id
@@ -236,12 +303,8 @@ func ReaderBorrowGet(c *gin.Context) {
 	// 获取用户ID
 	userID := mg.GetUserIDFromSession(c)
 
-	// 设置借阅日期和到期日期
-	now := time.Now()
-	dueDate := now.AddDate(0, 0, 14) // 14天后到期
-
-	// 创建借阅记录
-	_, err = models.CreateBorrowRecord(userID, id, now, dueDate)
+	// 创建借阅记录，到期日由流通规则决定
+	record, err := models.CreateBorrowRecord(userID, id, time.Now())
 	if err != nil {
 		mg.SetFlashMessage(c, "error", err.Error())
 		c.Redirect(http.StatusFound, "/books/"+idStr)
@@ -249,7 +312,7 @@ func ReaderBorrowGet(c *gin.Context) {
 	}
 
 	// 设置成功消息
-	mg.SetFlashMessage(c, "success", "图书借阅成功，请在两周内归还")
+	mg.SetFlashMessage(c, "success", "图书借阅成功，请在"+record.DueDate.Format("2006-01-02")+"前归还")
 	
 	// 重定向到借阅记录页面
 	c.Redirect(http.StatusFound, "/reader/borrowed")
@@ -280,6 +343,7 @@ func ReaderBorrowedGet(c *gin.Context) {
 		DaysUntilDue   int
 		OverdueDays    int
 		StatusText     string
+		CanRenew       bool
 	}
 
 	var enhancedRecords []EnhancedRecord
@@ -309,6 +373,7 @@ func ReaderBorrowedGet(c *gin.Context) {
 			DaysUntilDue:   record.DaysUntilDue(),
 			OverdueDays:    record.OverdueDays(),
 			StatusText:     statusText,
+			CanRenew:       record.CanRenew() == nil,
 		}
 
 		enhancedRecords = append(enhancedRecords, enhancedRecord)
@@ -329,6 +394,7 @@ func ReaderBorrowedGet(c *gin.Context) {
 		"active_count":     activeCount,
 		"overdue_count":    overdueCount,
 		"book_map":         bookMap,
+		"series_next":      models.SeriesSuggestionsForUser(userID),
 	})
 }
 
@@ -368,4 +434,47 @@ func ReaderReturnBookGet(c *gin.Context) {
 	
 	// 重定向回借阅记录页面
 	c.Redirect(http.StatusFound, "/reader/borrowed")
-}
\ No newline at end of file
+}
+
+// ReaderRenewGet 处理GET /reader/renew/:id
+func ReaderRenewGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取借阅记录ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的借阅记录ID",
+		})
+		return
+	}
+
+	// 获取借阅记录
+	record, err := models.GetBorrowRecordByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "借阅记录不存在",
+		})
+		return
+	}
+
+	// 检查记录是否属于当前用户
+	userID := mg.GetUserIDFromSession(c)
+	if record.UserID != userID {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"error": "无权操作此借阅记录",
+		})
+		return
+	}
+
+	// 续借图书
+	record, err = models.RenewBorrowRecord(id, userID)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "续借成功，新的到期日为"+record.DueDate.Format("2006-01-02"))
+	}
+
+	// 重定向回借阅记录页面
+	c.Redirect(http.StatusFound, "/reader/borrowed")
+}
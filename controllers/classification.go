@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// ClassificationForm 分类节点表单
+type ClassificationForm struct {
+	Code      string `form:"code" binding:"required"`
+	Name      string `form:"name" binding:"required"`
+	ParentID  int    `form:"parent_id"`
+	CSRFToken string `form:"csrf_token"`
+}
+
+// ClassBrowseItem 图书列表分类导航中的一个分类链接
+type ClassBrowseItem struct {
+	*models.Classification
+	Count int
+	URL   template.URL
+}
+
+// classSubtreeIDs 按分类号取该分类及其下级的ID，分类号不存在时返回空列表以得到空结果
+func classSubtreeIDs(code string) []int {
+	tree, err := models.GetClassificationTree()
+	if err != nil {
+		log.Printf("获取分类表失败: %v", err)
+		return []int{}
+	}
+	node := tree.GetByCode(code)
+	if node == nil {
+		return []int{}
+	}
+	return tree.SubtreeIDs(node.ID)
+}
+
+// classBrowseData 图书列表的分类导航：当前分类路径和带图书数量的下级分类
+func classBrowseData(c *gin.Context, query models.BookQuery, data gin.H) {
+	tree, err := models.GetClassificationTree()
+	if err != nil {
+		log.Printf("获取分类表失败: %v", err)
+		return
+	}
+	if tree.Empty() {
+		return
+	}
+
+	children := tree.Roots
+	if node := tree.GetByCode(c.Query("class")); node != nil {
+		var path []ClassBrowseItem
+		for _, item := range tree.Path(node.ID) {
+			path = append(path, ClassBrowseItem{Classification: item, URL: facetURL(c, "class", item.Code)})
+		}
+		data["class_path"] = path
+		children = node.Children
+	}
+
+	counts, err := models.CountBooksByClassification(query, tree)
+	if err != nil {
+		log.Printf("统计分类图书数量失败: %v", err)
+	}
+	var items []ClassBrowseItem
+	for _, child := range children {
+		if counts[child.ID] == 0 {
+			continue
+		}
+		items = append(items, ClassBrowseItem{
+			Classification: child,
+			Count:          counts[child.ID],
+			URL:            facetURL(c, "class", child.Code),
+		})
+	}
+	data["class_children"] = items
+	data["class_all_url"] = facetURL(c, "class", "")
+}
+
+// classificationFormData 图书表单的分类下拉框，selected 为选中的分类号；分类表为空时表单使用文本输入
+func classificationFormData(data gin.H, book *models.Book, selected string) {
+	tree, err := models.GetClassificationTree()
+	if err != nil {
+		log.Printf("获取分类表失败: %v", err)
+		return
+	}
+	if tree.Empty() {
+		return
+	}
+	if selected == "" && book != nil {
+		if node := tree.Get(book.ClassID); node != nil {
+			selected = node.Code
+		}
+	}
+	data["classifications"] = tree.Flatten()
+	data["selected_class"] = selected
+}
+
+// AdminClassificationsGet 处理GET /admin/classifications
+func AdminClassificationsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	tree, err := models.GetClassificationTree()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取分类表失败",
+		})
+		return
+	}
+	counts, err := models.CountBooksByClassification(models.BookQuery{}, tree)
+	if err != nil {
+		log.Printf("统计分类图书数量失败: %v", err)
+	}
+
+	c.HTML(http.StatusOK, "admin/classifications.html", gin.H{
+		"title":           "分类表",
+		"classifications": tree.Flatten(),
+		"counts":          counts,
+		"csrf_token":      mg.GenerateCSRFToken(c),
+		"success":         mg.GetFlashMessage(c, "success"),
+		"error":           mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// AdminAddClassificationPost 处理POST /admin/classifications
+func AdminAddClassificationPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	var form ClassificationForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请填写分类号和类名")
+		c.Redirect(http.StatusFound, "/admin/classifications")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/classifications")
+		return
+	}
+
+	node, err := models.CreateClassification(form.Code, form.Name, form.ParentID)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "分类已添加："+node.Label())
+	}
+	c.Redirect(http.StatusFound, "/admin/classifications")
+}
+
+// AdminEditClassificationPost 处理POST /admin/classifications/:id
+func AdminEditClassificationPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的分类ID",
+		})
+		return
+	}
+
+	var form ClassificationForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请填写分类号和类名")
+		c.Redirect(http.StatusFound, "/admin/classifications")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/classifications")
+		return
+	}
+
+	node, err := models.UpdateClassification(id, form.Code, form.Name, form.ParentID)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "分类已更新："+node.Label())
+	}
+	c.Redirect(http.StatusFound, "/admin/classifications")
+}
+
+// AdminDeleteClassificationPost 处理POST /admin/classifications/:id/delete
+func AdminDeleteClassificationPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的分类ID",
+		})
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, c.PostForm("csrf_token")) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/classifications")
+		return
+	}
+
+	if err := models.DeleteClassification(id); err != nil {
+		mg.SetFlashMessage(c, "error", "无法删除："+err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "分类已删除")
+	}
+	c.Redirect(http.StatusFound, "/admin/classifications")
+}
+
+// APIv1ClassificationsGet 处理GET /api/v1/classifications，返回分类树
+func APIv1ClassificationsGet(c *gin.Context) {
+	tree, err := models.GetClassificationTree()
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询分类表失败")
+		return
+	}
+	roots := tree.Roots
+	if roots == nil {
+		roots = []*models.Classification{}
+	}
+	apiData(c, http.StatusOK, roots)
+}
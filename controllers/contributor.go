@@ -0,0 +1,261 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// ContributorForm 编辑作者表单
+type ContributorForm struct {
+	Name      string `form:"name" json:"name" binding:"required,max=100"`
+	Bio       string `form:"bio" json:"bio"`
+	CSRFToken string `form:"csrf_token" json:"-"`
+}
+
+// CreditRequest API中图书的一位责任者
+type CreditRequest struct {
+	Name string `json:"name" binding:"required"`
+	Role string `json:"role" binding:"required,oneof=author translator editor"`
+}
+
+// WorkGroup 作者页面上同一责任方式的作品
+type WorkGroup struct {
+	Role  string
+	Works []*models.ContributorWork
+}
+
+// groupWorks 按责任方式分组作品，作品已按责任方式排序
+func groupWorks(works []*models.ContributorWork) []WorkGroup {
+	var groups []WorkGroup
+	for _, work := range works {
+		if len(groups) == 0 || groups[len(groups)-1].Role != work.Role.Text() {
+			groups = append(groups, WorkGroup{Role: work.Role.Text()})
+		}
+		groups[len(groups)-1].Works = append(groups[len(groups)-1].Works, work)
+	}
+	return groups
+}
+
+// bookCreditsInput 表单中的译者、编者，作者由 Book.Author 同步
+func bookCreditsInput(form BookForm) map[models.ContributorRole][]string {
+	return map[models.ContributorRole][]string{
+		models.CreditTranslator: models.ParseContributorNames(form.Translators),
+		models.CreditEditor:     models.ParseContributorNames(form.Editors),
+	}
+}
+
+// AuthorsGet 处理GET /authors
+func AuthorsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	page, _ := strconv.Atoi(c.Query("page"))
+	result, err := models.FindContributors(c.Query("q"), page, 30)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取作者列表失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "author_list.html", gin.H{
+		"title":        "作者",
+		"contributors": result.Contributors,
+		"total":        result.Total,
+		"query":        c.Query("q"),
+		"page_url":     pageURL(c),
+		"current_page": result.Page,
+		"total_pages":  result.TotalPages,
+		"user_role":    mg.GetUserRoleFromSession(c),
+	})
+}
+
+// AuthorDetailGet 处理GET /authors/:id
+func AuthorDetailGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的作者ID",
+		})
+		return
+	}
+
+	contributor, err := models.GetContributorByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "作者不存在",
+		})
+		return
+	}
+	works, err := models.GetContributorWorks(id)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取作品列表失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "author_detail.html", gin.H{
+		"title":       contributor.Name,
+		"contributor": contributor,
+		"work_groups": groupWorks(works),
+		"work_count":  len(works),
+		"user_role":   mg.GetUserRoleFromSession(c),
+	})
+}
+
+// AdminEditAuthorGet 处理GET /admin/edit-author/:id
+func AdminEditAuthorGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的作者ID",
+		})
+		return
+	}
+
+	contributor, err := models.GetContributorByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "作者不存在",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin/edit_author.html", gin.H{
+		"title":       "编辑作者",
+		"contributor": contributor,
+		"csrf_token":  mg.GenerateCSRFToken(c),
+		"error":       mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// AdminEditAuthorPost 处理POST /admin/edit-author/:id
+func AdminEditAuthorPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的作者ID",
+		})
+		return
+	}
+
+	var form ContributorForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请填写姓名（不超过100个字符）")
+		c.Redirect(http.StatusFound, "/admin/edit-author/"+idStr)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/edit-author/"+idStr)
+		return
+	}
+
+	contributor, err := models.UpdateContributor(id, form.Name, form.Bio)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/admin/edit-author/"+idStr)
+		return
+	}
+
+	mg.SetFlashMessage(c, "success", "作者信息已更新："+contributor.Name)
+	c.Redirect(http.StatusFound, "/authors/"+idStr)
+}
+
+// APIv1AuthorsGet 处理GET /api/v1/authors，支持 q、page、size 参数
+func APIv1AuthorsGet(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	size, _ := strconv.Atoi(c.Query("size"))
+	result, err := models.FindContributors(c.Query("q"), page, size)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询作者失败")
+		return
+	}
+	apiData(c, http.StatusOK, result)
+}
+
+// APIv1AuthorGet 处理GET /api/v1/authors/:id，返回作者及其作品
+func APIv1AuthorGet(c *gin.Context) {
+	id, ok := apiParamID(c, "作者")
+	if !ok {
+		return
+	}
+
+	contributor, err := models.GetContributorByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	works, err := models.GetContributorWorks(id)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询作品失败")
+		return
+	}
+
+	author := *contributor
+	author.Works = len(works)
+	apiData(c, http.StatusOK, gin.H{
+		"author": author,
+		"works":  works,
+	})
+}
+
+// APIv1AuthorPut 处理PUT /api/v1/authors/:id
+func APIv1AuthorPut(c *gin.Context) {
+	id, ok := apiParamID(c, "作者")
+	if !ok {
+		return
+	}
+
+	var req ContributorForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请填写姓名（不超过100个字符）")
+		return
+	}
+
+	contributor, err := models.UpdateContributor(id, req.Name, req.Bio)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, contributor)
+}
+
+// apiSetBookCredits 按请求中的责任者列表替换图书的译者和编者，作者已通过 author 字段同步；
+// 请求未携带 contributors 时保持不变
+func apiSetBookCredits(bookID int, credits []CreditRequest) error {
+	input := requestCreditsInput(credits)
+	if input == nil {
+		return nil
+	}
+	return models.SetBookCredits(bookID, input)
+}
+
+// requestCreditsInput 请求中的译者和编者，未携带 contributors 时返回 nil
+func requestCreditsInput(credits []CreditRequest) map[models.ContributorRole][]string {
+	if len(credits) == 0 {
+		return nil
+	}
+
+	input := map[models.ContributorRole][]string{
+		models.CreditTranslator: {},
+		models.CreditEditor:     {},
+	}
+	for _, credit := range credits {
+		role := models.ContributorRole(credit.Role)
+		if role != models.CreditAuthor {
+			input[role] = append(input[role], credit.Name)
+		}
+	}
+	return input
+}
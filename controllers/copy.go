@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// CopyForm 图书副本表单结构
+type CopyForm struct {
+	Barcode       string `form:"barcode"`
+	ShelfLocation string `form:"shelf_location"`
+	Condition     string `form:"condition"`
+	Status        string `form:"status"`
+	CSRFToken     string `form:"csrf_token"`
+}
+
+// LibrarianCopiesGet 处理GET /librarian/copies/:id
+func LibrarianCopiesGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取图书ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的图书ID",
+		})
+		return
+	}
+
+	// 获取图书
+	book, err := models.GetBookByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "图书不存在",
+		})
+		return
+	}
+
+	// 查找借出副本的当前借阅人
+	userMap := make(map[int]*models.User)
+	for _, user := range models.GetAllUsers() {
+		userMap[user.ID] = user
+	}
+	borrowers := make(map[int]*models.User)
+	for _, record := range models.GetActiveBorrowRecordsByBookID(id) {
+		borrowers[record.CopyID] = userMap[record.UserID]
+	}
+
+	// 生成CSRF令牌
+	token := mg.GenerateCSRFToken(c)
+
+	// 渲染副本管理页面
+	c.HTML(http.StatusOK, "librarian/copies.html", gin.H{
+		"title":      "副本管理",
+		"book":       book,
+		"copies":     models.GetCopiesByBookID(id),
+		"borrowers":  borrowers,
+		"csrf_token": token,
+		"success":    mg.GetFlashMessage(c, "success"),
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// LibrarianAddCopyPost 处理POST /librarian/copies/:id
+func LibrarianAddCopyPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取图书ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的图书ID",
+		})
+		return
+	}
+
+	var form CopyForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请正确填写副本信息")
+		c.Redirect(http.StatusFound, "/librarian/copies/"+idStr)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/librarian/copies/"+idStr)
+		return
+	}
+
+	// 添加副本
+	item, err := models.AddBookCopy(id, form.Barcode, form.ShelfLocation, models.CopyCondition(form.Condition))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "副本已添加，条码："+item.Barcode)
+	}
+
+	// 重定向回副本管理页面
+	c.Redirect(http.StatusFound, "/librarian/copies/"+idStr)
+}
+
+// LibrarianUpdateCopyPost 处理POST /librarian/copy/:id
+func LibrarianUpdateCopyPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取副本ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的副本ID",
+		})
+		return
+	}
+
+	// 获取副本
+	item, err := models.GetCopyByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "副本不存在",
+		})
+		return
+	}
+	redirectURL := "/librarian/copies/" + strconv.Itoa(item.BookID)
+
+	var form CopyForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请正确填写副本信息")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	// 更新副本
+	_, err = models.UpdateBookCopy(id, form.ShelfLocation, models.CopyCondition(form.Condition), models.CopyStatus(form.Status))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "副本"+item.Barcode+"已更新")
+	}
+
+	// 重定向回副本管理页面
+	c.Redirect(http.StatusFound, redirectURL)
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+)
+
+// readCoverUpload 读取并处理表单中上传的封面图片，没有上传文件时返回nil
+func readCoverUpload(c *gin.Context, field string) (models.CoverImages, error) {
+	header, err := c.FormFile(field)
+	if errors.Is(err, http.ErrMissingFile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("读取封面图片失败")
+	}
+	if header.Size > models.MaxCoverFileSize {
+		return nil, fmt.Errorf("封面图片不能超过%dMB", models.MaxCoverFileSize>>20)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, errors.New("读取封面图片失败")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxCoverFileSize+1))
+	if err != nil {
+		return nil, errors.New("读取封面图片失败")
+	}
+	return models.ProcessCoverImage(data)
+}
+
+// CoverGet 处理GET /covers/:name，文件名带版本号，可以长期缓存
+func CoverGet(c *gin.Context) {
+	name := c.Param("name")
+	if !models.IsCoverFileName(name) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	file, modTime, err := models.GetCoverStorage().Open(name)
+	if errors.Is(err, models.ErrCoverNotFound) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("读取封面文件失败: %v", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "image/jpeg")
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	http.ServeContent(c.Writer, c.Request, name, modTime, file)
+}
+
+// APIv1BookCoverPut 处理PUT /api/v1/books/:id/cover，以 multipart 字段 file 上传封面
+func APIv1BookCoverPut(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	images, err := readCoverUpload(c, "file")
+	if err != nil {
+		middleware.APIError(c, http.StatusUnprocessableEntity, "invalid_image", err.Error())
+		return
+	}
+	if images == nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请上传封面图片")
+		return
+	}
+
+	book, err := models.SetBookCover(id, images)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, book)
+}
+
+// APIv1BookCoverDelete 处理DELETE /api/v1/books/:id/cover，删除上传的封面
+func APIv1BookCoverDelete(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	if err := models.RemoveBookCover(id); err != nil {
+		apiModelError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+)
+
+// exportURL 生成保留当前筛选条件的导出链接前缀，模板中在其后追加格式
+func exportURL(c *gin.Context) template.URL {
+	values := url.Values{}
+	for key, value := range c.Request.URL.Query() {
+		if key != "page" && key != "cursor" && key != "size" && key != "format" {
+			values[key] = value
+		}
+	}
+
+	prefix := "/admin/export-books?"
+	if encoded := values.Encode(); encoded != "" {
+		prefix += encoded + "&"
+	}
+	return template.URL(prefix + "format=")
+}
+
+// streamBookExport 按查询参数流式导出图书，开始输出前的错误交给 fail 处理
+func streamBookExport(c *gin.Context, fail func(err error)) {
+	format, err := models.ParseExportFormat(c.Query("format"))
+	if err != nil {
+		fail(err)
+		return
+	}
+	query := parseBookQuery(c, models.MaxPageSize)
+	if err := models.ValidateBookQuery(query); err != nil {
+		fail(err)
+		return
+	}
+
+	filename := fmt.Sprintf("books-%s.%s", time.Now().Format("20060102"), format.Extension())
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	// 响应头已发出，之后的错误只能记录日志
+	if _, err := models.ExportBooks(c.Writer, format, query); err != nil {
+		log.Printf("导出图书失败: %v", err)
+	}
+}
+
+// AdminExportBooksGet 处理GET /admin/export-books，筛选参数与图书管理列表相同
+func AdminExportBooksGet(c *gin.Context) {
+	streamBookExport(c, func(err error) {
+		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+			"error": err.Error(),
+		})
+	})
+}
+
+// APIv1BooksExportGet 处理GET /api/v1/books/export
+func APIv1BooksExportGet(c *gin.Context) {
+	streamBookExport(c, func(err error) {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_query", err.Error())
+	})
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// FineForm 缴纳/减免罚款表单结构
+type FineForm struct {
+	Type      string `form:"type" binding:"required"`
+	Amount    string `form:"amount" binding:"required"`
+	Note      string `form:"note"`
+	CSRFToken string `form:"csrf_token"`
+}
+
+// FineAccountView 读者罚款汇总信息
+type FineAccountView struct {
+	User    *models.User
+	Charged int
+	Paid    int
+	Waived  int
+	Balance int
+}
+
+// ReaderFinesGet 处理GET /reader/fines
+func ReaderFinesGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取用户的罚款台账
+	userID := mg.GetUserIDFromSession(c)
+	balance, err := models.GetFineBalance(userID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "查询罚款余额失败",
+		})
+		return
+	}
+
+	// 渲染罚款页面
+	c.HTML(http.StatusOK, "reader/fines.html", gin.H{
+		"title":     "我的罚款",
+		"entries":   models.GetFineEntriesByUserID(userID),
+		"balance":   balance,
+		"threshold": models.GetFineConfig().BlockThreshold,
+	})
+}
+
+// LibrarianFinesGet 处理GET /librarian/fines
+func LibrarianFinesGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取所有用户
+	userMap := make(map[int]*models.User)
+	for _, user := range models.GetAllUsers() {
+		userMap[user.ID] = user
+	}
+
+	// 按读者汇总台账
+	accountMap := make(map[int]*FineAccountView)
+	for _, entry := range models.GetAllFineEntries() {
+		account, ok := accountMap[entry.UserID]
+		if !ok {
+			account = &FineAccountView{User: userMap[entry.UserID]}
+			accountMap[entry.UserID] = account
+		}
+		switch entry.Type {
+		case models.FineCharge:
+			account.Charged += entry.Amount
+			account.Balance += entry.Amount
+		case models.FinePayment:
+			account.Paid += entry.Amount
+			account.Balance -= entry.Amount
+		case models.FineWaiver:
+			account.Waived += entry.Amount
+			account.Balance -= entry.Amount
+		}
+	}
+
+	// 未缴金额多的排在前面
+	var accounts []*FineAccountView
+	for _, account := range accountMap {
+		if account.User != nil {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		if accounts[i].Balance != accounts[j].Balance {
+			return accounts[i].Balance > accounts[j].Balance
+		}
+		return accounts[i].User.ID < accounts[j].User.ID
+	})
+
+	// 查看指定读者的台账明细
+	var selected *FineAccountView
+	var entries []*models.FineEntry
+	if userID, err := strconv.Atoi(c.Query("user_id")); err == nil {
+		selected = accountMap[userID]
+		entries = models.GetFineEntriesByUserID(userID)
+	}
+
+	// 生成CSRF令牌
+	token := mg.GenerateCSRFToken(c)
+
+	// 渲染罚款管理页面
+	c.HTML(http.StatusOK, "librarian/fines.html", gin.H{
+		"title":      "罚款管理",
+		"accounts":   accounts,
+		"selected":   selected,
+		"entries":    entries,
+		"user_map":   userMap,
+		"threshold":  models.GetFineConfig().BlockThreshold,
+		"csrf_token": token,
+		"success":    mg.GetFlashMessage(c, "success"),
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// LibrarianFinesPost 处理POST /librarian/fines/:id
+func LibrarianFinesPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取读者ID
+	idStr := c.Param("id")
+	userID, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的用户ID",
+		})
+		return
+	}
+	redirectURL := "/librarian/fines?user_id=" + idStr
+
+	var form FineForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请填写金额")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	amount, err := models.ParseAmount(form.Amount)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	// 记录缴纳或减免
+	operatorID := mg.GetUserIDFromSession(c)
+	switch form.Type {
+	case string(models.FinePayment):
+		_, err = models.PayFine(userID, amount, operatorID, form.Note)
+	case string(models.FineWaiver):
+		_, err = models.WaiveFine(userID, amount, operatorID, form.Note)
+	default:
+		mg.SetFlashMessage(c, "error", "无效的操作类型")
+		c.Redirect(http.StatusFound, redirectURL)
+		return
+	}
+
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "已记录"+models.FormatAmount(amount)+"元")
+	}
+
+	// 重定向回读者台账
+	c.Redirect(http.StatusFound, redirectURL)
+}
@@ -0,0 +1,213 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// HoldView 预约展示信息
+type HoldView struct {
+	Hold          *models.Hold
+	Book          *models.Book
+	User          *models.User
+	QueuePosition int
+}
+
+// buildHoldViews 为预约补充图书、用户和排队位置信息
+func buildHoldViews(holds []*models.Hold) []HoldView {
+	bookMap := make(map[int]*models.Book)
+	for _, book := range models.GetAllBooks() {
+		bookMap[book.ID] = book
+	}
+	userMap := make(map[int]*models.User)
+	for _, user := range models.GetAllUsers() {
+		userMap[user.ID] = user
+	}
+
+	views := make([]HoldView, 0, len(holds))
+	for _, hold := range holds {
+		views = append(views, HoldView{
+			Hold:          hold,
+			Book:          bookMap[hold.BookID],
+			User:          userMap[hold.UserID],
+			QueuePosition: models.HoldQueuePosition(hold),
+		})
+	}
+	return views
+}
+
+// ReaderHoldGet 处理GET /reader/hold/:id
+func ReaderHoldGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取图书ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的图书ID",
+		})
+		return
+	}
+
+	// 获取用户ID
+	userID := mg.GetUserIDFromSession(c)
+
+	// 创建预约
+	hold, err := models.PlaceHold(userID, id)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/books/"+idStr)
+		return
+	}
+
+	// 设置成功消息
+	mg.SetFlashMessage(c, "success", "预约成功，当前排队第"+strconv.Itoa(models.HoldQueuePosition(hold))+"位")
+
+	// 重定向到预约列表
+	c.Redirect(http.StatusFound, "/reader/holds")
+}
+
+// ReaderHoldsGet 处理GET /reader/holds
+func ReaderHoldsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取用户的所有预约
+	userID := mg.GetUserIDFromSession(c)
+	holds := buildHoldViews(models.GetHoldsByUserID(userID))
+
+	// 统计待取数量
+	readyCount := 0
+	for _, view := range holds {
+		if view.Hold.Status == models.HoldReady {
+			readyCount++
+		}
+	}
+
+	// 渲染预约页面
+	c.HTML(http.StatusOK, "reader/holds.html", gin.H{
+		"title":       "我的预约",
+		"holds":       holds,
+		"ready_count": readyCount,
+		"success":     mg.GetFlashMessage(c, "success"),
+		"error":       mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// ReaderCancelHoldGet 处理GET /reader/cancel-hold/:id
+func ReaderCancelHoldGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取预约ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的预约ID",
+		})
+		return
+	}
+
+	// 检查预约是否属于当前用户
+	hold, err := models.GetHoldByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "预约不存在",
+		})
+		return
+	}
+	if hold.UserID != mg.GetUserIDFromSession(c) {
+		c.HTML(http.StatusForbidden, "error.html", gin.H{
+			"error": "无权操作此预约",
+		})
+		return
+	}
+
+	// 取消预约
+	if _, err := models.CancelHold(id); err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "预约已取消")
+	}
+
+	// 重定向回预约列表
+	c.Redirect(http.StatusFound, "/reader/holds")
+}
+
+// LibrarianHoldsGet 处理GET /librarian/holds
+func LibrarianHoldsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 按状态筛选，默认只显示进行中的预约
+	status := c.DefaultQuery("status", "active")
+
+	var holds []*models.Hold
+	for _, hold := range models.GetAllHolds() {
+		switch status {
+		case "all":
+		case "active":
+			if !hold.IsActive() {
+				continue
+			}
+		default:
+			if string(hold.Status) != status {
+				continue
+			}
+		}
+		holds = append(holds, hold)
+	}
+
+	// 生成CSRF令牌（用于为待取预约办理借阅）
+	token := mg.GenerateCSRFToken(c)
+
+	// 渲染预约管理页面
+	c.HTML(http.StatusOK, "librarian/holds.html", gin.H{
+		"title":      "预约管理",
+		"holds":      buildHoldViews(holds),
+		"status":     status,
+		"csrf_token": token,
+		"now":        time.Now(),
+		"success":    mg.GetFlashMessage(c, "success"),
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// LibrarianCancelHoldGet 处理GET /librarian/cancel-hold/:id
+func LibrarianCancelHoldGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取预约ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的预约ID",
+		})
+		return
+	}
+
+	// 取消预约
+	if _, err := models.CancelHold(id); err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "预约已取消")
+	}
+
+	// 重定向回预约管理页面
+	c.Redirect(http.StatusFound, "/librarian/holds")
+}
+
+// LibrarianExpireHoldsGet 处理GET /librarian/expire-holds
+func LibrarianExpireHoldsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 处理超过取书期限的预约
+	count, err := models.ExpireHolds(time.Now())
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "已处理过期预约 "+strconv.Itoa(count)+" 条")
+	}
+
+	// 重定向回预约管理页面
+	c.Redirect(http.StatusFound, "/librarian/holds")
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// maxImportFileSize 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+// ImportForm 批量导入上传表单结构
+type ImportForm struct {
+	Mode               string `form:"mode"`
+	DefaultCategory    string `form:"default_category"`
+	DefaultDescription string `form:"default_description"`
+	DefaultCoverURL    string `form:"default_cover_url"`
+	DefaultQuantity    int    `form:"default_quantity"`
+	CSRFToken          string `form:"csrf_token"`
+}
+
+// ImportConfirmForm 确认导入表单结构
+type ImportConfirmForm struct {
+	Token     string `form:"token" binding:"required"`
+	CSRFToken string `form:"csrf_token"`
+}
+
+// AdminImportBooksGet 处理GET /admin/import-books
+func AdminImportBooksGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 生成CSRF令牌
+	token := mg.GenerateCSRFToken(c)
+
+	// 渲染上传页面
+	c.HTML(http.StatusOK, "admin/import_books.html", gin.H{
+		"title":      "批量导入",
+		"csrf_token": token,
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// AdminImportBooksPost 处理POST /admin/import-books，解析并校验文件后显示预览
+func AdminImportBooksPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	var form ImportForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请正确填写导入选项")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 读取上传的文件
+	header, err := c.FormFile("file")
+	if err != nil {
+		mg.SetFlashMessage(c, "error", "请选择要导入的文件")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+	if header.Size > maxImportFileSize {
+		mg.SetFlashMessage(c, "error", "文件不能超过10MB")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		mg.SetFlashMessage(c, "error", "读取文件失败")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", "读取文件失败")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 解析并逐行校验
+	rows, err := models.ParseImportFile(header.Filename, data)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+	defaults := models.ImportDefaults{
+		Category:    form.DefaultCategory,
+		Description: form.DefaultDescription,
+		CoverURL:    form.DefaultCoverURL,
+		Quantity:    form.DefaultQuantity,
+	}
+	batch, err := models.PrepareImport(mg.GetUserIDFromSession(c), header.Filename, rows, models.DuplicateMode(form.Mode), defaults)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 渲染预览页面
+	c.HTML(http.StatusOK, "admin/import_books.html", gin.H{
+		"title":      "导入预览",
+		"csrf_token": mg.GenerateCSRFToken(c),
+		"batch":      batch,
+		"created":    batch.Count(models.ImportCreate),
+		"updated":    batch.Count(models.ImportUpdate),
+		"skipped":    batch.Count(models.ImportSkip),
+		"invalid":    batch.Count(models.ImportInvalid),
+	})
+}
+
+// AdminImportBooksConfirmPost 处理POST /admin/import-books/confirm，执行导入并显示汇总报告
+func AdminImportBooksConfirmPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	var form ImportConfirmForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "导入批次无效，请重新上传文件")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 执行导入
+	summary, err := models.RunImport(form.Token, mg.GetUserIDFromSession(c))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/admin/import-books")
+		return
+	}
+
+	// 渲染汇总报告
+	c.HTML(http.StatusOK, "admin/import_books.html", gin.H{
+		"title":   "导入结果",
+		"summary": summary,
+	})
+}
@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+)
+
+// respondBookMetadata 查询书目信息并按API格式返回
+func respondBookMetadata(c *gin.Context, isbn string) {
+	metadata, err := models.LookupBookMetadata(c.Request.Context(), isbn)
+	switch {
+	case err == nil:
+		apiData(c, http.StatusOK, metadata)
+	case errors.Is(err, models.ErrInvalidISBN):
+		middleware.APIError(c, http.StatusUnprocessableEntity, "invalid_isbn", err.Error())
+	case errors.Is(err, models.ErrMetadataNotFound):
+		middleware.APIError(c, http.StatusNotFound, "not_found", err.Error())
+	default:
+		log.Printf("查询书目信息失败: %v", err)
+		middleware.APIError(c, http.StatusBadGateway, "upstream_error", "书目数据源暂时不可用")
+	}
+}
+
+// AdminBookMetadataGet 处理GET /admin/book-metadata，供添加图书页面自动填充
+func AdminBookMetadataGet(c *gin.Context) {
+	respondBookMetadata(c, c.Query("isbn"))
+}
+
+// APIv1MetadataGet 处理GET /api/v1/metadata/:isbn
+func APIv1MetadataGet(c *gin.Context) {
+	respondBookMetadata(c, c.Param("isbn"))
+}
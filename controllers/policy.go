@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// PolicyForm 流通规则表单结构，金额以元为单位填写
+type PolicyForm struct {
+	Role          string `form:"role"`
+	Category      string `form:"category"`
+	LoanDays      int    `form:"loan_days" binding:"required,min=1"`
+	MaxLoans      int    `form:"max_loans" binding:"min=0"`
+	MaxRenewals   int    `form:"max_renewals" binding:"min=0"`
+	FineDailyRate string `form:"fine_daily_rate" binding:"required"`
+	FineMax       string `form:"fine_max" binding:"required"`
+	CSRFToken     string `form:"csrf_token"`
+}
+
+// toPolicy 将表单转换为流通规则
+func (f *PolicyForm) toPolicy(id int) (*models.CirculationPolicy, error) {
+	dailyRate, err := models.ParseAmount(f.FineDailyRate)
+	if err != nil {
+		return nil, err
+	}
+	fineMax, err := models.ParseAmount(f.FineMax)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CirculationPolicy{
+		ID:            id,
+		Role:          models.UserRole(f.Role),
+		Category:      f.Category,
+		LoanDays:      f.LoanDays,
+		MaxLoans:      f.MaxLoans,
+		MaxRenewals:   f.MaxRenewals,
+		FineDailyRate: dailyRate,
+		FineMax:       fineMax,
+	}, nil
+}
+
+// AdminPoliciesGet 处理GET /admin/policies
+func AdminPoliciesGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 渲染流通规则列表页面
+	c.HTML(http.StatusOK, "admin/policies.html", gin.H{
+		"title":          "流通规则",
+		"policies":       models.GetAllPolicies(),
+		"default_policy": models.DefaultCirculationPolicy,
+		"success":        mg.GetFlashMessage(c, "success"),
+		"error":          mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// AdminAddPolicyGet 处理GET /admin/add-policy
+func AdminAddPolicyGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 生成CSRF令牌
+	token := mg.GenerateCSRFToken(c)
+
+	// 以默认规则预填表单
+	policy := models.DefaultCirculationPolicy
+
+	// 渲染添加流通规则页面
+	c.HTML(http.StatusOK, "admin/edit_policy.html", gin.H{
+		"title":      "添加流通规则",
+		"policy":     &policy,
+		"categories": models.GetAllCategories(),
+		"csrf_token": token,
+		"error":      mg.GetFlashMessage(c, "error"),
+		"is_add":     true,
+	})
+}
+
+// AdminAddPolicyPost 处理POST /admin/add-policy
+func AdminAddPolicyPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	var form PolicyForm
+	if err := c.ShouldBind(&form); err != nil {
+		// 表单验证失败
+		mg.SetFlashMessage(c, "error", "请正确填写所有必填字段")
+		c.Redirect(http.StatusFound, "/admin/add-policy")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/add-policy")
+		return
+	}
+
+	// 创建流通规则
+	policy, err := form.toPolicy(0)
+	if err == nil {
+		err = models.CreatePolicy(policy)
+	}
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/admin/add-policy")
+		return
+	}
+
+	// 设置成功消息
+	mg.SetFlashMessage(c, "success", "流通规则已添加")
+
+	// 重定向到流通规则列表
+	c.Redirect(http.StatusFound, "/admin/policies")
+}
+
+// AdminEditPolicyGet 处理GET /admin/edit-policy/:id
+func AdminEditPolicyGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取流通规则ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的流通规则ID",
+		})
+		return
+	}
+
+	// 获取流通规则
+	policy, err := models.GetPolicyByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "流通规则不存在",
+		})
+		return
+	}
+
+	// 生成CSRF令牌
+	token := mg.GenerateCSRFToken(c)
+
+	// 渲染编辑流通规则页面
+	c.HTML(http.StatusOK, "admin/edit_policy.html", gin.H{
+		"title":      "编辑流通规则",
+		"policy":     policy,
+		"categories": models.GetAllCategories(),
+		"csrf_token": token,
+		"error":      mg.GetFlashMessage(c, "error"),
+		"is_add":     false,
+	})
+}
+
+// AdminEditPolicyPost 处理POST /admin/edit-policy/:id
+func AdminEditPolicyPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取流通规则ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的流通规则ID",
+		})
+		return
+	}
+
+	var form PolicyForm
+	if err := c.ShouldBind(&form); err != nil {
+		// 表单验证失败
+		mg.SetFlashMessage(c, "error", "请正确填写所有必填字段")
+		c.Redirect(http.StatusFound, "/admin/edit-policy/"+idStr)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/edit-policy/"+idStr)
+		return
+	}
+
+	// 更新流通规则
+	policy, err := form.toPolicy(id)
+	if err == nil {
+		err = models.UpdatePolicy(policy)
+	}
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+		c.Redirect(http.StatusFound, "/admin/edit-policy/"+idStr)
+		return
+	}
+
+	// 设置成功消息
+	mg.SetFlashMessage(c, "success", "流通规则已更新")
+
+	// 重定向到流通规则列表
+	c.Redirect(http.StatusFound, "/admin/policies")
+}
+
+// AdminDeletePolicyGet 处理GET /admin/delete-policy/:id
+func AdminDeletePolicyGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	// 获取流通规则ID
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的流通规则ID",
+		})
+		return
+	}
+
+	// 删除流通规则
+	if err := models.DeletePolicy(id); err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "流通规则已删除")
+	}
+
+	// 重定向到流通规则列表
+	c.Redirect(http.StatusFound, "/admin/policies")
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// ReviewForm 读者评价表单
+type ReviewForm struct {
+	Rating    int    `form:"rating" json:"rating" binding:"required,min=1,max=5"`
+	Content   string `form:"content" json:"content" binding:"max=2000"`
+	CSRFToken string `form:"csrf_token" json:"-"`
+}
+
+// ReviewView 评价展示信息
+type ReviewView struct {
+	Review *models.Review
+	Book   *models.Book
+	User   *models.User
+}
+
+// reviewStatuses 审核队列可筛选的状态
+var reviewStatuses = map[string]models.ReviewStatus{
+	"pending":  models.ReviewPending,
+	"approved": models.ReviewApproved,
+	"hidden":   models.ReviewHidden,
+	"all":      "",
+}
+
+// buildReviewViews 为评价补充图书和读者信息
+func buildReviewViews(reviews []*models.Review) []ReviewView {
+	bookMap := make(map[int]*models.Book)
+	for _, book := range models.GetAllBooks() {
+		bookMap[book.ID] = book
+	}
+	userMap := make(map[int]*models.User)
+	for _, user := range models.GetAllUsers() {
+		userMap[user.ID] = user
+	}
+
+	views := make([]ReviewView, 0, len(reviews))
+	for _, review := range reviews {
+		views = append(views, ReviewView{
+			Review: review,
+			Book:   bookMap[review.BookID],
+			User:   userMap[review.UserID],
+		})
+	}
+	return views
+}
+
+// bookReviewData 图书详情页的评价列表和当前读者的评价表单
+func bookReviewData(c *gin.Context, mg *utils.SessionManager, data gin.H, bookID, userID int) {
+	data["reviews"] = buildReviewViews(models.GetBookReviews(bookID))
+	data["success"] = mg.GetFlashMessage(c, "success")
+	data["error"] = mg.GetFlashMessage(c, "error")
+	if userID == 0 {
+		return
+	}
+
+	data["my_review"] = models.GetUserReview(userID, bookID)
+	if models.CanReview(userID, bookID) {
+		data["can_review"] = true
+		data["csrf_token"] = mg.GenerateCSRFToken(c)
+	}
+}
+
+// ReaderReviewPost 处理POST /reader/review/:id，读者评价借阅过的图书
+func ReaderReviewPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的图书ID",
+		})
+		return
+	}
+	redirect := "/books/" + idStr + "#reviews"
+
+	var form ReviewForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请选择1到5星的评分，评价不超过2000个字符")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	userID := mg.GetUserIDFromSession(c)
+	if _, err := models.SubmitReview(userID, id, form.Rating, form.Content); err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "评价已提交，审核通过后公开显示")
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// LibrarianReviewsGet 处理GET /librarian/reviews，默认显示待审核的评价
+func LibrarianReviewsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	status := c.DefaultQuery("status", "pending")
+	filter, ok := reviewStatuses[status]
+	if !ok {
+		status, filter = "pending", models.ReviewPending
+	}
+
+	c.HTML(http.StatusOK, "librarian/reviews.html", gin.H{
+		"title":      "评价审核",
+		"reviews":    buildReviewViews(models.GetReviewsByStatus(filter)),
+		"status":     status,
+		"csrf_token": mg.GenerateCSRFToken(c),
+		"success":    mg.GetFlashMessage(c, "success"),
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// LibrarianModerateReviewPost 处理POST /librarian/reviews/:id，通过或隐藏评价
+func LibrarianModerateReviewPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的评价ID",
+		})
+		return
+	}
+
+	// 处理后回到原来的筛选状态
+	redirect := "/librarian/reviews"
+	if status := c.PostForm("status"); status != "" {
+		if _, ok := reviewStatuses[status]; ok {
+			redirect += "?status=" + status
+		}
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, c.PostForm("csrf_token")) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	review, err := models.ModerateReview(id, models.ReviewStatus(c.PostForm("action")))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "评价"+review.StatusText())
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// APIv1BookReviewsGet 处理GET /api/v1/books/:id/reviews，返回已通过审核的评价
+func APIv1BookReviewsGet(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	book, err := models.GetBookByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	reviews := models.GetBookReviews(id)
+	if reviews == nil {
+		reviews = []*models.Review{}
+	}
+	apiData(c, http.StatusOK, gin.H{
+		"rating_average": book.RatingAverage,
+		"rating_count":   book.RatingCount,
+		"reviews":        reviews,
+	})
+}
+
+// APIv1BookReviewPost 处理POST /api/v1/books/:id/reviews，当前用户评价借阅过的图书，提交后进入审核
+func APIv1BookReviewPost(c *gin.Context) {
+	user := middleware.APIUser(c)
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	var req ReviewForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请提供1到5的评分 rating，评价 content 不超过2000个字符")
+		return
+	}
+
+	review, err := models.SubmitReview(user.ID, id, req.Rating, req.Content)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusCreated, review)
+}
+
+// APIv1ReviewsGet 处理GET /api/v1/reviews，按状态列出评价，默认为待审核
+func APIv1ReviewsGet(c *gin.Context) {
+	filter, ok := reviewStatuses[c.DefaultQuery("status", "pending")]
+	if !ok {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_query", "status 须为 pending、approved、hidden 或 all")
+		return
+	}
+
+	reviews := models.GetReviewsByStatus(filter)
+	if reviews == nil {
+		reviews = []*models.Review{}
+	}
+	apiData(c, http.StatusOK, reviews)
+}
+
+// APIv1ReviewPut 处理PUT /api/v1/reviews/:id，审核评价，status 为 approved 或 hidden
+func APIv1ReviewPut(c *gin.Context) {
+	id, ok := apiParamID(c, "评价")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Status models.ReviewStatus `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请提供审核结果 status（approved 或 hidden）")
+		return
+	}
+
+	review, err := models.ModerateReview(id, req.Status)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, review)
+}
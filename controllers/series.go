@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// SeriesForm 修改丛书表单
+type SeriesForm struct {
+	Name        string `form:"name" json:"name" binding:"required,max=100"`
+	Description string `form:"description" json:"description"`
+	CSRFToken   string `form:"csrf_token" json:"-"`
+}
+
+// bookSeriesFormData 图书表单中可选的丛书，book 不为空时填入图书当前的丛书名和卷次
+func bookSeriesFormData(data gin.H, book *models.Book) {
+	if book != nil {
+		if info := models.GetBookSeries(book.ID); info != nil {
+			data["series"] = info.Series.Name
+			data["volume"] = info.Volume
+		}
+	}
+	series, err := models.GetAllSeries()
+	if err != nil {
+		log.Printf("获取丛书列表失败: %v", err)
+		return
+	}
+	data["series_options"] = series
+}
+
+// apiSetBookSeries 按请求设置图书所属丛书，请求中未携带 series 时保持不变
+func apiSetBookSeries(bookID int, req BookRequest) error {
+	if req.Series == nil {
+		return nil
+	}
+	return models.SetBookSeries(bookID, *req.Series, req.Volume)
+}
+
+// SeriesListGet 处理GET /series
+func SeriesListGet(c *gin.Context) {
+	series, err := models.GetAllSeries()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取丛书列表失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "series_list.html", gin.H{
+		"title":  "丛书",
+		"series": series,
+	})
+}
+
+// SeriesDetailGet 处理GET /series/:id，按卷次列出丛书各册及可借情况
+func SeriesDetailGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的丛书ID",
+		})
+		return
+	}
+
+	series, err := models.GetSeriesByID(id)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "丛书不存在",
+		})
+		return
+	}
+	volumes, err := models.GetSeriesVolumes(id)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取丛书卷次失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "series_detail.html", gin.H{
+		"title":     series.Name,
+		"series":    series,
+		"volumes":   volumes,
+		"user_role": mg.GetUserRoleFromSession(c),
+	})
+}
+
+// AdminSeriesGet 处理GET /admin/series
+func AdminSeriesGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	series, err := models.GetAllSeries()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取丛书列表失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin/series.html", gin.H{
+		"title":      "丛书管理",
+		"series":     series,
+		"csrf_token": mg.GenerateCSRFToken(c),
+		"success":    mg.GetFlashMessage(c, "success"),
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// AdminEditSeriesPost 处理POST /admin/series/:id，修改丛书名称和简介
+func AdminEditSeriesPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的丛书ID",
+		})
+		return
+	}
+
+	var form SeriesForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请填写丛书名（不超过100个字符）")
+		c.Redirect(http.StatusFound, "/admin/series")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/series")
+		return
+	}
+
+	series, err := models.UpdateSeries(id, form.Name, form.Description)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "丛书已更新："+series.Name)
+	}
+	c.Redirect(http.StatusFound, "/admin/series")
+}
+
+// AdminDeleteSeriesPost 处理POST /admin/series/:id/delete
+func AdminDeleteSeriesPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的丛书ID",
+		})
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, c.PostForm("csrf_token")) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/series")
+		return
+	}
+
+	if err := models.DeleteSeries(id); err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "丛书已删除")
+	}
+	c.Redirect(http.StatusFound, "/admin/series")
+}
+
+// APIv1SeriesListGet 处理GET /api/v1/series，返回全部丛书及其册数
+func APIv1SeriesListGet(c *gin.Context) {
+	series, err := models.GetAllSeries()
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询丛书失败")
+		return
+	}
+	apiData(c, http.StatusOK, series)
+}
+
+// APIv1SeriesGet 处理GET /api/v1/series/:id，返回丛书及各册可借数量
+func APIv1SeriesGet(c *gin.Context) {
+	id, ok := apiParamID(c, "丛书")
+	if !ok {
+		return
+	}
+
+	series, err := models.GetSeriesByID(id)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	volumes, err := models.GetSeriesVolumes(id)
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询丛书卷次失败")
+		return
+	}
+	apiData(c, http.StatusOK, gin.H{
+		"series":  series,
+		"volumes": volumes,
+	})
+}
+
+// APIv1SeriesPut 处理PUT /api/v1/series/:id，修改丛书名称和简介
+func APIv1SeriesPut(c *gin.Context) {
+	id, ok := apiParamID(c, "丛书")
+	if !ok {
+		return
+	}
+
+	var req SeriesForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请填写丛书名 name（不超过100个字符）")
+		return
+	}
+
+	series, err := models.UpdateSeries(id, req.Name, req.Description)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, series)
+}
+
+// APIv1SeriesDelete 处理DELETE /api/v1/series/:id，丛书中的图书不再属于任何丛书
+func APIv1SeriesDelete(c *gin.Context) {
+	id, ok := apiParamID(c, "丛书")
+	if !ok {
+		return
+	}
+
+	if err := models.DeleteSeries(id); err != nil {
+		apiModelError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// shelfNeighborCount 书架浏览时前后各显示的图书数量
+const shelfNeighborCount = 5
+
+// ShelvingForm 索书号和默认架位表单
+type ShelvingForm struct {
+	CallNumber    string `form:"call_number"`
+	ShelfLocation string `form:"shelf_location"`
+	CSRFToken     string `form:"csrf_token"`
+}
+
+// CopyLocation 图书详情页上一个副本的架位和状态
+type CopyLocation struct {
+	Barcode    string
+	Location   string
+	Status     models.CopyStatus
+	StatusText string
+}
+
+// copyLocations 图书在馆副本（不含遗失和剔除）的架位，副本未登记架位时使用图书的默认架位
+func copyLocations(book *models.Book) []CopyLocation {
+	var locations []CopyLocation
+	for _, item := range models.GetCopiesByBookID(book.ID) {
+		if item.Status == models.CopyLost || item.Status == models.CopyWithdrawn {
+			continue
+		}
+		locations = append(locations, CopyLocation{
+			Barcode:    item.Barcode,
+			Location:   item.LocationFor(book),
+			Status:     item.Status,
+			StatusText: item.StatusText(),
+		})
+	}
+	return locations
+}
+
+// validateShelving 在写入图书之前校验请求中的索书号和默认架位
+func (req *BookRequest) validateShelving() error {
+	var callNumber, shelfLocation string
+	if req.CallNumber != nil {
+		callNumber = *req.CallNumber
+	}
+	if req.ShelfLocation != nil {
+		shelfLocation = *req.ShelfLocation
+	}
+	return models.ValidateShelving(callNumber, shelfLocation)
+}
+
+// apiSetBookShelving 按请求设置索书号和默认架位，请求中未携带的字段保持不变
+func apiSetBookShelving(book *models.Book, req BookRequest) (*models.Book, error) {
+	if req.CallNumber == nil && req.ShelfLocation == nil {
+		return book, nil
+	}
+	callNumber, shelfLocation := book.CallNumber, book.ShelfLocation
+	if req.CallNumber != nil {
+		callNumber = *req.CallNumber
+	}
+	if req.ShelfLocation != nil {
+		shelfLocation = *req.ShelfLocation
+	}
+	return models.SetBookShelving(book.ID, callNumber, shelfLocation)
+}
+
+// BookShelfGet 处理GET /books/:id/shelf，按索书号顺序浏览图书前后的相邻图书
+func BookShelfGet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的图书ID",
+		})
+		return
+	}
+
+	view, err := models.BrowseShelf(id, shelfNeighborCount)
+	if errors.Is(err, models.ErrNoCallNumber) {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "该图书尚未编制索书号，暂不能浏览书架",
+		})
+		return
+	}
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "图书不存在",
+		})
+		return
+	}
+
+	// 两侧取满时可能还有更多图书，以最外侧的图书为中心继续浏览
+	data := gin.H{
+		"title": "浏览书架：" + view.Book.CallNumber,
+		"shelf": view,
+	}
+	if len(view.Before) == shelfNeighborCount {
+		data["prev_id"] = view.Before[0].ID
+	}
+	if len(view.After) == shelfNeighborCount {
+		data["next_id"] = view.After[len(view.After)-1].ID
+	}
+	c.HTML(http.StatusOK, "shelf.html", data)
+}
+
+// LibrarianShelvingPost 处理POST /librarian/copies/:id/shelving，设置图书的索书号和默认架位
+func LibrarianShelvingPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的图书ID",
+		})
+		return
+	}
+
+	var form ShelvingForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请正确填写索书号和架位")
+		c.Redirect(http.StatusFound, "/librarian/copies/"+idStr)
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/librarian/copies/"+idStr)
+		return
+	}
+
+	book, err := models.SetBookShelving(id, form.CallNumber, form.ShelfLocation)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else if book.CallNumber == "" {
+		mg.SetFlashMessage(c, "success", "已清除索书号")
+	} else {
+		mg.SetFlashMessage(c, "success", "索书号已更新："+book.CallNumber)
+	}
+	c.Redirect(http.StatusFound, "/librarian/copies/"+idStr)
+}
+
+// APIv1BookShelfGet 处理GET /api/v1/books/:id/shelf，返回按索书号排在图书前后的相邻图书，n 为每侧数量
+func APIv1BookShelfGet(c *gin.Context) {
+	id, ok := apiParamID(c, "图书")
+	if !ok {
+		return
+	}
+
+	n := shelfNeighborCount
+	if v, err := strconv.Atoi(c.Query("n")); err == nil && v > 0 {
+		n = v
+	}
+	if n > models.MaxPageSize {
+		n = models.MaxPageSize
+	}
+
+	view, err := models.BrowseShelf(id, n)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, view)
+}
@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/middleware"
+	"librarysystem/models"
+	"librarysystem/utils"
+)
+
+// TagForm 修改标签表单
+type TagForm struct {
+	Name      string `form:"name" json:"name" binding:"required,max=50"`
+	Kind      string `form:"kind" json:"kind" binding:"required,oneof=tag subject"`
+	CSRFToken string `form:"csrf_token" json:"-"`
+}
+
+// TagMergeRequest 合并标签请求
+type TagMergeRequest struct {
+	Into int `form:"into" json:"into" binding:"required,min=1"`
+}
+
+// TagFilter 图书列表中已选中的标签，点击取消该筛选
+type TagFilter struct {
+	Name      string
+	RemoveURL template.URL
+}
+
+// bookTagsInput 表单中的主题词和标签
+func bookTagsInput(form BookForm) map[models.TagKind][]string {
+	return map[models.TagKind][]string{
+		models.TagSubject: models.ParseTagNames(form.Subjects),
+		models.TagTopic:   models.ParseTagNames(form.Tags),
+	}
+}
+
+// apiSetBookTags 按请求替换图书的主题词和标签，请求中未携带的类型保持不变
+func apiSetBookTags(bookID int, req BookRequest) error {
+	input := requestTagsInput(req)
+	if input == nil {
+		return nil
+	}
+	return models.SetBookTags(bookID, input)
+}
+
+// requestTagsInput 请求中的主题词和标签，两者都未携带时返回 nil
+func requestTagsInput(req BookRequest) map[models.TagKind][]string {
+	input := make(map[models.TagKind][]string)
+	if req.Subjects != nil {
+		input[models.TagSubject] = models.ParseTagNames(strings.Join(req.Subjects, "、"))
+	}
+	if req.Tags != nil {
+		input[models.TagTopic] = models.ParseTagNames(strings.Join(req.Tags, "、"))
+	}
+	if len(input) == 0 {
+		return nil
+	}
+	return input
+}
+
+// tagFilters 当前选中的标签及取消各标签筛选的链接
+func tagFilters(c *gin.Context, tags []string) []TagFilter {
+	filters := make([]TagFilter, 0, len(tags))
+	for i, tag := range tags {
+		values := c.Request.URL.Query()
+		values.Del("page")
+		values.Del("cursor")
+		values.Del("tag")
+		for j, other := range tags {
+			if j != i {
+				values.Add("tag", other)
+			}
+		}
+		filters = append(filters, TagFilter{Name: tag, RemoveURL: encodeListURL(c, values)})
+	}
+	return filters
+}
+
+// encodeListURL 以当前路径和给定参数生成链接
+func encodeListURL(c *gin.Context, values url.Values) template.URL {
+	if encoded := values.Encode(); encoded != "" {
+		return template.URL(c.Request.URL.Path + "?" + encoded)
+	}
+	return template.URL(c.Request.URL.Path)
+}
+
+// AdminTagsGet 处理GET /admin/tags
+func AdminTagsGet(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	tags, err := models.GetAllTags()
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+			"error": "获取标签列表失败",
+		})
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin/tags.html", gin.H{
+		"title":      "标签管理",
+		"tags":       tags,
+		"tag_kinds":  models.TagKinds,
+		"csrf_token": mg.GenerateCSRFToken(c),
+		"success":    mg.GetFlashMessage(c, "success"),
+		"error":      mg.GetFlashMessage(c, "error"),
+	})
+}
+
+// AdminEditTagPost 处理POST /admin/tags/:id，修改标签名称和类型
+func AdminEditTagPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的标签ID",
+		})
+		return
+	}
+
+	var form TagForm
+	if err := c.ShouldBind(&form); err != nil {
+		mg.SetFlashMessage(c, "error", "请填写标签名称（不超过50个字符）并选择类型")
+		c.Redirect(http.StatusFound, "/admin/tags")
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, form.CSRFToken) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/tags")
+		return
+	}
+
+	tag, err := models.RenameTag(id, form.Name, models.TagKind(form.Kind))
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "标签已更新："+tag.Name)
+	}
+	c.Redirect(http.StatusFound, "/admin/tags")
+}
+
+// AdminMergeTagPost 处理POST /admin/tags/:id/merge，将标签并入另一个标签
+func AdminMergeTagPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的标签ID",
+		})
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, c.PostForm("csrf_token")) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/tags")
+		return
+	}
+
+	var req TagMergeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		mg.SetFlashMessage(c, "error", "请选择要并入的标签")
+		c.Redirect(http.StatusFound, "/admin/tags")
+		return
+	}
+
+	into, err := models.MergeTags(id, req.Into)
+	if err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "标签已并入："+into.Name)
+	}
+	c.Redirect(http.StatusFound, "/admin/tags")
+}
+
+// AdminDeleteTagPost 处理POST /admin/tags/:id/delete
+func AdminDeleteTagPost(c *gin.Context) {
+	mg := utils.NewSessionManager(c)
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.HTML(http.StatusNotFound, "error.html", gin.H{
+			"error": "无效的标签ID",
+		})
+		return
+	}
+
+	// 验证CSRF令牌
+	if !mg.VerifyCSRFToken(c, c.PostForm("csrf_token")) {
+		mg.SetFlashMessage(c, "error", "安全验证失败，请重试")
+		c.Redirect(http.StatusFound, "/admin/tags")
+		return
+	}
+
+	if err := models.DeleteTag(id); err != nil {
+		mg.SetFlashMessage(c, "error", err.Error())
+	} else {
+		mg.SetFlashMessage(c, "success", "标签已删除")
+	}
+	c.Redirect(http.StatusFound, "/admin/tags")
+}
+
+// APIv1TagsGet 处理GET /api/v1/tags，返回全部标签及其图书数量
+func APIv1TagsGet(c *gin.Context) {
+	tags, err := models.GetAllTags()
+	if err != nil {
+		middleware.APIError(c, http.StatusInternalServerError, "internal_error", "查询标签失败")
+		return
+	}
+	apiData(c, http.StatusOK, tags)
+}
+
+// APIv1TagPut 处理PUT /api/v1/tags/:id，修改标签名称和类型
+func APIv1TagPut(c *gin.Context) {
+	id, ok := apiParamID(c, "标签")
+	if !ok {
+		return
+	}
+
+	var req TagForm
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请填写标签名称（不超过50个字符）和类型（tag 或 subject）")
+		return
+	}
+
+	tag, err := models.RenameTag(id, req.Name, models.TagKind(req.Kind))
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, tag)
+}
+
+// APIv1TagMergePost 处理POST /api/v1/tags/:id/merge，将标签并入 into 指定的标签
+func APIv1TagMergePost(c *gin.Context) {
+	id, ok := apiParamID(c, "标签")
+	if !ok {
+		return
+	}
+
+	var req TagMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.APIError(c, http.StatusBadRequest, "invalid_request", "请指定要并入的标签 into")
+		return
+	}
+
+	into, err := models.MergeTags(id, req.Into)
+	if err != nil {
+		apiModelError(c, err)
+		return
+	}
+	apiData(c, http.StatusOK, into)
+}
@@ -1,96 +1,57 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"time"
 
 	"librarysystem/config"
+	"librarysystem/models"
 )
 
-// InitTables 初始化数据库表
-func InitTables() {
-	log.Println("初始化数据库表...")
-
-	db := config.GetDB()
-
-	// 创建用户表
-	_, err := db.Exec(`
-                CREATE TABLE IF NOT EXISTS users (
-                id INT AUTO_INCREMENT PRIMARY KEY,
-                username VARCHAR(100) NOT NULL UNIQUE,
-                email VARCHAR(200) NOT NULL UNIQUE,
-                password_hash VARCHAR(255) NOT NULL,
-                role VARCHAR(20) NOT NULL,
-                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-                );
-        `)
-	if err != nil {
-		log.Fatalf("创建用户表失败: %v", err)
-	}
-
-	// 创建图书表
-	_, err = db.Exec(`
-        CREATE TABLE IF NOT EXISTS books (
-            id INT AUTO_INCREMENT PRIMARY KEY,
-            title VARCHAR(200) NOT NULL,
-            author VARCHAR(100) NOT NULL,
-            isbn VARCHAR(20) NOT NULL UNIQUE,
-            published_year INTEGER NOT NULL,
-            category VARCHAR(50) NOT NULL,
-            description TEXT NOT NULL,
-            cover_url TEXT NOT NULL,
-            quantity INTEGER NOT NULL,
-            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-        )`)
-	if err != nil {
-		log.Fatalf("创建图书表失败: %v", err)
-	}
-
-	// 单独创建借阅记录表
-	_, err = db.Exec(`
-        CREATE TABLE IF NOT EXISTS borrow_records (
-            id INT AUTO_INCREMENT PRIMARY KEY,
-            user_id INT NOT NULL,
-            book_id INT NOT NULL,
-            borrow_date TIMESTAMP NOT NULL,
-            due_date TIMESTAMP NOT NULL,
-            return_date TIMESTAMP NULL,
-            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-            FOREIGN KEY (user_id) REFERENCES users(id),
-            FOREIGN KEY (book_id) REFERENCES books(id)
-        )`)
-	if err != nil {
-		log.Fatalf("创建借阅记录表失败: %v", err)
-	}
-
-	log.Println("数据库表初始化完成")
+// clearTables ClearData 依次清空的数据表，子表在前、被引用的表在后
+var clearTables = []struct {
+	name  string
+	label string
+}{
+	{"reviews", "书评表"},
+	{"series_volumes", "丛书分册表"},
+	{"series", "丛书表"},
+	{"book_tags", "图书标签表"},
+	{"tags", "标签表"},
+	{"book_contributors", "图书责任者表"},
+	{"contributors", "责任者表"},
+	{"fine_entries", "罚款记录表"},
+	{"borrow_renewals", "续借记录表"},
+	{"holds", "预约表"},
+	{"borrow_records", "借阅记录表"},
+	{"book_copies", "图书副本表"},
+	{"books", "图书表"},
+	{"classifications", "分类表"},
+	{"api_tokens", "API令牌表"},
+	{"sessions", "会话表"},
+	{"users", "用户表"},
 }
 
-// ClearData 清空数据库数据（仅用于开发和测试）
-func ClearData() {
+// ClearData 清空数据库数据（仅用于开发和测试），流通策略等配置保留
+func ClearData() error {
 	log.Println("清空所有数据表...")
 
 	db := config.GetDB()
 
-	// 清空借阅记录表
-	_, err := db.Exec("DELETE FROM borrow_records")
-	if err != nil {
-		log.Printf("清空借阅记录表失败: %v", err)
-	}
-
-	// 清空图书表
-	_, err = db.Exec("DELETE FROM books")
-	if err != nil {
-		log.Printf("清空图书表失败: %v", err)
+	for _, table := range clearTables {
+		if _, err := db.Exec("DELETE FROM " + table.name); err != nil {
+			return fmt.Errorf("清空%s失败: %w", table.label, err)
+		}
 	}
 
-	// 清空用户表
-	_, err = db.Exec("DELETE FROM users")
-	if err != nil {
-		log.Printf("清空用户表失败: %v", err)
+	// 通知其他实例重建搜索索引
+	if _, err := db.Exec("UPDATE catalog_version SET version = version + 1 WHERE id = 1"); err != nil {
+		return fmt.Errorf("更新目录版本失败: %w", err)
 	}
 
 	log.Println("所有数据表已清空")
+	return nil
 }
 
 // SeedData 填充初始数据
@@ -117,6 +78,9 @@ func SeedData() {
 	// 添加借阅记录
 	InitBorrowRecords()
 
+	// 为示例图书登记副本
+	InitBookCopies()
+
 	log.Println("初始数据填充完成")
 }
 
@@ -125,10 +89,10 @@ func InitUsers() {
 	log.Println("初始化用户数据...")
 	db := config.GetDB()
 
-	// 创建密码哈希（在实际应用中应使用bcrypt）
-	adminPassword := "admin123"
-	librarianPassword := "librarian123"
-	readerPassword := "reader123"
+	// 创建密码哈希
+	adminPassword := mustHashPassword("admin123")
+	librarianPassword := mustHashPassword("librarian123")
+	readerPassword := mustHashPassword("reader123")
 
 	// 修改后的插入语句
 	_, err := db.Exec(`
@@ -147,6 +111,15 @@ func InitUsers() {
 	log.Println("用户数据初始化完成")
 }
 
+// mustHashPassword 生成示例用户的密码哈希
+func mustHashPassword(password string) string {
+	hash, err := models.HashPassword(password)
+	if err != nil {
+		log.Fatalf("生成密码哈希失败: %v", err)
+	}
+	return hash
+}
+
 // InitBooks 初始化图书数据
 func InitBooks() {
 	log.Println("初始化图书数据...")
@@ -183,6 +156,20 @@ INSERT INTO books (title, author, isbn, published_year, category, description, c
 	log.Println("图书数据初始化完成")
 }
 
+// InitBookCopies 按图书数量生成副本，并为未归还的借阅分配副本
+func InitBookCopies() {
+	log.Println("初始化图书副本...")
+	db := config.GetDB()
+
+	for _, statement := range backfillBookCopies {
+		if _, err := db.Exec(statement); err != nil {
+			log.Fatalf("初始化图书副本失败: %v", err)
+		}
+	}
+
+	log.Println("图书副本初始化完成")
+}
+
 // InitBorrowRecords 初始化借阅记录
 func InitBorrowRecords() {
 	log.Println("初始化借阅记录...")
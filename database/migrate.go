@@ -0,0 +1,259 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"librarysystem/config"
+)
+
+// 迁移重跑时表示语句已生效的MySQL错误码
+const (
+	errDupFieldName     = 1060 // 列已存在
+	errDupKeyName       = 1061 // 索引已存在
+	errCantDropFieldKey = 1091 // 要删除的列或索引不存在
+	errFKDupName        = 1826 // 外键约束已存在
+	errNoSuchTable      = 1146 // 表不存在
+)
+
+// Migration 单个版本化的数据库迁移
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string // 升级语句，按顺序执行
+	Down    []string // 回滚语句，按顺序执行
+}
+
+// MigrationStatus 迁移的执行状态
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	DryRun     bool      // 仅打印将要执行的SQL，不修改数据库
+	Out        io.Writer // 执行日志输出
+}
+
+// NewMigrator 创建迁移执行器，使用已注册的全部迁移
+func NewMigrator(db *sql.DB) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+		Out:        os.Stdout,
+	}
+}
+
+// RunMigrations 启动时将数据库升级到最新版本
+func RunMigrations() {
+	log.Println("执行数据库迁移...")
+
+	if err := NewMigrator(config.GetDB()).Up(0); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+
+	log.Println("数据库迁移完成")
+}
+
+// Up 依次执行未应用的迁移，target 为 0 时升级到最新版本
+func (m *Migrator) Up(target int) error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if target > 0 && migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		m.logf("升级 %d_%s\n", migration.Version, migration.Name)
+		if err := m.apply(migration.Up); err != nil {
+			return fmt.Errorf("迁移 %d_%s 升级失败: %w", migration.Version, migration.Name, err)
+		}
+		if err := m.exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Down 按倒序回滚最近应用的 steps 个迁移
+func (m *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("回滚步数必须大于0")
+	}
+
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		m.logf("回滚 %d_%s\n", migration.Version, migration.Name)
+		if err := m.apply(migration.Down); err != nil {
+			return fmt.Errorf("迁移 %d_%s 回滚失败: %w", migration.Version, migration.Name, err)
+		}
+		if err := m.exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version); err != nil {
+			return err
+		}
+		steps--
+	}
+
+	return nil
+}
+
+// Status 返回所有迁移及其执行状态
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// ensureTable 创建迁移记录表，演练模式下不修改数据库
+func (m *Migrator) ensureTable() error {
+	if m.DryRun {
+		return nil
+	}
+
+	_, err := m.db.Exec(`
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INT PRIMARY KEY,
+            name VARCHAR(200) NOT NULL,
+            applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )`)
+	if err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions 读取已应用的迁移版本
+func (m *Migrator) appliedVersions() (map[int]time.Time, error) {
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		// 演练模式不创建迁移记录表，表不存在即视为尚未应用任何迁移
+		if m.DryRun && isMySQLError(err, errNoSuchTable) {
+			return map[int]time.Time{}, nil
+		}
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// apply 顺序执行一组迁移语句
+//
+// 迁移记录在全部语句成功后才写入，中途失败的迁移会从头重跑。建表语句使用
+// IF NOT EXISTS、数据语句自带存在性条件；单条 ALTER TABLE 是原子的，重跑时
+// 报列、索引或外键已存在（回滚时报不存在）说明该语句上次已经生效，直接跳过
+func (m *Migrator) apply(statements []string) error {
+	for _, statement := range statements {
+		err := m.exec(statement)
+		if isMySQLError(err, errDupFieldName, errDupKeyName, errCantDropFieldKey, errFKDupName) {
+			m.logf("语句已生效，跳过: %v\n", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isMySQLError 判断错误是否为指定错误码的MySQL错误
+func isMySQLError(err error, numbers ...uint16) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	for _, number := range numbers {
+		if mysqlErr.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// exec 执行单条语句，演练模式下只输出SQL
+func (m *Migrator) exec(statement string, args ...interface{}) error {
+	if m.DryRun {
+		if len(args) > 0 {
+			m.logf("%s -- %v\n", statement, args)
+		} else {
+			m.logf("%s;\n", statement)
+		}
+		return nil
+	}
+
+	_, err := m.db.Exec(statement, args...)
+	return err
+}
+
+// logf 输出迁移日志
+func (m *Migrator) logf(format string, args ...interface{}) {
+	if m.Out != nil {
+		fmt.Fprintf(m.Out, format, args...)
+	}
+}
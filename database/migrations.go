@@ -0,0 +1,384 @@
+package database
+
+// migrations 已注册的全部迁移，新增表结构变更时在末尾追加新版本
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_core_tables",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS users (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                username VARCHAR(100) NOT NULL UNIQUE,
+                email VARCHAR(200) NOT NULL UNIQUE,
+                password_hash VARCHAR(255) NOT NULL,
+                role VARCHAR(20) NOT NULL,
+                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+            )`,
+			`CREATE TABLE IF NOT EXISTS books (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                title VARCHAR(200) NOT NULL,
+                author VARCHAR(100) NOT NULL,
+                isbn VARCHAR(20) NOT NULL UNIQUE,
+                published_year INTEGER NOT NULL,
+                category VARCHAR(50) NOT NULL,
+                description TEXT NOT NULL,
+                cover_url TEXT NOT NULL,
+                quantity INTEGER NOT NULL,
+                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+            )`,
+			`CREATE TABLE IF NOT EXISTS borrow_records (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                user_id INT NOT NULL,
+                book_id INT NOT NULL,
+                borrow_date TIMESTAMP NOT NULL,
+                due_date TIMESTAMP NOT NULL,
+                return_date TIMESTAMP NULL,
+                created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                FOREIGN KEY (user_id) REFERENCES users(id),
+                FOREIGN KEY (book_id) REFERENCES books(id)
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS borrow_records`,
+			`DROP TABLE IF EXISTS books`,
+			`DROP TABLE IF EXISTS users`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "create_sessions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS sessions (
+                id VARCHAR(64) PRIMARY KEY,
+                data BLOB NOT NULL,
+                expiry TIMESTAMP NOT NULL,
+                INDEX idx_sessions_expiry (expiry)
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS sessions`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "hash_plaintext_passwords",
+		// 早期示例数据以明文写入 password_hash，这里统一转换为旧版 SHA-256 哈希，
+		// 用户下次登录时会自动升级为当前算法
+		Up: []string{
+			`UPDATE users SET password_hash = SHA2(password_hash, 256)
+            WHERE password_hash NOT LIKE '$%' AND CHAR_LENGTH(password_hash) <> 64`,
+		},
+		Down: []string{},
+	},
+	{
+		Version: 4,
+		Name:    "create_holds",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS holds (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                user_id INT NOT NULL,
+                book_id INT NOT NULL,
+                status VARCHAR(20) NOT NULL,
+                created_at TIMESTAMP NOT NULL,
+                ready_at TIMESTAMP NULL,
+                pickup_deadline TIMESTAMP NULL,
+                INDEX idx_holds_book_status (book_id, status),
+                FOREIGN KEY (user_id) REFERENCES users(id),
+                FOREIGN KEY (book_id) REFERENCES books(id)
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS holds`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_borrow_renewals",
+		Up: []string{
+			`ALTER TABLE borrow_records ADD COLUMN renew_count INT NOT NULL DEFAULT 0`,
+			`CREATE TABLE IF NOT EXISTS borrow_renewals (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                record_id INT NOT NULL,
+                renewed_by INT NOT NULL,
+                renewed_at TIMESTAMP NOT NULL,
+                previous_due_date TIMESTAMP NOT NULL,
+                new_due_date TIMESTAMP NOT NULL,
+                FOREIGN KEY (record_id) REFERENCES borrow_records(id)
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS borrow_renewals`,
+			`ALTER TABLE borrow_records DROP COLUMN renew_count`,
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_fine_entries",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS fine_entries (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                user_id INT NOT NULL,
+                record_id INT NULL,
+                type VARCHAR(20) NOT NULL,
+                amount INT NOT NULL,
+                note VARCHAR(255) NOT NULL DEFAULT '',
+                created_by INT NULL,
+                created_at TIMESTAMP NOT NULL,
+                INDEX idx_fine_entries_user (user_id),
+                FOREIGN KEY (user_id) REFERENCES users(id),
+                FOREIGN KEY (record_id) REFERENCES borrow_records(id)
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS fine_entries`,
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create_circulation_policies",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS circulation_policies (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                role VARCHAR(20) NOT NULL DEFAULT '',
+                category VARCHAR(50) NOT NULL DEFAULT '',
+                loan_days INT NOT NULL,
+                max_loans INT NOT NULL,
+                max_renewals INT NOT NULL,
+                fine_daily_rate INT NOT NULL,
+                fine_max INT NOT NULL,
+                UNIQUE KEY uk_circulation_policies (role, category)
+            )`,
+			`INSERT IGNORE INTO circulation_policies (role, category, loan_days, max_loans, max_renewals, fine_daily_rate, fine_max)
+            VALUES ('', '', 14, 5, 2, 50, 5000)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS circulation_policies`,
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_book_copies",
+		Up: append([]string{
+			`CREATE TABLE IF NOT EXISTS book_copies (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                book_id INT NOT NULL,
+                barcode VARCHAR(50) NOT NULL UNIQUE,
+                shelf_location VARCHAR(100) NOT NULL DEFAULT '',
+                ` + "`condition`" + ` VARCHAR(20) NOT NULL DEFAULT 'good',
+                status VARCHAR(20) NOT NULL DEFAULT 'available',
+                created_at TIMESTAMP NOT NULL,
+                INDEX idx_book_copies_book_status (book_id, status),
+                FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE
+            )`,
+			`ALTER TABLE borrow_records
+                ADD COLUMN copy_id INT NULL AFTER book_id,
+                ADD CONSTRAINT fk_borrow_records_copy FOREIGN KEY (copy_id) REFERENCES book_copies(id) ON DELETE SET NULL`,
+		}, backfillBookCopies...),
+		Down: []string{
+			`ALTER TABLE borrow_records DROP FOREIGN KEY fk_borrow_records_copy, DROP COLUMN copy_id`,
+			`DROP TABLE IF EXISTS book_copies`,
+		},
+	},
+	{
+		Version: 9,
+		Name:    "create_api_tokens",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS api_tokens (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                user_id INT NOT NULL,
+                name VARCHAR(100) NOT NULL,
+                token_hash CHAR(64) NOT NULL UNIQUE,
+                created_at TIMESTAMP NOT NULL,
+                last_used_at TIMESTAMP NULL,
+                FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS api_tokens`,
+		},
+	},
+	{
+		Version: 10,
+		Name:    "add_book_cover_image",
+		Up: []string{
+			`ALTER TABLE books ADD COLUMN cover_image VARCHAR(64) NOT NULL DEFAULT '' AFTER cover_url`,
+		},
+		Down: []string{
+			`ALTER TABLE books DROP COLUMN cover_image`,
+		},
+	},
+	{
+		Version: 11,
+		Name:    "create_contributors",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS contributors (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                name VARCHAR(100) NOT NULL UNIQUE,
+                bio TEXT NOT NULL,
+                created_at TIMESTAMP NOT NULL
+            )`,
+			`CREATE TABLE IF NOT EXISTS book_contributors (
+                book_id INT NOT NULL,
+                contributor_id INT NOT NULL,
+                role VARCHAR(20) NOT NULL,
+                position INT NOT NULL,
+                PRIMARY KEY (book_id, role, contributor_id),
+                INDEX idx_book_contributors_contributor (contributor_id),
+                FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+                FOREIGN KEY (contributor_id) REFERENCES contributors(id) ON DELETE CASCADE
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS book_contributors`,
+			`DROP TABLE IF EXISTS contributors`,
+		},
+	},
+	{
+		Version: 12,
+		Name:    "create_classifications",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS classifications (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                parent_id INT NOT NULL DEFAULT 0,
+                code VARCHAR(32) NOT NULL UNIQUE,
+                name VARCHAR(100) NOT NULL,
+                INDEX idx_classifications_parent (parent_id)
+            )`,
+			`ALTER TABLE books ADD COLUMN classification_id INT NOT NULL DEFAULT 0 AFTER category,
+                ADD INDEX idx_books_classification (classification_id)`,
+		},
+		Down: []string{
+			`ALTER TABLE books DROP INDEX idx_books_classification, DROP COLUMN classification_id`,
+			`DROP TABLE IF EXISTS classifications`,
+		},
+	},
+	{
+		Version: 13,
+		Name:    "create_tags",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS tags (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                name VARCHAR(50) NOT NULL UNIQUE,
+                kind VARCHAR(20) NOT NULL,
+                created_at TIMESTAMP NOT NULL
+            )`,
+			`CREATE TABLE IF NOT EXISTS book_tags (
+                book_id INT NOT NULL,
+                tag_id INT NOT NULL,
+                PRIMARY KEY (book_id, tag_id),
+                INDEX idx_book_tags_tag (tag_id),
+                FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+                FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS book_tags`,
+			`DROP TABLE IF EXISTS tags`,
+		},
+	},
+	{
+		Version: 14,
+		Name:    "add_book_call_number",
+		Up: []string{
+			`ALTER TABLE books ADD COLUMN call_number VARCHAR(64) NOT NULL DEFAULT '' AFTER classification_id,
+                ADD COLUMN call_number_key VARCHAR(160) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL DEFAULT '' AFTER call_number,
+                ADD COLUMN shelf_location VARCHAR(100) NOT NULL DEFAULT '' AFTER call_number_key,
+                ADD INDEX idx_books_call_number (call_number_key, id)`,
+		},
+		Down: []string{
+			`ALTER TABLE books DROP INDEX idx_books_call_number, DROP COLUMN shelf_location,
+                DROP COLUMN call_number_key, DROP COLUMN call_number`,
+		},
+	},
+	{
+		Version: 15,
+		Name:    "create_series",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS series (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                name VARCHAR(100) NOT NULL UNIQUE,
+                description TEXT NOT NULL,
+                created_at TIMESTAMP NOT NULL
+            )`,
+			`CREATE TABLE IF NOT EXISTS series_volumes (
+                book_id INT PRIMARY KEY,
+                series_id INT NOT NULL,
+                volume INT NOT NULL,
+                UNIQUE KEY uk_series_volumes_volume (series_id, volume),
+                FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+                FOREIGN KEY (series_id) REFERENCES series(id) ON DELETE CASCADE
+            )`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS series_volumes`,
+			`DROP TABLE IF EXISTS series`,
+		},
+	},
+	{
+		Version: 16,
+		Name:    "create_reviews",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS reviews (
+                id INT AUTO_INCREMENT PRIMARY KEY,
+                book_id INT NOT NULL,
+                user_id INT NOT NULL,
+                rating TINYINT NOT NULL,
+                content TEXT NOT NULL,
+                status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL,
+                UNIQUE KEY uk_reviews_book_user (book_id, user_id),
+                INDEX idx_reviews_status (status, updated_at),
+                FOREIGN KEY (book_id) REFERENCES books(id) ON DELETE CASCADE,
+                FOREIGN KEY (user_id) REFERENCES users(id)
+            )`,
+			`ALTER TABLE books ADD COLUMN rating_average DECIMAL(3,2) NOT NULL DEFAULT 0 AFTER quantity,
+                ADD COLUMN rating_count INT NOT NULL DEFAULT 0 AFTER rating_average,
+                ADD INDEX idx_books_rating (rating_average, id)`,
+		},
+		Down: []string{
+			`ALTER TABLE books DROP INDEX idx_books_rating, DROP COLUMN rating_count, DROP COLUMN rating_average`,
+			`DROP TABLE IF EXISTS reviews`,
+		},
+	},
+	{
+		Version: 17,
+		Name:    "create_catalog_version",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS catalog_version (
+                id TINYINT PRIMARY KEY,
+                version BIGINT NOT NULL
+            )`,
+			`INSERT IGNORE INTO catalog_version (id, version) VALUES (1, 0)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS catalog_version`,
+		},
+	},
+}
+
+// backfillBookCopies 按图书数量为尚无副本的图书生成副本，并为未归还的借阅分配副本
+var backfillBookCopies = []string{
+	`INSERT INTO book_copies (book_id, barcode, shelf_location, ` + "`condition`" + `, status, created_at)
+        WITH RECURSIVE seq (n) AS (
+            SELECT 1
+            UNION ALL
+            SELECT n + 1 FROM seq WHERE n < (SELECT COALESCE(MAX(quantity), 0) FROM books)
+        )
+        SELECT b.id, CONCAT('B', LPAD(b.id, 6, '0'), '-', LPAD(seq.n, 3, '0')), '', 'good', 'available', NOW()
+        FROM books b
+        JOIN seq ON seq.n <= b.quantity
+        WHERE NOT EXISTS (SELECT 1 FROM book_copies c WHERE c.book_id = b.id)`,
+	`UPDATE borrow_records br
+        JOIN (
+            SELECT id, book_id, ROW_NUMBER() OVER (PARTITION BY book_id ORDER BY id) AS rn
+            FROM borrow_records
+            WHERE return_date IS NULL AND copy_id IS NULL
+        ) active ON active.id = br.id
+        JOIN book_copies c ON c.book_id = active.book_id
+            AND c.barcode = CONCAT('B', LPAD(active.book_id, 6, '0'), '-', LPAD(active.rn, 3, '0'))
+        SET br.copy_id = c.id`,
+	`UPDATE book_copies c
+        JOIN borrow_records br ON br.copy_id = c.id AND br.return_date IS NULL
+        SET c.status = 'on_loan'`,
+}
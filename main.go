@@ -5,6 +5,7 @@ import (
         "net/http"
         "os"
         "os/signal"
+        "strings"
         "syscall"
         "time"
 
@@ -12,6 +13,7 @@ import (
         "github.com/joho/godotenv"
         "librarysystem/config"
         "librarysystem/database"
+        "librarysystem/models"
         "librarysystem/routes"
         "librarysystem/utils"
 )
@@ -24,6 +26,13 @@ func main() {
 	
         // 设置日志格式
         log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+        // 数据库迁移子命令
+        if len(os.Args) > 1 && os.Args[1] == "migrate" {
+                runMigrateCommand(os.Args[2:])
+                return
+        }
+
         log.Println("启动图书管理系统...")
 
         // 设置模式（生产/开发）
@@ -33,11 +42,71 @@ func main() {
         log.Println("初始化数据库连接...")
         config.InitDatabase()
         
-        // 初始化数据库表和示例数据
-        log.Println("初始化数据库表...")
-        database.InitTables()
+        // 选择密码哈希算法（argon2id 或 bcrypt），须在填充示例用户之前设置
+        hasher, err := models.PasswordHasherByName(os.Getenv("PASSWORD_HASHER"))
+        if err != nil {
+                log.Fatalf("初始化密码哈希失败: %v", err)
+        }
+        models.SetPasswordHasher(hasher)
+
+        // 执行数据库迁移并填充示例数据
+        database.RunMigrations()
         database.SeedData()
 
+        // 使用MySQL数据仓库
+        models.SetRepositories(models.NewMySQLRepositories(config.GetDB()))
+
+        // 将已有图书的ISBN统一为13位形式
+        if count, err := models.NormalizeStoredISBNs(); err != nil {
+                log.Printf("规范化ISBN失败: %v", err)
+        } else if count > 0 {
+                log.Printf("已规范化 %d 本图书的ISBN", count)
+        }
+
+        // 按作者字段为已有图书建立作者关联
+        if count, err := models.BackfillContributors(); err != nil {
+                log.Printf("建立作者关联失败: %v", err)
+        } else if count > 0 {
+                log.Printf("已为 %d 本图书建立作者关联", count)
+        }
+        if count, err := models.SeedClassifications(); err != nil {
+                log.Printf("初始化分类表失败: %v", err)
+        } else if count > 0 {
+                log.Printf("已写入 %d 个中图法基本大类", count)
+        }
+
+        // 读取罚款限额（借期、续借次数和费率在流通规则中设置），未设置时使用默认值
+        if value := os.Getenv("FINE_BLOCK_THRESHOLD"); value != "" {
+                threshold, err := models.ParseAmount(value)
+                if err != nil {
+                        log.Fatalf("读取罚款限额 FINE_BLOCK_THRESHOLD 失败: %v", err)
+                }
+                models.SetFineConfig(models.FineConfig{BlockThreshold: threshold})
+        }
+
+        // 配置按ISBN自动填充使用的书目数据源（openlibrary、file，可用逗号分隔按顺序查询）
+        provider, err := models.NewMetadataProvider(models.MetadataConfig{
+                Providers:      strings.Split(os.Getenv("METADATA_PROVIDERS"), ","),
+                OpenLibraryURL: os.Getenv("OPENLIBRARY_URL"),
+                File:           os.Getenv("METADATA_FILE"),
+        })
+        if err != nil {
+                log.Fatalf("初始化书目数据源失败: %v", err)
+        }
+        models.SetMetadataProvider(provider)
+
+        // 上传的封面保存在本地目录（默认 uploads/covers）
+        if dir := os.Getenv("COVER_DIR"); dir != "" {
+                models.SetCoverStorage(models.NewDiskCoverStorage(dir))
+        }
+
+        // 初始化会话存储（默认使用MySQL，设置 SESSION_STORE=memory 时使用进程内存）
+        if os.Getenv("SESSION_STORE") == "memory" {
+                utils.SetSessionStore(utils.NewMemorySessionStore())
+        } else {
+                utils.SetSessionStore(utils.NewMySQLSessionStore(config.GetDB()))
+        }
+
         // 创建路由
         log.Println("设置路由...")
         router := routes.SetupRouter()
@@ -45,6 +114,9 @@ func main() {
         // 启动会话清理定时任务
         go sessionCleanupTask()
 
+        // 启动过期预约处理定时任务
+        go holdExpiryTask()
+
         // 启动服务器
         port := os.Getenv("PORT")
         if port == "" {
@@ -82,11 +154,21 @@ func sessionCleanupTask() {
 
         for range ticker.C {
                 log.Println("清理过期会话...")
-                sessionStore := &utils.MemorySessionStore{
-                        Sessions: make(map[string]*utils.SessionItem),
-                }
-                if err := sessionStore.ClearExpired(); err != nil {
-                        log.Printf("清理过期会话时出错: %v\n", err)
+                utils.CleanupExpiredSessions()
+        }
+}
+
+// 过期预约处理定时任务
+func holdExpiryTask() {
+        ticker := time.NewTicker(1 * time.Hour)
+        defer ticker.Stop()
+
+        for range ticker.C {
+                count, err := models.ExpireHolds(time.Now())
+                if err != nil {
+                        log.Printf("处理过期预约时出错: %v\n", err)
+                } else if count > 0 {
+                        log.Printf("已处理过期预约 %d 条\n", count)
                 }
         }
-}
\ No newline at end of file
+}
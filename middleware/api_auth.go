@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"librarysystem/models"
+)
+
+// apiUserKey 上下文中保存API认证用户的键
+const apiUserKey = "api_user"
+
+// APIError 向客户端返回统一格式的错误响应并中止请求
+func APIError(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"error": gin.H{
+			"code":    code,
+			"message": message,
+		},
+	})
+}
+
+// APIUser 获取当前请求的认证用户，未认证时返回nil
+func APIUser(c *gin.Context) *models.User {
+	if value, exists := c.Get(apiUserKey); exists {
+		if user, ok := value.(*models.User); ok {
+			return user
+		}
+	}
+	return nil
+}
+
+// apiTokenFromRequest 从 Authorization: Bearer 或 X-API-Key 请求头读取令牌
+func apiTokenFromRequest(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(c.GetHeader("X-API-Key"))
+}
+
+// APIAuth 解析请求中的API令牌，携带无效令牌时拒绝请求
+func APIAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := apiTokenFromRequest(c)
+		if token == "" {
+			// 匿名请求交由后续中间件判断
+			c.Next()
+			return
+		}
+
+		user, err := models.AuthenticateAPIToken(token)
+		if err != nil {
+			APIError(c, http.StatusUnauthorized, "invalid_token", "API令牌无效")
+			return
+		}
+
+		c.Set(apiUserKey, user)
+		c.Next()
+	}
+}
+
+// APIRequireAuth 验证API请求是否已认证
+func APIRequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if APIUser(c) == nil {
+			APIError(c, http.StatusUnauthorized, "unauthorized", "请提供API令牌")
+			return
+		}
+		c.Next()
+	}
+}
+
+// APIRequireAdmin 验证API请求用户是否为管理员
+func APIRequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := APIUser(c)
+		if user == nil {
+			APIError(c, http.StatusUnauthorized, "unauthorized", "请提供API令牌")
+			return
+		}
+		if user.Role != models.RoleAdmin {
+			APIError(c, http.StatusForbidden, "forbidden", "需要管理员权限")
+			return
+		}
+		c.Next()
+	}
+}
+
+// APIRequireLibrarian 验证API请求用户是否为图书管理员
+func APIRequireLibrarian() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := APIUser(c)
+		if user == nil {
+			APIError(c, http.StatusUnauthorized, "unauthorized", "请提供API令牌")
+			return
+		}
+		if user.Role != models.RoleLibrarian && user.Role != models.RoleAdmin {
+			APIError(c, http.StatusForbidden, "forbidden", "需要图书管理员权限")
+			return
+		}
+		c.Next()
+	}
+}
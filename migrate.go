@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"librarysystem/config"
+	"librarysystem/database"
+)
+
+// migrateUsage migrate 子命令用法
+const migrateUsage = `用法: librarysystem migrate [-dry-run] <命令> [参数]
+
+命令:
+  up [版本]     升级到指定版本（默认最新）
+  down [步数]   回滚最近的若干个迁移（默认1）
+  status        查看迁移状态
+`
+
+// runMigrateCommand 处理 migrate 子命令
+func runMigrateCommand(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "只打印将要执行的SQL，不修改数据库")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	config.InitDatabase()
+	migrator := database.NewMigrator(config.GetDB())
+	migrator.DryRun = *dryRun
+
+	var err error
+	switch fs.Arg(0) {
+	case "up":
+		err = migrator.Up(intArg(fs, 1, 0))
+	case "down":
+		err = migrator.Down(intArg(fs, 1, 1))
+	case "status":
+		var statuses []database.MigrationStatus
+		statuses, err = migrator.Status()
+		for _, status := range statuses {
+			state := "未应用"
+			if status.Applied {
+				state = "已应用 " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-30s  %s\n", status.Version, status.Name, state)
+		}
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("迁移失败: %v", err)
+	}
+}
+
+// intArg 读取整数位置参数，缺省时返回默认值
+func intArg(fs *flag.FlagSet, index, defaultValue int) int {
+	if fs.NArg() <= index {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(fs.Arg(index))
+	if err != nil {
+		log.Fatalf("无效的参数 %q: %v", fs.Arg(index), err)
+	}
+	return value
+}
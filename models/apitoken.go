@@ -0,0 +1,103 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// apiTokenPrefix API令牌前缀，便于在日志和配置中识别
+const apiTokenPrefix = "lib_"
+
+// APIToken API访问令牌，只保存令牌的SHA-256摘要
+type APIToken struct {
+	ID         int       `json:"id"`
+	UserID     int       `json:"user_id"`
+	Name       string    `json:"name"`
+	TokenHash  string    `json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+// hashAPIToken 计算令牌摘要
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken 为用户签发API令牌，明文令牌只在创建时返回一次
+func CreateAPIToken(userID int, name string) (string, *APIToken, error) {
+	if _, err := GetUserByID(userID); err != nil {
+		return "", nil, errors.New("用户不存在")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "default"
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	plain := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	token := &APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashAPIToken(plain),
+		CreatedAt: time.Now(),
+	}
+	if err := GetRepositories().APITokens.Create(token); err != nil {
+		return "", nil, err
+	}
+	return plain, token, nil
+}
+
+// AuthenticateAPIToken 校验明文令牌并返回令牌所属用户
+func AuthenticateAPIToken(plain string) (*User, error) {
+	if !strings.HasPrefix(plain, apiTokenPrefix) {
+		return nil, ErrAPITokenNotFound
+	}
+
+	token, err := GetRepositories().APITokens.GetByHash(hashAPIToken(plain))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := GetUserByID(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := GetRepositories().APITokens.Touch(token.ID, time.Now()); err != nil {
+		log.Printf("更新令牌使用时间失败: %v", err)
+	}
+	return user, nil
+}
+
+// RevokeAPIToken 吊销用户的API令牌
+func RevokeAPIToken(id, userID int) error {
+	token, err := GetRepositories().APITokens.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if token.UserID != userID {
+		return ErrAPITokenNotFound
+	}
+	return GetRepositories().APITokens.Delete(id)
+}
+
+// GetAPITokensByUserID 获取用户的所有API令牌
+func GetAPITokensByUserID(userID int) []*APIToken {
+	tokens, err := GetRepositories().APITokens.GetByUserID(userID)
+	if err != nil {
+		log.Printf("获取API令牌失败: %v", err)
+	}
+	return tokens
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryAPITokenRepository 基于内存切片的API令牌仓库实现
+type MemoryAPITokenRepository struct {
+	mu     sync.RWMutex
+	tokens []*APIToken
+	nextID int
+}
+
+// NewMemoryAPITokenRepository 创建内存API令牌仓库
+func NewMemoryAPITokenRepository() *MemoryAPITokenRepository {
+	return &MemoryAPITokenRepository{nextID: 1}
+}
+
+// Create 添加令牌并分配ID
+func (r *MemoryAPITokenRepository) Create(token *APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token.ID = r.nextID
+	r.tokens = append(r.tokens, token)
+	r.nextID++
+	return nil
+}
+
+// Delete 删除令牌
+func (r *MemoryAPITokenRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, token := range r.tokens {
+		if token.ID == id {
+			r.tokens = append(r.tokens[:i], r.tokens[i+1:]...)
+			return nil
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+// Touch 记录令牌最近使用时间
+func (r *MemoryAPITokenRepository) Touch(id int, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id {
+			token.LastUsedAt = usedAt
+			return nil
+		}
+	}
+	return ErrAPITokenNotFound
+}
+
+// GetByID 根据ID获取令牌
+func (r *MemoryAPITokenRepository) GetByID(id int) (*APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.ID == id {
+			return token, nil
+		}
+	}
+	return nil, ErrAPITokenNotFound
+}
+
+// GetByHash 根据令牌摘要获取令牌
+func (r *MemoryAPITokenRepository) GetByHash(hash string) (*APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return token, nil
+		}
+	}
+	return nil, ErrAPITokenNotFound
+}
+
+// GetByUserID 获取用户的所有令牌
+func (r *MemoryAPITokenRepository) GetByUserID(userID int) ([]*APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*APIToken
+	for _, token := range r.tokens {
+		if token.UserID == userID {
+			result = append(result, token)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// apiTokenColumns API令牌表查询列
+const apiTokenColumns = "id, user_id, name, token_hash, created_at, last_used_at"
+
+// MySQLAPITokenRepository 基于MySQL的API令牌仓库实现
+type MySQLAPITokenRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAPITokenRepository 创建MySQL API令牌仓库
+func NewMySQLAPITokenRepository(db *sql.DB) *MySQLAPITokenRepository {
+	return &MySQLAPITokenRepository{db: db}
+}
+
+// Create 插入令牌并回填ID
+func (r *MySQLAPITokenRepository) Create(token *APIToken) error {
+	result, err := r.db.Exec(
+		"INSERT INTO api_tokens (user_id, name, token_hash, created_at) VALUES (?, ?, ?, ?)",
+		token.UserID, token.Name, token.TokenHash, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("创建API令牌失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取API令牌ID失败: %w", err)
+	}
+	token.ID = int(id)
+	return nil
+}
+
+// Delete 删除令牌
+func (r *MySQLAPITokenRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM api_tokens WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除API令牌失败: %w", err)
+	}
+	return checkAffected(result, ErrAPITokenNotFound)
+}
+
+// Touch 记录令牌最近使用时间
+func (r *MySQLAPITokenRepository) Touch(id int, usedAt time.Time) error {
+	if _, err := r.db.Exec("UPDATE api_tokens SET last_used_at = ? WHERE id = ?", usedAt, id); err != nil {
+		return fmt.Errorf("更新API令牌失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取令牌
+func (r *MySQLAPITokenRepository) GetByID(id int) (*APIToken, error) {
+	return scanAPIToken(r.db.QueryRow("SELECT "+apiTokenColumns+" FROM api_tokens WHERE id = ?", id))
+}
+
+// GetByHash 根据令牌摘要获取令牌
+func (r *MySQLAPITokenRepository) GetByHash(hash string) (*APIToken, error) {
+	return scanAPIToken(r.db.QueryRow("SELECT "+apiTokenColumns+" FROM api_tokens WHERE token_hash = ?", hash))
+}
+
+// GetByUserID 获取用户的所有令牌
+func (r *MySQLAPITokenRepository) GetByUserID(userID int) ([]*APIToken, error) {
+	rows, err := r.db.Query("SELECT "+apiTokenColumns+" FROM api_tokens WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("查询API令牌失败: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// scanAPIToken 扫描一行令牌数据
+func scanAPIToken(row rowScanner) (*APIToken, error) {
+	token := &APIToken{}
+	var lastUsedAt sql.NullTime
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, &token.CreatedAt, &lastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPITokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	token.LastUsedAt = lastUsedAt.Time
+	return token, nil
+}
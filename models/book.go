@@ -2,193 +2,248 @@ package models
 
 import (
 	"errors"
-	"strings"
+	"fmt"
+	"log"
 	"sync"
+	"time"
 )
 
 // Book 图书模型
 type Book struct {
-	ID            int    `json:"id"`
-	Title         string `json:"title"`
-	Author        string `json:"author"`
-	ISBN          string `json:"isbn"`
-	PublishedYear int    `json:"published_year"`
-	Category      string `json:"category"`
-	Description   string `json:"description"`
-	CoverURL      string `json:"cover_url"`
-	Quantity      int    `json:"quantity"`
+	ID            int     `json:"id"`
+	Title         string  `json:"title"`
+	Author        string  `json:"author"`
+	ISBN          string  `json:"isbn"`
+	PublishedYear int     `json:"published_year"`
+	Category      string  `json:"category"`
+	ClassID       int     `json:"classification_id"` // 所属分类节点，0 表示尚未归入分类表
+	CallNumber    string  `json:"call_number"`       // 索书号，为空表示尚未编目排架
+	CallNumberKey string  `json:"-"`                 // 索书号排序键，见 callNumberSortKey
+	ShelfLocation string  `json:"shelf_location"`    // 默认架位，副本未登记架位时使用
+	Description   string  `json:"description"`
+	CoverURL      string  `json:"cover_url"`
+	CoverImage    string  `json:"cover_image"` // 上传封面的文件前缀，为空时使用 CoverURL
+	Quantity      int     `json:"quantity"`
+	RatingAverage float64 `json:"rating_average"` // 已通过审核的评价的平均评分，没有评价时为0
+	RatingCount   int     `json:"rating_count"`   // 已通过审核的评价数
 }
 
-// Books 全局图书列表
-var (
-	Books       []*Book
-	NextBookID  = 1
-	bookMutex   sync.Mutex
-	categories  map[string]bool
-)
+// bookMutex 保证图书校验与写入的原子性
+var bookMutex sync.Mutex
+
+// validateBook 校验图书字段，已有图书的副本可能全部剔除或遗失，existing 为 true 时数量允许为0
+func validateBook(title, author, isbn string, publishedYear int, category, description, coverURL string, quantity int, existing bool) error {
+	if title == "" || author == "" || isbn == "" || category == "" || description == "" {
+		return errors.New("除封面外的字段都不能为空")
+	}
+
+	if publishedYear < 1000 || publishedYear > 2100 {
+		return errors.New("出版年份必须在1000-2100之间")
+	}
+
+	if quantity < 0 {
+		return errors.New("数量不能为负数")
+	}
+	if quantity == 0 && !existing {
+		return errors.New("数量必须大于0")
+	}
+
+	return nil
+}
 
 // CreateBook 创建新图书
 func CreateBook(title, author, isbn string, publishedYear int, category, description, coverURL string, quantity int) (*Book, error) {
 	bookMutex.Lock()
 	defer bookMutex.Unlock()
-	
+
 	// 验证参数
-	if title == "" || author == "" || isbn == "" || category == "" || description == "" || coverURL == "" {
-		return nil, errors.New("所有字段都不能为空")
+	if err := validateBook(title, author, isbn, publishedYear, category, description, coverURL, quantity, false); err != nil {
+		return nil, err
 	}
-	
-	if publishedYear < 1000 || publishedYear > 2100 {
-		return nil, errors.New("出版年份必须在1000-2100之间")
+	if err := validateAuthorNames(author); err != nil {
+		return nil, err
 	}
-	
-	if quantity < 1 {
-		return nil, errors.New("数量必须大于0")
+	isbn, err := NormalizeISBN(isbn)
+	if err != nil {
+		return nil, err
 	}
-	
+	// 分类表非空时分类须取自分类表
+	class, err := resolveBookClassification(category)
+	if err != nil {
+		return nil, err
+	}
+	classID := 0
+	if class != nil {
+		category, classID = class.Name, class.ID
+	}
+
 	// 检查ISBN是否已存在
-	for _, book := range Books {
-		if book.ISBN == isbn {
-			return nil, errors.New("ISBN已存在")
-		}
+	if existing, _ := GetRepositories().Books.GetByISBN(isbn); existing != nil {
+		return nil, fmt.Errorf("%w：《%s》", ErrDuplicateISBN, existing.Title)
 	}
-	
+
 	// 创建图书
 	book := &Book{
-		ID:            NextBookID,
 		Title:         title,
 		Author:        author,
 		ISBN:          isbn,
 		PublishedYear: publishedYear,
 		Category:      category,
+		ClassID:       classID,
 		Description:   description,
 		CoverURL:      coverURL,
 		Quantity:      quantity,
 	}
-	
-	// 添加到分类映射
-	if categories == nil {
-		categories = make(map[string]bool)
-	}
-	categories[category] = true
-	
-	// 添加到列表并递增ID
-	Books = append(Books, book)
-	NextBookID++
-	
+
+	if err := GetRepositories().Books.Create(book); err != nil {
+		return nil, err
+	}
+
+	// 按数量登记副本并建立作者关联，失败时删除已写入的图书，以便修正后重新添加
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+	err = reconcileCopies(book, quantity)
+	if err == nil {
+		err = syncAuthorCreditsLocked(book)
+	}
+	if err != nil {
+		if rerr := removeBookLocked(book); rerr != nil {
+			log.Printf("删除未添加成功的图书 %d 失败: %v", book.ID, rerr)
+		}
+		return nil, err
+	}
 	return book, nil
 }
 
 // GetBookByID 根据ID获取图书
 func GetBookByID(id int) (*Book, error) {
-	for _, book := range Books {
-		if book.ID == id {
-			return book, nil
-		}
-	}
-	return nil, errors.New("图书不存在")
+	return GetRepositories().Books.GetByID(id)
 }
 
 // GetAllBooks 获取所有图书
 func GetAllBooks() []*Book {
-	return Books
+	books, err := GetRepositories().Books.GetAll()
+	if err != nil {
+		log.Printf("获取图书列表失败: %v", err)
+	}
+	return books
 }
 
 // GetAllCategories 获取所有分类
 func GetAllCategories() []string {
-	if categories == nil {
+	categories, err := GetRepositories().Books.GetCategories()
+	if err != nil {
+		log.Printf("获取图书分类失败: %v", err)
 		return []string{}
 	}
-	
-	var result []string
-	for category := range categories {
-		result = append(result, category)
-	}
-	return result
+	return categories
 }
 
 // GetBooksByCategory 根据分类获取图书
 func GetBooksByCategory(category string) []*Book {
-	var result []*Book
-	for _, book := range Books {
-		if book.Category == category {
-			result = append(result, book)
-		}
+	books, err := GetRepositories().Books.GetByCategory(category)
+	if err != nil {
+		log.Printf("按分类获取图书失败: %v", err)
 	}
-	return result
+	return books
 }
 
-// SearchBooks 搜索图书
+// SearchBooks 全文检索图书，结果按相关度排序
 func SearchBooks(query string) []*Book {
-	query = strings.ToLower(query)
-	var result []*Book
-	
-	for _, book := range Books {
-		// 匹配标题、作者、ISBN或描述
-		if strings.Contains(strings.ToLower(book.Title), query) ||
-		   strings.Contains(strings.ToLower(book.Author), query) ||
-		   strings.Contains(strings.ToLower(book.ISBN), query) ||
-		   strings.Contains(strings.ToLower(book.Description), query) {
-			result = append(result, book)
+	ids, err := SearchBookIDs(query)
+	if err != nil {
+		log.Printf("搜索图书失败: %v", err)
+	}
+
+	books := make([]*Book, 0, len(ids))
+	for _, id := range ids {
+		if book, err := GetBookByID(id); err == nil {
+			books = append(books, book)
 		}
 	}
-	
-	return result
+	return books
 }
 
 // UpdateBook 更新图书
 func UpdateBook(id int, title, author, isbn string, publishedYear int, category, description, coverURL string, quantity int) (*Book, error) {
 	bookMutex.Lock()
 	defer bookMutex.Unlock()
-	
+
 	// 验证参数
-	if title == "" || author == "" || isbn == "" || category == "" || description == "" || coverURL == "" {
-		return nil, errors.New("所有字段都不能为空")
+	if err := validateBook(title, author, isbn, publishedYear, category, description, coverURL, quantity, true); err != nil {
+		return nil, err
 	}
-	
-	if publishedYear < 1000 || publishedYear > 2100 {
-		return nil, errors.New("出版年份必须在1000-2100之间")
+	if err := validateAuthorNames(author); err != nil {
+		return nil, err
 	}
-	
-	if quantity < 1 {
-		return nil, errors.New("数量必须大于0")
+	isbn, err := NormalizeISBN(isbn)
+	if err != nil {
+		return nil, err
 	}
-	
-	// 查找图书
-	var book *Book
-	for _, b := range Books {
-		if b.ID == id {
-			book = b
-			break
-		}
+	// 分类表非空时分类须取自分类表
+	class, err := resolveBookClassification(category)
+	if err != nil {
+		return nil, err
 	}
-	
-	if book == nil {
-		return nil, errors.New("图书不存在")
+	classID := 0
+	if class != nil {
+		category, classID = class.Name, class.ID
 	}
-	
+
+	// 查找图书
+	book, err := GetRepositories().Books.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
 	// 检查ISBN是否已被其他图书使用
-	for _, b := range Books {
-		if b.ID != id && b.ISBN == isbn {
-			return nil, errors.New("ISBN已被其他图书使用")
-		}
+	if existing, _ := GetRepositories().Books.GetByISBN(isbn); existing != nil && existing.ID != id {
+		return nil, fmt.Errorf("%w：《%s》", ErrDuplicateISBN, existing.Title)
 	}
-	
+
 	// 更新图书信息
+	old := *book
+	authorChanged := book.Author != author
 	book.Title = title
 	book.Author = author
 	book.ISBN = isbn
 	book.PublishedYear = publishedYear
 	book.Category = category
+	book.ClassID = classID
 	book.Description = description
 	book.CoverURL = coverURL
 	book.Quantity = quantity
-	
-	// 添加到分类映射
-	if categories == nil {
-		categories = make(map[string]bool)
+
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+	if err := GetRepositories().Books.Update(book); err != nil {
+		*book = old
+		return nil, err
+	}
+
+	// 图书保存后按新数量增加或剔除副本，失败时恢复原图书信息，数量以实际副本为准
+	if err := reconcileCopies(book, quantity); err != nil {
+		*book = old
+		if rerr := GetRepositories().Books.Update(book); rerr != nil {
+			log.Printf("恢复图书 %d 失败: %v", book.ID, rerr)
+		} else if rerr := syncBookQuantity(book); rerr != nil {
+			log.Printf("同步图书 %d 的数量失败: %v", book.ID, rerr)
+		}
+		return nil, err
+	}
+
+	// 库存增加后通知排队中的预约
+	holdMutex.Lock()
+	promoteHolds(book.ID, time.Now())
+	holdMutex.Unlock()
+
+	if authorChanged {
+		if err := syncAuthorCreditsLocked(book); err != nil {
+			return nil, err
+		}
+	} else {
+		bookIndex.update(book)
 	}
-	categories[category] = true
-	
 	return book, nil
 }
 
@@ -196,36 +251,61 @@ func UpdateBook(id int, title, author, isbn string, publishedYear int, category,
 func DeleteBook(id int) error {
 	bookMutex.Lock()
 	defer bookMutex.Unlock()
-	
-	// 检查有无借阅记录
-	for _, record := range BorrowRecords {
-		if record.BookID == id && record.ReturnDate.IsZero() {
-			return errors.New("该图书有未归还的借阅记录，无法删除")
-		}
+
+	// 借阅记录和预约引用图书且不随图书删除，有过借阅或预约的图书只能剔除副本，不能删除
+	records, err := GetRepositories().Borrows.GetByBookID(id)
+	if err != nil {
+		return err
+	}
+	if len(records) > 0 {
+		return errors.New("该图书有借阅记录，无法删除，可将副本登记为剔除")
 	}
-	
-	// 查找图书索引
-	index := -1
-	for i, book := range Books {
-		if book.ID == id {
-			index = i
-			break
+	holds, err := GetRepositories().Holds.GetByBookID(id)
+	if err != nil {
+		return err
+	}
+	if len(holds) > 0 {
+		return errors.New("该图书有预约记录，无法删除，可将副本登记为剔除")
+	}
+
+	book, err := GetRepositories().Books.GetByID(id)
+	if err != nil {
+		return err
+	}
+	return removeBookLocked(book)
+}
+
+// removeBookLocked 删除图书及其副本、评价和关联，调用方需持有 bookMutex
+func removeBookLocked(book *Book) error {
+	id := book.ID
+
+	// 删除前先记下责任者、标签和丛书，删除后据此清理不再使用的条目
+	var cleanups []func()
+	for _, prepare := range []func(int) (func(), error){deleteBookCreditsLocked, deleteBookTagsLocked, deleteBookSeriesLocked} {
+		cleanup, err := prepare(id)
+		if err != nil {
+			return err
 		}
+		cleanups = append(cleanups, cleanup)
+	}
+
+	// 先删除图书，失败时关联数据保持不变；MySQL 中副本、评价等关联随外键级联删除，
+	// 以下删除只对内存仓库有实际作用
+	if err := GetRepositories().Books.Delete(id); err != nil {
+		return err
 	}
-	
-	if index == -1 {
-		return errors.New("图书不存在")
+	if err := GetRepositories().Copies.DeleteByBookID(id); err != nil {
+		log.Printf("删除图书 %d 的副本失败: %v", id, err)
 	}
-	
-	// 删除图书
-	Books = append(Books[:index], Books[index+1:]...)
-	
-	// 重建分类映射
-	categories = make(map[string]bool)
-	for _, book := range Books {
-		categories[book.Category] = true
+	if err := deleteBookReviewsLocked(id); err != nil {
+		log.Printf("删除图书 %d 的评价失败: %v", id, err)
 	}
-	
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+
+	bookIndex.remove(id)
+	deleteCoverFiles(book.CoverImage)
 	return nil
 }
 
@@ -236,109 +316,12 @@ func (b *Book) IsAvailable() bool {
 
 // GetAvailableQuantity 获取可用库存数量
 func (b *Book) GetAvailableQuantity() int {
-	// 计算当前借出的数量
-	borrowedCount := 0
-	for _, record := range BorrowRecords {
-		if record.BookID == b.ID && record.ReturnDate.IsZero() {
-			borrowedCount++
-		}
+	// 统计在架副本数量
+	shelved, err := GetRepositories().Copies.CountByStatus(b.ID, CopyAvailable)
+	if err != nil {
+		log.Printf("统计图书在架副本失败: %v", err)
 	}
-	
-	// 返回可用数量
-	return b.Quantity - borrowedCount
-}
 
-// InitSampleBooks 初始化示例图书数据
-func InitSampleBooks() {
-	// 清空图书列表
-	bookMutex.Lock()
-	defer bookMutex.Unlock()
-	
-	Books = nil
-	NextBookID = 1
-	categories = make(map[string]bool)
-	
-	// 创建示例图书
-	books := []struct {
-		Title         string
-		Author        string
-		ISBN          string
-		PublishedYear int
-		Category      string
-		Description   string
-		CoverURL      string
-		Quantity      int
-	}{
-		{
-			Title:         "Python编程：从入门到实践",
-			Author:        "埃里克·马瑟斯",
-			ISBN:          "9787115428028",
-			PublishedYear: 2016,
-			Category:      "编程",
-			Description:   "本书是一本针对所有层次的Python读者而作的Python入门书。全书分两部分：第一部分介绍用Python编程所必须了解的基本概念，第二部分将理论付诸实践，讲解如何开发三个项目。",
-			CoverURL:      "https://img3.doubanio.com/view/subject/l/public/s29424065.jpg",
-			Quantity:      5,
-		},
-		{
-			Title:         "Go语言实战",
-			Author:        "威廉·肯尼迪",
-			ISBN:          "9787115445353",
-			PublishedYear: 2017,
-			Category:      "编程",
-			Description:   "本书首先介绍Go语言的独特之处，然后讲解如何编写地道的Go代码并使用其特有的特性和工具包编写代码。后续章节会介绍测试、Web编程以及与其他主流语言的集成。",
-			CoverURL:      "https://img9.doubanio.com/view/subject/l/public/s29446435.jpg",
-			Quantity:      3,
-		},
-		{
-			Title:         "明朝那些事儿",
-			Author:        "当年明月",
-			ISBN:          "9787807023630",
-			PublishedYear: 2009,
-			Category:      "历史",
-			Description:   "《明朝那些事儿》讲述从1344年到1644年，明朝三百年间的历史。以史料为基础，以年代和具体人物为主线，运用小说的手法，对明朝十七帝和其他王公权贵和小人物的命运进行全景展示。",
-			CoverURL:      "https://img1.doubanio.com/view/subject/l/public/s27131114.jpg",
-			Quantity:      4,
-		},
-		{
-			Title:         "三体",
-			Author:        "刘慈欣",
-			ISBN:          "9787536692930",
-			PublishedYear: 2008,
-			Category:      "科幻",
-			Description:   "文化大革命如火如荼进行的同时，军方探寻外星文明的绝秘计划'红岸工程'取得了突破性进展。但在按下发射键的那一刻，历经劫难的叶文洁没有意识到，她彻底改变了人类的命运。",
-			CoverURL:      "https://img2.doubanio.com/view/subject/l/public/s2768378.jpg",
-			Quantity:      2,
-		},
-		{
-			Title:         "围城",
-			Author:        "钱钟书",
-			ISBN:          "9787020090006",
-			PublishedYear: 1991,
-			Category:      "文学",
-			Description:   "《围城》是钱钟书所著的长篇小说，自问世以来，就以它的犀利的语言、巧妙的结构和象征性的意义在中国文学史上占据重要地位。",
-			CoverURL:      "https://img2.doubanio.com/view/subject/l/public/s1070222.jpg",
-			Quantity:      3,
-		},
-	}
-	
-	for _, bookData := range books {
-		book := &Book{
-			ID:            NextBookID,
-			Title:         bookData.Title,
-			Author:        bookData.Author,
-			ISBN:          bookData.ISBN,
-			PublishedYear: bookData.PublishedYear,
-			Category:      bookData.Category,
-			Description:   bookData.Description,
-			CoverURL:      bookData.CoverURL,
-			Quantity:      bookData.Quantity,
-		}
-		
-		// 添加到列表并递增ID
-		Books = append(Books, book)
-		NextBookID++
-		
-		// 添加到分类映射
-		categories[bookData.Category] = true
-	}
-}
\ No newline at end of file
+	// 返回可用数量（扣除为待取预约保留的库存）
+	return shelved - CountReadyHoldsByBookID(b.ID)
+}
@@ -0,0 +1,348 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFormat 图书导出格式
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"     // CSV，列与批量导入一致
+	ExportJSONL   ExportFormat = "jsonl"   // 每行一个JSON对象
+	ExportMARCXML ExportFormat = "marcxml" // MARC21 XML
+	ExportBibTeX  ExportFormat = "bibtex"  // BibTeX
+)
+
+// exportFormatInfo 各导出格式的MIME类型和文件扩展名
+var exportFormatInfo = map[ExportFormat][2]string{
+	ExportCSV:     {"text/csv; charset=utf-8", "csv"},
+	ExportJSONL:   {"application/x-ndjson; charset=utf-8", "jsonl"},
+	ExportMARCXML: {"application/marcxml+xml; charset=utf-8", "xml"},
+	ExportBibTeX:  {"application/x-bibtex; charset=utf-8", "bib"},
+}
+
+// ParseExportFormat 解析导出格式，空值为CSV
+func ParseExportFormat(s string) (ExportFormat, error) {
+	format := ExportFormat(strings.ToLower(s))
+	if format == "" {
+		format = ExportCSV
+	}
+	if _, ok := exportFormatInfo[format]; !ok {
+		return "", errors.New("不支持的导出格式")
+	}
+	return format, nil
+}
+
+// ContentType 导出文件的MIME类型
+func (f ExportFormat) ContentType() string {
+	return exportFormatInfo[f][0]
+}
+
+// Extension 导出文件的扩展名
+func (f ExportFormat) Extension() string {
+	return exportFormatInfo[f][1]
+}
+
+// bookEncoder 按格式逐本写出图书
+type bookEncoder interface {
+	begin() error
+	encode(book *Book) error
+	flush() error
+	end() error
+}
+
+// flusher 支持分批刷新的输出，例如HTTP响应
+type flusher interface {
+	Flush()
+}
+
+// ValidateBookQuery 校验查询条件，用于在开始输出前报告错误
+func ValidateBookQuery(query BookQuery) error {
+	return query.normalize()
+}
+
+// ExportBooks 将满足条件的图书逐页查询并流式写出，返回写出的图书数量
+func ExportBooks(w io.Writer, format ExportFormat, query BookQuery) (int, error) {
+	var enc bookEncoder
+	switch format {
+	case ExportCSV:
+		enc = &csvBookEncoder{w: csv.NewWriter(w), out: w}
+	case ExportJSONL:
+		enc = &jsonlBookEncoder{enc: json.NewEncoder(w)}
+	case ExportMARCXML:
+		enc = &marcXMLBookEncoder{w: w, enc: xml.NewEncoder(w)}
+	case ExportBibTeX:
+		enc = &bibtexBookEncoder{w: w}
+	default:
+		return 0, errors.New("不支持的导出格式")
+	}
+
+	// 检索条件只解析一次，之后按批读取，不在内存中保留整个目录
+	if err := query.normalize(); err != nil {
+		return 0, err
+	}
+	if err := query.resolveIDs(); err != nil {
+		return 0, err
+	}
+	next := exportPager(query)
+
+	if err := enc.begin(); err != nil {
+		return 0, err
+	}
+	count := 0
+	for {
+		books, err := next()
+		if err != nil {
+			return count, err
+		}
+		if len(books) == 0 {
+			break
+		}
+		for _, book := range books {
+			if err := enc.encode(book); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if err := enc.flush(); err != nil {
+			return count, err
+		}
+		if f, ok := w.(flusher); ok {
+			f.Flush()
+		}
+	}
+	return count, enc.end()
+}
+
+// exportPager 返回按批读取图书的函数，读完时返回空切片；query 须已解析检索条件。
+// 按相关度排序时直接按检索结果的ID顺序分批读取，其余排序方式按游标翻页
+func exportPager(query BookQuery) func() ([]*Book, error) {
+	if query.Sort == SortRelevance {
+		ranked := query.IDs
+		if query.Desc {
+			ranked = make([]int, len(query.IDs))
+			for i, id := range query.IDs {
+				ranked[len(ranked)-1-i] = id
+			}
+		}
+		return func() ([]*Book, error) {
+			for len(ranked) > 0 {
+				n := MaxPageSize
+				if n > len(ranked) {
+					n = len(ranked)
+				}
+				batch := query
+				batch.IDs, ranked = ranked[:n], ranked[n:]
+				matched, _, err := GetRepositories().Books.Find(batch, nil, 0, 0)
+				if err != nil {
+					return nil, err
+				}
+				byID := make(map[int]*Book, len(matched))
+				for _, book := range matched {
+					byID[book.ID] = book
+				}
+				books := make([]*Book, 0, len(matched))
+				for _, id := range batch.IDs {
+					if book, ok := byID[id]; ok {
+						books = append(books, book)
+					}
+				}
+				// 本批的图书都被其他条件筛掉时继续读下一批
+				if len(books) > 0 {
+					return books, nil
+				}
+			}
+			return nil, nil
+		}
+	}
+
+	var after *BookCursor
+	done := false
+	return func() ([]*Book, error) {
+		if done {
+			return nil, nil
+		}
+		books, _, err := GetRepositories().Books.Find(query, after, MaxPageSize, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(books) < MaxPageSize {
+			done = true
+		}
+		if len(books) > 0 {
+			last := books[len(books)-1]
+			available := 0
+			if query.Sort == SortAvailability {
+				available = last.GetAvailableQuantity()
+			}
+			after = cursorFor(last, available, query.Sort, query.Desc)
+		}
+		return books, nil
+	}
+}
+
+// csvBookEncoder CSV导出，带UTF-8 BOM以便Excel正确识别中文
+type csvBookEncoder struct {
+	w   *csv.Writer
+	out io.Writer
+}
+
+func (e *csvBookEncoder) begin() error {
+	if _, err := io.WriteString(e.out, "\ufeff"); err != nil {
+		return err
+	}
+	return e.w.Write([]string{"title", "author", "isbn", "published_year", "category", "description", "cover_url", "quantity"})
+}
+
+func (e *csvBookEncoder) encode(book *Book) error {
+	return e.w.Write([]string{
+		book.Title, book.Author, book.ISBN, strconv.Itoa(book.PublishedYear),
+		book.Category, book.Description, book.CoverURL, strconv.Itoa(book.Quantity),
+	})
+}
+
+func (e *csvBookEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvBookEncoder) end() error {
+	return e.flush()
+}
+
+// jsonlBookEncoder JSON Lines导出
+type jsonlBookEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlBookEncoder) begin() error { return nil }
+
+func (e *jsonlBookEncoder) encode(book *Book) error {
+	return e.enc.Encode(book)
+}
+
+func (e *jsonlBookEncoder) flush() error { return nil }
+
+func (e *jsonlBookEncoder) end() error { return nil }
+
+// marcXMLSubfield、marcXMLField、marcXMLControl、marcXMLOut 为MARCXML输出结构
+type marcXMLSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcXMLField struct {
+	Tag       string            `xml:"tag,attr"`
+	Ind1      string            `xml:"ind1,attr"`
+	Ind2      string            `xml:"ind2,attr"`
+	Subfields []marcXMLSubfield `xml:"subfield"`
+}
+
+type marcXMLControl struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcXMLOut struct {
+	XMLName       xml.Name         `xml:"record"`
+	Leader        string           `xml:"leader"`
+	ControlFields []marcXMLControl `xml:"controlfield"`
+	DataFields    []marcXMLField   `xml:"datafield"`
+}
+
+// marcXMLBookEncoder MARCXML导出，字段对应关系与批量导入一致
+type marcXMLBookEncoder struct {
+	w   io.Writer
+	enc *xml.Encoder
+}
+
+func (e *marcXMLBookEncoder) begin() error {
+	_, err := io.WriteString(e.w, xml.Header+`<collection xmlns="http://www.loc.gov/MARC21/slim">`+"\n")
+	return err
+}
+
+func (e *marcXMLBookEncoder) encode(book *Book) error {
+	field := func(tag, ind1, ind2, code, value string) marcXMLField {
+		return marcXMLField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: []marcXMLSubfield{{Code: code, Value: value}}}
+	}
+
+	// 008 定长字段：7-10位为出版年，35-37位为语种
+	fixed := []byte(strings.Repeat(" ", 40))
+	copy(fixed[6:], fmt.Sprintf("s%04d", book.PublishedYear))
+	copy(fixed[35:], "chi")
+
+	record := marcXMLOut{
+		Leader: "     nam a22     uu 4500",
+		ControlFields: []marcXMLControl{
+			{Tag: "001", Value: strconv.Itoa(book.ID)},
+			{Tag: "008", Value: string(fixed)},
+		},
+		DataFields: []marcXMLField{
+			field("020", " ", " ", "a", book.ISBN),
+			field("100", "1", " ", "a", book.Author),
+			field("245", "1", "0", "a", book.Title),
+			field("264", " ", "1", "c", strconv.Itoa(book.PublishedYear)),
+			field("520", " ", " ", "a", book.Description),
+			field("650", " ", "4", "a", book.Category),
+			field("856", "4", "2", "u", book.CoverURL),
+		},
+	}
+	if err := e.enc.Encode(record); err != nil {
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n")
+	return err
+}
+
+func (e *marcXMLBookEncoder) flush() error { return nil }
+
+func (e *marcXMLBookEncoder) end() error {
+	_, err := io.WriteString(e.w, "</collection>\n")
+	return err
+}
+
+// bibtexBookEncoder BibTeX导出，条目键为 book<ID>
+type bibtexBookEncoder struct {
+	w io.Writer
+}
+
+// bibtexEscaper 转义BibTeX中的特殊字符
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`, `}`, `\}`,
+	`%`, `\%`, `&`, `\&`, `$`, `\$`, `#`, `\#`, `_`, `\_`,
+)
+
+func (e *bibtexBookEncoder) begin() error { return nil }
+
+func (e *bibtexBookEncoder) encode(book *Book) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@book{book%d,\n", book.ID)
+	for _, f := range [][2]string{
+		{"title", book.Title},
+		{"author", book.Author},
+		{"year", strconv.Itoa(book.PublishedYear)},
+		{"isbn", book.ISBN},
+		{"keywords", book.Category},
+		{"abstract", book.Description},
+	} {
+		if f[1] != "" {
+			fmt.Fprintf(&b, "  %s = {%s},\n", f[0], bibtexEscaper.Replace(f[1]))
+		}
+	}
+	b.WriteString("}\n\n")
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+func (e *bibtexBookEncoder) flush() error { return nil }
+
+func (e *bibtexBookEncoder) end() error { return nil }
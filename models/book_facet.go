@@ -0,0 +1,149 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+)
+
+// BookFacet 图书统计维度
+type BookFacet string
+
+const (
+	FacetCategory     BookFacet = "category"     // 分类
+	FacetClass        BookFacet = "class"        // 分类表节点，取值为节点ID
+	FacetAuthor       BookFacet = "author"       // 作者
+	FacetDecade       BookFacet = "decade"       // 出版年代
+	FacetAvailability BookFacet = "availability" // 是否可借
+)
+
+// 可借维度的取值
+const (
+	facetAvailable   = "available"
+	facetUnavailable = "unavailable"
+)
+
+// maxAuthorFacets 作者维度最多返回的取值数量
+const maxAuthorFacets = 20
+
+// FacetCount 统计维度上的一个取值及命中数量
+type FacetCount struct {
+	Value    string `json:"value"`
+	Label    string `json:"label"`
+	Count    int    `json:"count"`
+	Selected bool   `json:"selected"`
+}
+
+// BookFacets 检索结果在各维度上的分布
+type BookFacets struct {
+	Category     []FacetCount `json:"category"`
+	Author       []FacetCount `json:"author"`
+	Decade       []FacetCount `json:"decade"`
+	Availability []FacetCount `json:"availability"`
+}
+
+// decadeOf 计算出版年份所属的年代
+func decadeOf(year int) int {
+	return year / 10 * 10
+}
+
+// FindBookFacets 统计满足查询条件的图书在各维度上的分布；
+// 每个维度统计时不应用该维度自身的筛选条件，便于在同一维度内切换取值
+func FindBookFacets(query BookQuery) (*BookFacets, error) {
+	if err := query.normalize(); err != nil {
+		return nil, err
+	}
+
+	if err := query.resolveIDs(); err != nil {
+		return nil, err
+	}
+
+	facets := &BookFacets{}
+
+	without := query
+	without.Category = ""
+	counts, err := GetRepositories().Books.Facet(without, FacetCategory)
+	if err != nil {
+		return nil, err
+	}
+	facets.Category = labelFacets(counts, query.Category, func(v string) string { return v })
+	sortFacetsByCount(facets.Category)
+
+	without = query
+	without.Author = ""
+	counts, err = GetRepositories().Books.Facet(without, FacetAuthor)
+	if err != nil {
+		return nil, err
+	}
+	facets.Author = labelFacets(counts, query.Author, func(v string) string { return v })
+	sortFacetsByCount(facets.Author)
+	if len(facets.Author) > maxAuthorFacets {
+		// 截断时保留已选中的作者
+		top := facets.Author[:maxAuthorFacets]
+		for _, count := range facets.Author[maxAuthorFacets:] {
+			if count.Selected {
+				top = append(top, count)
+			}
+		}
+		facets.Author = top
+	}
+
+	without = query
+	without.Decade = 0
+	counts, err = GetRepositories().Books.Facet(without, FacetDecade)
+	if err != nil {
+		return nil, err
+	}
+	selectedDecade := ""
+	if query.Decade != 0 {
+		selectedDecade = strconv.Itoa(query.Decade)
+	}
+	facets.Decade = labelFacets(counts, selectedDecade, func(v string) string { return v + "年代" })
+	sort.Slice(facets.Decade, func(i, j int) bool {
+		a, _ := strconv.Atoi(facets.Decade[i].Value)
+		b, _ := strconv.Atoi(facets.Decade[j].Value)
+		return a > b
+	})
+
+	without = query
+	without.AvailableOnly = false
+	counts, err = GetRepositories().Books.Facet(without, FacetAvailability)
+	if err != nil {
+		return nil, err
+	}
+	selectedAvailability := ""
+	if query.AvailableOnly {
+		selectedAvailability = facetAvailable
+	}
+	facets.Availability = labelFacets(counts, selectedAvailability, func(v string) string {
+		if v == facetAvailable {
+			return "可借"
+		}
+		return "暂无可借"
+	})
+	sort.Slice(facets.Availability, func(i, j int) bool {
+		return facets.Availability[i].Value < facets.Availability[j].Value
+	})
+
+	return facets, nil
+}
+
+// labelFacets 填充取值的显示文本和选中状态
+func labelFacets(counts []FacetCount, selected string, label func(string) string) []FacetCount {
+	result := make([]FacetCount, 0, len(counts))
+	for _, count := range counts {
+		count.Label = label(count.Value)
+		count.Selected = count.Value == selected
+		result = append(result, count)
+	}
+	return result
+}
+
+// sortFacetsByCount 按命中数量降序排列，数量相同时按取值排列
+func sortFacetsByCount(counts []FacetCount) {
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+}
@@ -0,0 +1,411 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ImportAction 导入行的处理方式
+type ImportAction string
+
+const (
+	ImportCreate  ImportAction = "create"  // 新增图书
+	ImportUpdate  ImportAction = "update"  // 更新已有图书
+	ImportSkip    ImportAction = "skip"    // ISBN已存在，跳过
+	ImportInvalid ImportAction = "invalid" // 校验未通过
+)
+
+// DuplicateMode ISBN已存在时的处理方式
+type DuplicateMode string
+
+const (
+	DuplicateSkip   DuplicateMode = "skip"   // 跳过
+	DuplicateUpdate DuplicateMode = "update" // 用文件中的数据覆盖
+)
+
+// maxImportRows 单个文件最多导入的行数
+const maxImportRows = 5000
+
+// importBatchLifetime 预览后等待确认的有效期
+const importBatchLifetime = 30 * time.Minute
+
+// importFields 导入文件可识别的列名，键为规范字段名
+var importFields = map[string][]string{
+	"title":          {"title", "书名", "标题", "题名"},
+	"author":         {"author", "作者", "责任者"},
+	"isbn":           {"isbn"},
+	"published_year": {"published_year", "year", "出版年份", "出版年"},
+	"category":       {"category", "分类", "类别"},
+	"description":    {"description", "简介", "描述", "内容简介"},
+	"cover_url":      {"cover_url", "cover", "封面", "封面url", "封面图片url"},
+	"quantity":       {"quantity", "数量", "册数", "复本数"},
+}
+
+// ImportRow 导入文件中的一行（或一条MARC记录）图书数据
+type ImportRow struct {
+	Line          int          `json:"line"`
+	Title         string       `json:"title"`
+	Author        string       `json:"author"`
+	ISBN          string       `json:"isbn"`
+	PublishedYear int          `json:"published_year"`
+	Category      string       `json:"category"`
+	Description   string       `json:"description"`
+	CoverURL      string       `json:"cover_url"`
+	Quantity      int          `json:"quantity"`
+	Action        ImportAction `json:"action"`
+	ExistingID    int          `json:"existing_id,omitempty"`
+	Error         string       `json:"error,omitempty"`
+
+	// rawYear、rawQuantity 保留原始文本，便于报告无法解析的数字
+	rawYear     string
+	rawQuantity string
+}
+
+// ImportDefaults 文件中缺失字段使用的缺省值
+type ImportDefaults struct {
+	Category    string
+	Description string
+	CoverURL    string
+	Quantity    int
+}
+
+// ImportBatch 已解析、等待确认的导入批次
+type ImportBatch struct {
+	Token     string
+	UserID    int
+	FileName  string
+	Mode      DuplicateMode
+	Rows      []*ImportRow
+	CreatedAt time.Time
+}
+
+// Count 统计指定处理方式的行数
+func (b *ImportBatch) Count(action ImportAction) int {
+	n := 0
+	for _, row := range b.Rows {
+		if row.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// ImportSummary 导入完成后的汇总报告
+type ImportSummary struct {
+	FileName string       `json:"file_name"`
+	Total    int          `json:"total"`
+	Created  int          `json:"created"`
+	Updated  int          `json:"updated"`
+	Skipped  int          `json:"skipped"`
+	Failed   int          `json:"failed"`
+	Failures []*ImportRow `json:"failures"`
+}
+
+var (
+	importBatches     = make(map[string]*ImportBatch)
+	importBatchesLock sync.Mutex
+)
+
+// newImportRow 根据规范字段名到文本值的映射创建导入行
+func newImportRow(line int, values map[string]string) *ImportRow {
+	return &ImportRow{
+		Line:        line,
+		Title:       values["title"],
+		Author:      values["author"],
+		ISBN:        values["isbn"],
+		Category:    values["category"],
+		Description: values["description"],
+		CoverURL:    values["cover_url"],
+		rawYear:     values["published_year"],
+		rawQuantity: values["quantity"],
+	}
+}
+
+// importHeader 将表头映射为规范字段名，返回列序号到字段名的映射
+func importHeader(header []string) (map[int]string, error) {
+	aliases := make(map[string]string)
+	for field, names := range importFields {
+		for _, name := range names {
+			aliases[name] = field
+		}
+	}
+
+	columns := make(map[int]string)
+	seen := make(map[string]bool)
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if key == "" {
+			continue
+		}
+		field, ok := aliases[key]
+		if !ok {
+			return nil, fmt.Errorf("无法识别的列：%s", strings.TrimSpace(name))
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("重复的列：%s", strings.TrimSpace(name))
+		}
+		seen[field] = true
+		columns[i] = field
+	}
+
+	for _, field := range []string{"title", "isbn"} {
+		if !seen[field] {
+			return nil, fmt.Errorf("缺少必需的列：%s", field)
+		}
+	}
+	return columns, nil
+}
+
+// tableRows 将表格数据（首行为表头）转换为导入行，lines 为每行在文件中的行号
+func tableRows(records [][]string, lines []int) ([]*ImportRow, error) {
+	if len(records) == 0 {
+		return nil, errors.New("文件为空")
+	}
+	columns, err := importHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []*ImportRow
+	for i, record := range records[1:] {
+		values := make(map[string]string)
+		empty := true
+		for col, value := range record {
+			field, ok := columns[col]
+			if !ok {
+				continue
+			}
+			value = strings.TrimSpace(value)
+			if value != "" {
+				empty = false
+			}
+			values[field] = value
+		}
+		if empty {
+			continue
+		}
+		rows = append(rows, newImportRow(lines[i+1], values))
+	}
+	return rows, nil
+}
+
+// ParseImportFile 按文件扩展名解析CSV、XLSX、MARC21（.mrc）或MARCXML（.xml）文件
+func ParseImportFile(fileName string, data []byte) ([]*ImportRow, error) {
+	var rows []*ImportRow
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		rows, err = parseImportCSV(data)
+	case ".xlsx":
+		rows, err = parseImportXLSX(data)
+	case ".mrc", ".marc", ".iso":
+		rows, err = parseImportMARC(data)
+	case ".xml":
+		rows, err = parseImportMARCXML(data)
+	default:
+		return nil, errors.New("不支持的文件格式，请上传CSV、XLSX、MARC21或MARCXML文件")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("文件中没有图书数据")
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("单次最多导入%d条记录", maxImportRows)
+	}
+	return rows, nil
+}
+
+// PrepareImport 补全缺省值并逐行校验，保存为等待确认的导入批次
+func PrepareImport(userID int, fileName string, rows []*ImportRow, mode DuplicateMode, defaults ImportDefaults) (*ImportBatch, error) {
+	if mode != DuplicateSkip && mode != DuplicateUpdate {
+		return nil, errors.New("无效的重复ISBN处理方式")
+	}
+
+	tree, err := GetClassificationTree()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]int)
+	for _, row := range rows {
+		applyImportDefaults(row, defaults)
+		row.Action, row.ExistingID, row.Error = ImportCreate, 0, ""
+
+		if err := parseImportNumbers(row); err != nil {
+			row.Action, row.Error = ImportInvalid, err.Error()
+			continue
+		}
+		if err := validateBook(row.Title, row.Author, row.ISBN, row.PublishedYear, row.Category, row.Description, row.CoverURL, row.Quantity, false); err != nil {
+			row.Action, row.Error = ImportInvalid, err.Error()
+			continue
+		}
+		isbn, err := NormalizeISBN(row.ISBN)
+		if err != nil {
+			row.Action, row.Error = ImportInvalid, err.Error()
+			continue
+		}
+		row.ISBN = isbn
+		if !tree.Empty() {
+			if _, err := tree.Resolve(row.Category); err != nil {
+				row.Action, row.Error = ImportInvalid, err.Error()
+				continue
+			}
+		}
+
+		// 文件内重复的ISBN只导入第一次出现的行
+		if line, ok := seen[row.ISBN]; ok {
+			row.Action, row.Error = ImportInvalid, fmt.Sprintf("ISBN与第%d行重复", line)
+			continue
+		}
+		seen[row.ISBN] = row.Line
+
+		if existing, _ := GetRepositories().Books.GetByISBN(row.ISBN); existing != nil {
+			row.ExistingID = existing.ID
+			row.Action = ImportSkip
+			if mode == DuplicateUpdate {
+				row.Action = ImportUpdate
+			}
+		}
+	}
+
+	token, err := newImportToken()
+	if err != nil {
+		return nil, err
+	}
+	batch := &ImportBatch{
+		Token:     token,
+		UserID:    userID,
+		FileName:  fileName,
+		Mode:      mode,
+		Rows:      rows,
+		CreatedAt: time.Now(),
+	}
+
+	importBatchesLock.Lock()
+	defer importBatchesLock.Unlock()
+	for key, b := range importBatches {
+		if time.Since(b.CreatedAt) > importBatchLifetime {
+			delete(importBatches, key)
+		}
+	}
+	importBatches[token] = batch
+	return batch, nil
+}
+
+// RunImport 执行已确认的导入批次，批次只能使用一次
+func RunImport(token string, userID int) (*ImportSummary, error) {
+	importBatchesLock.Lock()
+	batch, ok := importBatches[token]
+	if ok {
+		delete(importBatches, token)
+	}
+	importBatchesLock.Unlock()
+
+	if !ok || batch.UserID != userID || time.Since(batch.CreatedAt) > importBatchLifetime {
+		return nil, errors.New("导入批次不存在或已过期，请重新上传文件")
+	}
+
+	summary := &ImportSummary{FileName: batch.FileName, Total: len(batch.Rows), Failures: []*ImportRow{}}
+	for _, row := range batch.Rows {
+		var err error
+		switch row.Action {
+		case ImportCreate:
+			_, err = CreateBook(row.Title, row.Author, row.ISBN, row.PublishedYear, row.Category, row.Description, row.CoverURL, row.Quantity)
+			if err == nil {
+				summary.Created++
+			}
+		case ImportUpdate:
+			_, err = UpdateBook(row.ExistingID, row.Title, row.Author, row.ISBN, row.PublishedYear, row.Category, row.Description, row.CoverURL, row.Quantity)
+			if err == nil {
+				summary.Updated++
+			}
+		case ImportSkip:
+			summary.Skipped++
+		default:
+			err = errors.New(row.Error)
+		}
+
+		if err != nil {
+			row.Error = err.Error()
+			summary.Failed++
+			summary.Failures = append(summary.Failures, row)
+		}
+	}
+	return summary, nil
+}
+
+// GetImportBatch 获取用户等待确认的导入批次
+func GetImportBatch(token string, userID int) (*ImportBatch, error) {
+	importBatchesLock.Lock()
+	defer importBatchesLock.Unlock()
+
+	batch, ok := importBatches[token]
+	if !ok || batch.UserID != userID || time.Since(batch.CreatedAt) > importBatchLifetime {
+		return nil, errors.New("导入批次不存在或已过期，请重新上传文件")
+	}
+	return batch, nil
+}
+
+// applyImportDefaults 用缺省值补全空字段
+func applyImportDefaults(row *ImportRow, defaults ImportDefaults) {
+	if row.Category == "" {
+		row.Category = defaults.Category
+	}
+	if row.Description == "" {
+		row.Description = defaults.Description
+	}
+	if row.CoverURL == "" {
+		row.CoverURL = defaults.CoverURL
+	}
+	if row.rawQuantity == "" && defaults.Quantity > 0 {
+		row.rawQuantity = strconv.Itoa(defaults.Quantity)
+	}
+}
+
+// parseImportNumbers 解析出版年份和数量
+func parseImportNumbers(row *ImportRow) error {
+	year, err := parseImportInt(row.rawYear)
+	if err != nil {
+		return fmt.Errorf("出版年份格式错误：%s", row.rawYear)
+	}
+	quantity, err := parseImportInt(row.rawQuantity)
+	if err != nil {
+		return fmt.Errorf("数量格式错误：%s", row.rawQuantity)
+	}
+	row.PublishedYear, row.Quantity = year, quantity
+	return nil
+}
+
+// parseImportInt 解析整数，兼容电子表格导出的 "2016.0" 形式，空值为0
+func parseImportInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f != float64(int(f)) {
+		return 0, errors.New("不是整数")
+	}
+	return int(f), nil
+}
+
+// newImportToken 生成导入批次令牌
+func newImportToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成导入批次失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
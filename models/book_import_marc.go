@@ -0,0 +1,259 @@
+package models
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ISO 2709 分隔符
+const (
+	marcRecordTerminator = 0x1D
+	marcFieldTerminator  = 0x1E
+	marcSubfieldDelim    = 0x1F
+)
+
+// marcField 一个MARC字段，控制字段只有 Value，数据字段只有 Subfields
+type marcField struct {
+	Tag       string
+	Ind2      byte
+	Value     string
+	Subfields [][2]string // [代码, 内容]
+}
+
+// marcRecord 一条MARC记录
+type marcRecord struct {
+	Fields []marcField
+}
+
+// subfield 返回第一个指定标签字段中指定代码的子字段，filter 为空时不限制字段
+func (r *marcRecord) subfield(tag string, code string, filter func(marcField) bool) string {
+	for _, field := range r.Fields {
+		if field.Tag != tag || (filter != nil && !filter(field)) {
+			continue
+		}
+		for _, sf := range field.Subfields {
+			if sf[0] == code && strings.TrimSpace(sf[1]) != "" {
+				return strings.TrimSpace(sf[1])
+			}
+		}
+	}
+	return ""
+}
+
+// control 返回控制字段的内容
+func (r *marcRecord) control(tag string) string {
+	for _, field := range r.Fields {
+		if field.Tag == tag {
+			return field.Value
+		}
+	}
+	return ""
+}
+
+var (
+	marcISBNPattern = regexp.MustCompile(`[0-9Xx][0-9Xx-]{8,16}`)
+	marcYearPattern = regexp.MustCompile(`\d{4}`)
+)
+
+// trimISBD 去掉著录标识符留下的结尾标点
+func trimISBD(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), " /:;,.="))
+}
+
+// importValues 按MARC21书目格式提取导入字段
+func (r *marcRecord) importValues() map[string]string {
+	values := make(map[string]string)
+
+	// 020 ISBN，去掉 "(pbk.)" 之类的附加说明
+	if isbn := marcISBNPattern.FindString(r.subfield("020", "a", nil)); isbn != "" {
+		values["isbn"] = isbn
+	}
+
+	// 245 题名与责任说明
+	title := trimISBD(r.subfield("245", "a", nil))
+	if sub := trimISBD(r.subfield("245", "b", nil)); sub != "" {
+		title += "：" + sub
+	}
+	values["title"] = title
+
+	// 100/110/700 主要责任者，缺失时取245$c
+	for _, tag := range []string{"100", "110", "700"} {
+		if author := trimISBD(r.subfield(tag, "a", nil)); author != "" {
+			values["author"] = author
+			break
+		}
+	}
+	if values["author"] == "" {
+		values["author"] = trimISBD(r.subfield("245", "c", nil))
+	}
+
+	// 264（第二指示符为1表示出版）或260的出版日期，缺失时取008定长字段
+	published := r.subfield("264", "c", func(f marcField) bool { return f.Ind2 == '1' })
+	if published == "" {
+		published = r.subfield("260", "c", nil)
+	}
+	if year := marcYearPattern.FindString(published); year != "" {
+		values["published_year"] = year
+	} else if fixed := r.control("008"); len(fixed) >= 11 {
+		if _, err := strconv.Atoi(fixed[7:11]); err == nil {
+			values["published_year"] = fixed[7:11]
+		}
+	}
+
+	// 520 内容摘要
+	values["description"] = r.subfield("520", "a", nil)
+
+	// 650 主题词，缺失时使用084（中图法等）或082分类号
+	for _, tag := range []string{"650", "084", "082"} {
+		if category := trimISBD(r.subfield(tag, "a", nil)); category != "" {
+			values["category"] = category
+			break
+		}
+	}
+	return values
+}
+
+// parseImportMARC 解析ISO 2709格式的MARC21文件，假定记录为UTF-8编码
+func parseImportMARC(data []byte) ([]*ImportRow, error) {
+	var rows []*ImportRow
+	n := 0
+	for _, raw := range bytes.Split(data, []byte{marcRecordTerminator}) {
+		raw = bytes.TrimLeft(raw, "\r\n ")
+		if len(raw) == 0 {
+			continue
+		}
+		n++
+		record, err := decodeMARC(raw)
+		if err != nil {
+			return nil, fmt.Errorf("第%d条MARC记录格式错误: %w", n, err)
+		}
+		rows = append(rows, newImportRow(n, record.importValues()))
+	}
+	return rows, nil
+}
+
+// decodeMARC 解码一条ISO 2709记录（不含记录结束符）
+func decodeMARC(raw []byte) (*marcRecord, error) {
+	if len(raw) < 25 {
+		return nil, errors.New("记录过短")
+	}
+	base, ok := marcNumber(raw[12:17])
+	if !ok || base < 25 || base > len(raw) {
+		return nil, errors.New("数据起始地址无效")
+	}
+
+	record := &marcRecord{}
+	directory := raw[24 : base-1]
+	if len(directory)%12 != 0 {
+		return nil, errors.New("目次区长度无效")
+	}
+	for i := 0; i < len(directory); i += 12 {
+		entry := directory[i : i+12]
+		length, ok1 := marcNumber(entry[3:7])
+		start, ok2 := marcNumber(entry[7:12])
+		if !ok1 || !ok2 || base+start+length > len(raw) {
+			return nil, errors.New("目次项无效")
+		}
+		tag := string(entry[0:3])
+		content := bytes.TrimRight(raw[base+start:base+start+length], string(rune(marcFieldTerminator)))
+		record.Fields = append(record.Fields, decodeMARCField(tag, content))
+	}
+	return record, nil
+}
+
+// marcNumber 解析头标和目次中的定长数字，只接受十进制数字（不允许符号和空格）
+func marcNumber(b []byte) (int, bool) {
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, len(b) > 0
+}
+
+// decodeMARCField 解码字段内容，00X为控制字段
+func decodeMARCField(tag string, content []byte) marcField {
+	field := marcField{Tag: tag}
+	if strings.HasPrefix(tag, "00") {
+		field.Value = string(content)
+		return field
+	}
+
+	parts := bytes.Split(content, []byte{marcSubfieldDelim})
+	if len(parts[0]) >= 2 {
+		field.Ind2 = parts[0][1]
+	}
+	for _, part := range parts[1:] {
+		if len(part) == 0 {
+			continue
+		}
+		field.Subfields = append(field.Subfields, [2]string{string(part[:1]), string(part[1:])})
+	}
+	return field
+}
+
+// marcXMLRecord MARCXML中的 record 元素
+type marcXMLRecord struct {
+	ControlFields []struct {
+		Tag   string `xml:"tag,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"controlfield"`
+	DataFields []struct {
+		Tag       string `xml:"tag,attr"`
+		Ind2      string `xml:"ind2,attr"`
+		Subfields []struct {
+			Code  string `xml:"code,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"subfield"`
+	} `xml:"datafield"`
+}
+
+// parseImportMARCXML 解析MARCXML文件，根元素可以是 collection 或单条 record
+func parseImportMARCXML(data []byte) ([]*ImportRow, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var rows []*ImportRow
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("MARCXML格式错误: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var x marcXMLRecord
+		if err := decoder.DecodeElement(&x, &start); err != nil {
+			return nil, fmt.Errorf("第%d条MARC记录格式错误: %w", len(rows)+1, err)
+		}
+
+		record := &marcRecord{}
+		for _, cf := range x.ControlFields {
+			record.Fields = append(record.Fields, marcField{Tag: cf.Tag, Value: cf.Value})
+		}
+		for _, df := range x.DataFields {
+			field := marcField{Tag: df.Tag}
+			if df.Ind2 != "" {
+				field.Ind2 = df.Ind2[0]
+			}
+			for _, sf := range df.Subfields {
+				field.Subfields = append(field.Subfields, [2]string{sf.Code, sf.Value})
+			}
+			record.Fields = append(record.Fields, field)
+		}
+		rows = append(rows, newImportRow(len(rows)+1, record.importValues()))
+	}
+	return rows, nil
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+// buildMARC 按给定的目次项拼出一条ISO 2709记录，entries 为已编码的12位目次项
+func buildMARC(entries []string, data string) []byte {
+	directory := ""
+	for _, entry := range entries {
+		directory += entry
+	}
+	directory += string(rune(marcFieldTerminator))
+	base := 24 + len(directory)
+	leader := fmt.Sprintf("%05dnam a22%05d   4500", 24+len(directory)+len(data)+1, base)
+	return []byte(leader + directory + data)
+}
+
+func TestDecodeMARC(t *testing.T) {
+	field := "  " + string(rune(marcSubfieldDelim)) + "a三体" + string(rune(marcFieldTerminator))
+	record, err := decodeMARC(buildMARC([]string{fmt.Sprintf("245%04d%05d", len(field), 0)}, field))
+	if err != nil {
+		t.Fatalf("decodeMARC: %v", err)
+	}
+	if got := record.subfield("245", "a", nil); got != "三体" {
+		t.Errorf("245$a = %q, want %q", got, "三体")
+	}
+}
+
+func TestDecodeMARCRejectsInvalidDirectory(t *testing.T) {
+	field := "  " + string(rune(marcSubfieldDelim)) + "a三体" + string(rune(marcFieldTerminator))
+	tests := []struct {
+		name  string
+		entry string
+	}{
+		{"negative length", "245-00100000"},
+		{"negative start", "2450005-0005"},
+		{"signed length", "245+00500000"},
+		{"blank start", fmt.Sprintf("245%04d    0", len(field))},
+		{"beyond record", fmt.Sprintf("245%04d%05d", len(field), 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeMARC(buildMARC([]string{tt.entry}, field)); err == nil {
+				t.Errorf("decodeMARC(%q) succeeded, want error", tt.entry)
+			}
+		})
+	}
+}
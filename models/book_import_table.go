@@ -0,0 +1,253 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// parseImportCSV 解析首行为表头的CSV文件，兼容Excel导出的UTF-8 BOM
+func parseImportCSV(data []byte) ([]*ImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV格式错误: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return tableRows(records, lines)
+}
+
+// xlsxWorkbook、xlsxRelationships、xlsxSharedStrings、xlsxSheet 对应XLSX包内的XML结构
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String 拼接纯文本和富文本片段
+func (t xlsxText) String() string {
+	var b strings.Builder
+	b.WriteString(t.T)
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Num   int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// parseImportXLSX 解析XLSX工作簿的第一个工作表，首行为表头
+func parseImportXLSX(data []byte) ([]*ImportRow, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("XLSX文件已损坏或格式不正确")
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxFirstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("XLSX文件中没有工作表")
+	}
+	var sheet xlsxSheet
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	var lines []int
+	for i, row := range sheet.Rows {
+		var record []string
+		for j, cell := range row.Cells {
+			col := j
+			if cell.Ref != "" {
+				var err error
+				if col, err = xlsxColumn(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= xlsxMaxColumns {
+				return nil, fmt.Errorf("XLSX工作表的列数超过%d列", xlsxMaxColumns)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, errors.New("XLSX共享字符串索引无效")
+				}
+				record[col] = shared.Items[idx].String()
+			case "inlineStr":
+				record[col] = cell.Inline.String()
+			case "", "n":
+				record[col] = xlsxNumber(cell.Value)
+			default:
+				record[col] = cell.Value
+			}
+		}
+
+		line := row.Num
+		if line == 0 {
+			line = i + 1
+		}
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return tableRows(records, lines)
+}
+
+// xlsxFirstSheet 根据工作簿关系找到第一个工作表的路径
+func xlsxFirstSheet(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	wf, ok1 := files["xl/workbook.xml"]
+	rf, ok2 := files["xl/_rels/workbook.xml.rels"]
+	if !ok1 || !ok2 {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+	if err := decodeZipXML(wf, &workbook); err != nil {
+		return "", err
+	}
+	if err := decodeZipXML(rf, &rels); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("XLSX文件中没有工作表")
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/"), nil
+			}
+			return path.Join("xl", rel.Target), nil
+		}
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+// maxXLSXPartSize XLSX中单个XML文件解压后的大小上限，防止压缩炸弹耗尽内存
+const maxXLSXPartSize = 32 << 20
+
+// decodeZipXML 解码压缩包中的XML文件，解压后超过 maxXLSXPartSize 时返回错误
+func decodeZipXML(f *zip.File, v interface{}) error {
+	tooLarge := fmt.Errorf("XLSX文件中的 %s 解压后超过%dMB", f.Name, maxXLSXPartSize>>20)
+	if f.UncompressedSize64 > maxXLSXPartSize {
+		return tooLarge
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("读取XLSX文件失败: %w", err)
+	}
+	defer rc.Close()
+
+	// 压缩包中记录的大小可能被篡改，读取时再限制一次
+	limited := &io.LimitedReader{R: rc, N: maxXLSXPartSize + 1}
+	err = xml.NewDecoder(limited).Decode(v)
+	if limited.N <= 0 {
+		return tooLarge
+	}
+	if err != nil {
+		return fmt.Errorf("解析XLSX文件失败: %w", err)
+	}
+	return nil
+}
+
+// xlsxMaxColumns XLSX工作表的最大列数（A 至 XFD）
+const xlsxMaxColumns = 16384
+
+// xlsxColumn 将单元格引用（如 "AB12"）转换为从0开始的列序号，
+// 引用中没有列字母或超出 XFD 列时返回错误
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		if col > xlsxMaxColumns {
+			return 0, fmt.Errorf("XLSX单元格引用 %q 超出最大列 XFD", ref)
+		}
+	}
+	if col == 0 {
+		return 0, fmt.Errorf("XLSX单元格引用 %q 无效", ref)
+	}
+	return col - 1, nil
+}
+
+// xlsxNumber 将科学计数法的数字单元格还原为普通写法，避免ISBN变成 9.787115428028E12
+func xlsxNumber(value string) string {
+	if !strings.ContainsAny(value, "eE") {
+		return value
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,419 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MemoryBookRepository 基于内存切片的图书仓库实现
+type MemoryBookRepository struct {
+	mu         sync.RWMutex
+	books      []*Book
+	nextID     int
+	categories map[string]bool
+	version    int64
+}
+
+// NewMemoryBookRepository 创建内存图书仓库
+func NewMemoryBookRepository() *MemoryBookRepository {
+	return &MemoryBookRepository{
+		nextID:     1,
+		categories: make(map[string]bool),
+	}
+}
+
+// Create 添加图书并分配ID
+func (r *MemoryBookRepository) Create(book *Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	book.ID = r.nextID
+	r.books = append(r.books, book)
+	r.nextID++
+
+	// 添加到分类映射
+	r.categories[book.Category] = true
+	return nil
+}
+
+// Update 更新图书
+func (r *MemoryBookRepository) Update(book *Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, b := range r.books {
+		if b.ID == book.ID {
+			r.books[i] = book
+			r.rebuildCategories()
+			return nil
+		}
+	}
+	return ErrBookNotFound
+}
+
+// Delete 删除图书
+func (r *MemoryBookRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, b := range r.books {
+		if b.ID == id {
+			r.books = append(r.books[:i], r.books[i+1:]...)
+			r.rebuildCategories()
+			return nil
+		}
+	}
+	return ErrBookNotFound
+}
+
+// GetByID 根据ID获取图书
+func (r *MemoryBookRepository) GetByID(id int) (*Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, book := range r.books {
+		if book.ID == id {
+			return book, nil
+		}
+	}
+	return nil, ErrBookNotFound
+}
+
+// GetByISBN 根据ISBN获取图书
+func (r *MemoryBookRepository) GetByISBN(isbn string) (*Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, book := range r.books {
+		if book.ISBN == isbn {
+			return book, nil
+		}
+	}
+	return nil, ErrBookNotFound
+}
+
+// GetAll 获取所有图书
+func (r *MemoryBookRepository) GetAll() ([]*Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*Book, len(r.books))
+	copy(result, r.books)
+	return result, nil
+}
+
+// GetByCategory 根据分类获取图书
+func (r *MemoryBookRepository) GetByCategory(category string) ([]*Book, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Book
+	for _, book := range r.books {
+		if book.Category == category {
+			result = append(result, book)
+		}
+	}
+	return result, nil
+}
+
+// Find 按条件筛选、排序并分页，返回当前页图书和满足条件的总数，limit 为0时不分页
+func (r *MemoryBookRepository) Find(query BookQuery, after *BookCursor, limit, offset int) ([]*Book, int, error) {
+	matched, available, err := r.match(query, query.Sort == SortAvailability)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := len(matched)
+
+	// compare 按排序键和ID比较，降序时两者同时反转，与游标翻页方向保持一致
+	compare := func(book *Book, cursor *BookCursor) int {
+		var c int
+		switch query.Sort {
+		case SortTitle:
+			c = strings.Compare(strings.ToLower(book.Title), strings.ToLower(cursor.Text))
+		case SortAuthor:
+			c = strings.Compare(strings.ToLower(book.Author), strings.ToLower(cursor.Text))
+		case SortYear:
+			c = book.PublishedYear - cursor.Num
+		case SortAvailability:
+			c = available[book.ID] - cursor.Num
+		case SortCallNumber:
+			c = strings.Compare(book.CallNumberKey, cursor.Text)
+		case SortRating:
+			c = book.ratingKey() - cursor.Num
+		}
+		if c == 0 {
+			c = book.ID - cursor.ID
+		}
+		if query.Desc {
+			c = -c
+		}
+		return c
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return compare(matched[i], cursorFor(matched[j], available[matched[j].ID], query.Sort, query.Desc)) < 0
+	})
+
+	// 跳过游标之前的图书
+	if after != nil {
+		start := len(matched)
+		for i, book := range matched {
+			if compare(book, after) > 0 {
+				start = i
+				break
+			}
+		}
+		matched = matched[start:]
+	}
+
+	if offset >= len(matched) {
+		return []*Book{}, total, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+// Facet 统计满足筛选条件的图书在某一维度上的分布
+func (r *MemoryBookRepository) Facet(query BookQuery, facet BookFacet) ([]FacetCount, error) {
+	matched, available, err := r.match(query, facet == FacetAvailability)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, book := range matched {
+		switch facet {
+		case FacetCategory:
+			counts[book.Category]++
+		case FacetClass:
+			counts[strconv.Itoa(book.ClassID)]++
+		case FacetAuthor:
+			counts[book.Author]++
+		case FacetDecade:
+			counts[strconv.Itoa(decadeOf(book.PublishedYear))]++
+		case FacetAvailability:
+			if available[book.ID] > 0 {
+				counts[facetAvailable]++
+			} else {
+				counts[facetUnavailable]++
+			}
+		}
+	}
+
+	result := make([]FacetCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, FacetCount{Value: value, Count: count})
+	}
+	return result, nil
+}
+
+// match 返回满足筛选条件的图书，needAvailable 为真时同时计算可借数量
+func (r *MemoryBookRepository) match(query BookQuery, needAvailable bool) ([]*Book, map[int]int, error) {
+	var classIDs map[int]bool
+	if query.ClassIDs != nil {
+		classIDs = make(map[int]bool, len(query.ClassIDs))
+		for _, id := range query.ClassIDs {
+			classIDs[id] = true
+		}
+	}
+	var ids map[int]bool
+	if query.IDs != nil {
+		ids = make(map[int]bool, len(query.IDs))
+		for _, id := range query.IDs {
+			ids[id] = true
+		}
+	}
+	var tagged map[int]bool
+	if len(query.Tags) > 0 {
+		bookIDs, err := bookIDsWithTags(query.Tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		tagged = make(map[int]bool, len(bookIDs))
+		for _, id := range bookIDs {
+			tagged[id] = true
+		}
+	}
+
+	r.mu.RLock()
+	var matched []*Book
+	for _, book := range r.books {
+		if ids != nil && !ids[book.ID] {
+			continue
+		}
+		if tagged != nil && !tagged[book.ID] {
+			continue
+		}
+		if query.Category != "" && book.Category != query.Category {
+			continue
+		}
+		if classIDs != nil && !classIDs[book.ClassID] {
+			continue
+		}
+		if query.Author != "" && book.Author != query.Author {
+			continue
+		}
+		if query.Decade != 0 && decadeOf(book.PublishedYear) != query.Decade {
+			continue
+		}
+		if query.YearFrom != 0 && book.PublishedYear < query.YearFrom {
+			continue
+		}
+		if query.YearTo != 0 && book.PublishedYear > query.YearTo {
+			continue
+		}
+		if query.Shelved && book.CallNumber == "" {
+			continue
+		}
+		matched = append(matched, book)
+	}
+	r.mu.RUnlock()
+
+	// 可借数量依赖副本和预约仓库，在释放图书锁之后计算
+	available := make(map[int]int)
+	if query.AvailableOnly || needAvailable {
+		filtered := matched[:0:0]
+		for _, book := range matched {
+			available[book.ID] = book.GetAvailableQuantity()
+			if !query.AvailableOnly || available[book.ID] > 0 {
+				filtered = append(filtered, book)
+			}
+		}
+		matched = filtered
+	}
+	return matched, available, nil
+}
+
+// GetCategories 获取所有分类
+func (r *MemoryBookRepository) GetCategories() ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []string{}
+	for category := range r.categories {
+		result = append(result, category)
+	}
+	return result, nil
+}
+
+// rebuildCategories 重建分类映射，调用方需持有写锁
+func (r *MemoryBookRepository) rebuildCategories() {
+	r.categories = make(map[string]bool)
+	for _, book := range r.books {
+		r.categories[book.Category] = true
+	}
+}
+
+// InitSampleBooks 初始化示例图书数据
+func (r *MemoryBookRepository) InitSampleBooks() {
+	// 清空图书列表
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.books = nil
+	r.nextID = 1
+	r.categories = make(map[string]bool)
+
+	// 创建示例图书
+	books := []struct {
+		Title         string
+		Author        string
+		ISBN          string
+		PublishedYear int
+		Category      string
+		Description   string
+		CoverURL      string
+		Quantity      int
+	}{
+		{
+			Title:         "Python编程：从入门到实践",
+			Author:        "埃里克·马瑟斯",
+			ISBN:          "9787115428028",
+			PublishedYear: 2016,
+			Category:      "编程",
+			Description:   "本书是一本针对所有层次的Python读者而作的Python入门书。全书分两部分：第一部分介绍用Python编程所必须了解的基本概念，第二部分将理论付诸实践，讲解如何开发三个项目。",
+			CoverURL:      "https://img3.doubanio.com/view/subject/l/public/s29424065.jpg",
+			Quantity:      5,
+		},
+		{
+			Title:         "Go语言实战",
+			Author:        "威廉·肯尼迪",
+			ISBN:          "9787115445353",
+			PublishedYear: 2017,
+			Category:      "编程",
+			Description:   "本书首先介绍Go语言的独特之处，然后讲解如何编写地道的Go代码并使用其特有的特性和工具包编写代码。后续章节会介绍测试、Web编程以及与其他主流语言的集成。",
+			CoverURL:      "https://img9.doubanio.com/view/subject/l/public/s29446435.jpg",
+			Quantity:      3,
+		},
+		{
+			Title:         "明朝那些事儿",
+			Author:        "当年明月",
+			ISBN:          "9787807023630",
+			PublishedYear: 2009,
+			Category:      "历史",
+			Description:   "《明朝那些事儿》讲述从1344年到1644年，明朝三百年间的历史。以史料为基础，以年代和具体人物为主线，运用小说的手法，对明朝十七帝和其他王公权贵和小人物的命运进行全景展示。",
+			CoverURL:      "https://img1.doubanio.com/view/subject/l/public/s27131114.jpg",
+			Quantity:      4,
+		},
+		{
+			Title:         "三体",
+			Author:        "刘慈欣",
+			ISBN:          "9787536692930",
+			PublishedYear: 2008,
+			Category:      "科幻",
+			Description:   "文化大革命如火如荼进行的同时，军方探寻外星文明的绝秘计划'红岸工程'取得了突破性进展。但在按下发射键的那一刻，历经劫难的叶文洁没有意识到，她彻底改变了人类的命运。",
+			CoverURL:      "https://img2.doubanio.com/view/subject/l/public/s2768378.jpg",
+			Quantity:      2,
+		},
+		{
+			Title:         "围城",
+			Author:        "钱钟书",
+			ISBN:          "9787020090006",
+			PublishedYear: 1991,
+			Category:      "文学",
+			Description:   "《围城》是钱钟书所著的长篇小说，自问世以来，就以它的犀利的语言、巧妙的结构和象征性的意义在中国文学史上占据重要地位。",
+			CoverURL:      "https://img2.doubanio.com/view/subject/l/public/s1070222.jpg",
+			Quantity:      3,
+		},
+	}
+
+	for _, bookData := range books {
+		book := &Book{
+			ID:            r.nextID,
+			Title:         bookData.Title,
+			Author:        bookData.Author,
+			ISBN:          bookData.ISBN,
+			PublishedYear: bookData.PublishedYear,
+			Category:      bookData.Category,
+			Description:   bookData.Description,
+			CoverURL:      bookData.CoverURL,
+			Quantity:      bookData.Quantity,
+		}
+
+		// 添加到列表并递增ID
+		r.books = append(r.books, book)
+		r.nextID++
+
+		// 添加到分类映射
+		r.categories[bookData.Category] = true
+	}
+}
+
+// CatalogVersion 获取图书目录版本号
+func (r *MemoryBookRepository) CatalogVersion() (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version, nil
+}
+
+// BumpCatalogVersion 递增图书目录版本号并返回新值
+func (r *MemoryBookRepository) BumpCatalogVersion() (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.version++
+	return r.version, nil
+}
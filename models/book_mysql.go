@@ -0,0 +1,416 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// bookColumns 图书表查询列
+const bookColumns = "id, title, author, isbn, published_year, category, classification_id, call_number, call_number_key, shelf_location, description, cover_url, cover_image, quantity, rating_average, rating_count"
+
+// MySQLBookRepository 基于MySQL的图书仓库实现
+type MySQLBookRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLBookRepository 创建MySQL图书仓库
+func NewMySQLBookRepository(db *sql.DB) *MySQLBookRepository {
+	return &MySQLBookRepository{db: db}
+}
+
+// Create 插入图书并回填ID
+func (r *MySQLBookRepository) Create(book *Book) error {
+	result, err := r.db.Exec(`
+		INSERT INTO books (title, author, isbn, published_year, category, classification_id,
+			call_number, call_number_key, shelf_location, description, cover_url, cover_image, quantity,
+			rating_average, rating_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		book.Title, book.Author, book.ISBN, book.PublishedYear, book.Category, book.ClassID,
+		book.CallNumber, book.CallNumberKey, book.ShelfLocation, book.Description, book.CoverURL, book.CoverImage, book.Quantity,
+		book.RatingAverage, book.RatingCount)
+	if err != nil {
+		return fmt.Errorf("创建图书失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取图书ID失败: %w", err)
+	}
+	book.ID = int(id)
+	return nil
+}
+
+// Update 更新图书
+func (r *MySQLBookRepository) Update(book *Book) error {
+	_, err := r.db.Exec(`
+		UPDATE books SET title = ?, author = ?, isbn = ?, published_year = ?,
+			category = ?, classification_id = ?, call_number = ?, call_number_key = ?, shelf_location = ?,
+			description = ?, cover_url = ?, cover_image = ?, quantity = ?, rating_average = ?, rating_count = ?
+		WHERE id = ?`,
+		book.Title, book.Author, book.ISBN, book.PublishedYear, book.Category, book.ClassID,
+		book.CallNumber, book.CallNumberKey, book.ShelfLocation, book.Description, book.CoverURL, book.CoverImage, book.Quantity,
+		book.RatingAverage, book.RatingCount, book.ID)
+	if err != nil {
+		return fmt.Errorf("更新图书失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除图书
+func (r *MySQLBookRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM books WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除图书失败: %w", err)
+	}
+	return checkAffected(result, ErrBookNotFound)
+}
+
+// GetByID 根据ID获取图书
+func (r *MySQLBookRepository) GetByID(id int) (*Book, error) {
+	row := r.db.QueryRow("SELECT "+bookColumns+" FROM books WHERE id = ?", id)
+	return scanBook(row)
+}
+
+// GetByISBN 根据ISBN获取图书
+func (r *MySQLBookRepository) GetByISBN(isbn string) (*Book, error) {
+	row := r.db.QueryRow("SELECT "+bookColumns+" FROM books WHERE isbn = ?", isbn)
+	return scanBook(row)
+}
+
+// GetAll 获取所有图书
+func (r *MySQLBookRepository) GetAll() ([]*Book, error) {
+	return r.query("SELECT " + bookColumns + " FROM books ORDER BY id")
+}
+
+// GetByCategory 根据分类获取图书
+func (r *MySQLBookRepository) GetByCategory(category string) ([]*Book, error) {
+	return r.query("SELECT "+bookColumns+" FROM books WHERE category = ? ORDER BY id", category)
+}
+
+// bookAvailableExpr 计算可借数量的SQL表达式：在架副本数减去待取预约数
+const bookAvailableExpr = `(SELECT COUNT(*) FROM book_copies c WHERE c.book_id = books.id AND c.status = 'available')
+	- (SELECT COUNT(*) FROM holds h WHERE h.book_id = books.id AND h.status = 'ready')`
+
+// bookSortColumns 排序字段对应的列
+var bookSortColumns = map[BookSort]string{
+	SortTitle:        "title",
+	SortAuthor:       "author",
+	SortYear:         "published_year",
+	SortAvailability: "available",
+	SortCallNumber:   "call_number_key",
+	SortRating:       "rating_average",
+}
+
+// maxInlineIDs 检索结果的图书ID不超过该数量时直接写入 IN 列表，超过时先写入临时表
+const maxInlineIDs = 1000
+
+// sqlRunner *sql.DB 和 *sql.Tx 共有的执行方法
+type sqlRunner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// withQueryIDs 准备查询条件中的图书ID范围后执行 fn：ID较多时在事务中将其写入临时表 book_query_ids，
+// 使同一连接上的查询可以通过子查询引用，避免生成过长的 IN 列表
+func (r *MySQLBookRepository) withQueryIDs(query BookQuery, fn func(db sqlRunner) error) error {
+	if len(query.IDs) <= maxInlineIDs {
+		return fn(r.db)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("查询图书失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 临时表不随事务回滚，用完后显式删除，避免留在连接池中的连接上
+	if _, err := tx.Exec("CREATE TEMPORARY TABLE book_query_ids (id INT PRIMARY KEY)"); err != nil {
+		return fmt.Errorf("查询图书失败: %w", err)
+	}
+	defer tx.Exec("DROP TEMPORARY TABLE IF EXISTS book_query_ids")
+
+	for start := 0; start < len(query.IDs); start += maxInlineIDs {
+		end := start + maxInlineIDs
+		if end > len(query.IDs) {
+			end = len(query.IDs)
+		}
+		chunk := query.IDs[start:end]
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("(?), ", len(chunk)), ", ")
+		if _, err := tx.Exec("INSERT IGNORE INTO book_query_ids (id) VALUES "+placeholders, args...); err != nil {
+			return fmt.Errorf("查询图书失败: %w", err)
+		}
+	}
+	return fn(tx)
+}
+
+// Find 按条件筛选、排序并分页，返回当前页图书和满足条件的总数，limit 为0时不分页
+func (r *MySQLBookRepository) Find(query BookQuery, after *BookCursor, limit, offset int) ([]*Book, int, error) {
+	var books []*Book
+	var total int
+	err := r.withQueryIDs(query, func(db sqlRunner) error {
+		var err error
+		books, total, err = findBooks(db, query, after, limit, offset)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return books, total, nil
+}
+
+// findBooks 在给定连接上执行图书列表查询
+func findBooks(db sqlRunner, query BookQuery, after *BookCursor, limit, offset int) ([]*Book, int, error) {
+	from, conditions, args := bookFilter(query, query.Sort == SortAvailability)
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计图书失败: %w", err)
+	}
+
+	// 游标条件：排序键之后，排序键相同时按ID
+	direction, op := "ASC", ">"
+	if query.Desc {
+		direction, op = "DESC", "<"
+	}
+	column := bookSortColumns[query.Sort]
+	if after != nil {
+		if column == "" {
+			conditions = append(conditions, "id "+op+" ?")
+			args = append(args, after.ID)
+		} else {
+			var key interface{} = after.Num
+			if query.Sort == SortTitle || query.Sort == SortAuthor || query.Sort == SortCallNumber {
+				key = after.Text
+			}
+			if query.Sort == SortRating {
+				key = float64(after.Num) / 100
+			}
+			conditions = append(conditions, fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, op, column, op))
+			args = append(args, key, key, after.ID)
+		}
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	order := " ORDER BY id " + direction
+	if column != "" {
+		order = fmt.Sprintf(" ORDER BY %s %s, id %s", column, direction, direction)
+	}
+
+	limitClause := ""
+	if limit > 0 {
+		limitClause = " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+	books, err := queryBooks(db, "SELECT "+bookColumns+" FROM "+from+where+order+limitClause, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return books, total, nil
+}
+
+// bookFacetExprs 各统计维度对应的分组表达式
+var bookFacetExprs = map[BookFacet]string{
+	FacetCategory:     "category",
+	FacetClass:        "CAST(classification_id AS CHAR)",
+	FacetAuthor:       "author",
+	FacetDecade:       "CAST(FLOOR(published_year / 10) * 10 AS CHAR)",
+	FacetAvailability: "CASE WHEN available > 0 THEN '" + facetAvailable + "' ELSE '" + facetUnavailable + "' END",
+}
+
+// Facet 统计满足筛选条件的图书在某一维度上的分布
+func (r *MySQLBookRepository) Facet(query BookQuery, facet BookFacet) ([]FacetCount, error) {
+	expr, ok := bookFacetExprs[facet]
+	if !ok {
+		return nil, fmt.Errorf("未知的统计维度: %s", facet)
+	}
+
+	from, conditions, args := bookFilter(query, facet == FacetAvailability)
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var counts []FacetCount
+	err := r.withQueryIDs(query, func(db sqlRunner) error {
+		rows, err := db.Query("SELECT "+expr+" AS value, COUNT(*) FROM "+from+where+" GROUP BY value", args...)
+		if err != nil {
+			return fmt.Errorf("统计图书分布失败: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var count FacetCount
+			if err := rows.Scan(&count.Value, &count.Count); err != nil {
+				return err
+			}
+			counts = append(counts, count)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// bookFilter 生成筛选条件对应的数据来源、WHERE条件和参数，needAvailable 为真时数据来源包含可借数量列
+func bookFilter(query BookQuery, needAvailable bool) (string, []string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if query.IDs != nil {
+		if len(query.IDs) == 0 {
+			conditions = append(conditions, "1 = 0")
+		} else if len(query.IDs) > maxInlineIDs {
+			// 已由 withQueryIDs 写入临时表
+			conditions = append(conditions, "id IN (SELECT id FROM book_query_ids)")
+		} else {
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(query.IDs)), ", ")
+			conditions = append(conditions, "id IN ("+placeholders+")")
+			for _, id := range query.IDs {
+				args = append(args, id)
+			}
+		}
+	}
+	// 标签条件用子查询筛选，不将标签下的图书ID展开为参数
+	for _, name := range query.Tags {
+		conditions = append(conditions, "id IN (SELECT bt.book_id FROM book_tags bt JOIN tags t ON t.id = bt.tag_id WHERE t.name = ?)")
+		args = append(args, strings.Join(strings.Fields(name), " "))
+	}
+	if query.Category != "" {
+		conditions = append(conditions, "category = ?")
+		args = append(args, query.Category)
+	}
+	if query.ClassIDs != nil {
+		if len(query.ClassIDs) == 0 {
+			conditions = append(conditions, "1 = 0")
+		} else {
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(query.ClassIDs)), ", ")
+			conditions = append(conditions, "classification_id IN ("+placeholders+")")
+			for _, id := range query.ClassIDs {
+				args = append(args, id)
+			}
+		}
+	}
+	if query.Author != "" {
+		conditions = append(conditions, "author = ?")
+		args = append(args, query.Author)
+	}
+	if query.Decade != 0 {
+		conditions = append(conditions, "published_year BETWEEN ? AND ?")
+		args = append(args, query.Decade, query.Decade+9)
+	}
+	if query.YearFrom != 0 {
+		conditions = append(conditions, "published_year >= ?")
+		args = append(args, query.YearFrom)
+	}
+	if query.YearTo != 0 {
+		conditions = append(conditions, "published_year <= ?")
+		args = append(args, query.YearTo)
+	}
+	if query.Shelved {
+		conditions = append(conditions, "call_number <> ''")
+	}
+	if query.AvailableOnly {
+		conditions = append(conditions, "available > 0")
+	}
+
+	// 需要可借数量时在派生表中计算，以便用于筛选、排序和统计
+	from := "books"
+	if query.AvailableOnly || needAvailable {
+		from = "(SELECT books.*, " + bookAvailableExpr + " AS available FROM books) b"
+	}
+	return from, conditions, args
+}
+
+// GetCategories 获取所有分类
+func (r *MySQLBookRepository) GetCategories() ([]string, error) {
+	rows, err := r.db.Query("SELECT DISTINCT category FROM books ORDER BY category")
+	if err != nil {
+		return nil, fmt.Errorf("查询图书分类失败: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// CatalogVersion 获取图书目录版本号，各实例据此判断本地检索索引是否过期
+func (r *MySQLBookRepository) CatalogVersion() (int64, error) {
+	var version int64
+	if err := r.db.QueryRow("SELECT version FROM catalog_version WHERE id = 1").Scan(&version); err != nil {
+		return 0, fmt.Errorf("查询图书目录版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// BumpCatalogVersion 递增图书目录版本号并返回新值
+func (r *MySQLBookRepository) BumpCatalogVersion() (int64, error) {
+	result, err := r.db.Exec("UPDATE catalog_version SET version = LAST_INSERT_ID(version + 1) WHERE id = 1")
+	if err != nil {
+		return 0, fmt.Errorf("更新图书目录版本失败: %w", err)
+	}
+	version, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("更新图书目录版本失败: %w", err)
+	}
+	return version, nil
+}
+
+// query 执行查询并扫描图书列表
+func (r *MySQLBookRepository) query(query string, args ...interface{}) ([]*Book, error) {
+	return queryBooks(r.db, query, args...)
+}
+
+// queryBooks 在给定连接上执行查询并扫描图书列表
+func queryBooks(db sqlRunner, query string, args ...interface{}) ([]*Book, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询图书失败: %w", err)
+	}
+	defer rows.Close()
+
+	var books []*Book
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	return books, rows.Err()
+}
+
+// scanBook 扫描一行图书数据
+func scanBook(row rowScanner) (*Book, error) {
+	book := &Book{}
+	err := row.Scan(&book.ID, &book.Title, &book.Author, &book.ISBN, &book.PublishedYear,
+		&book.Category, &book.ClassID, &book.CallNumber, &book.CallNumberKey, &book.ShelfLocation, &book.Description, &book.CoverURL, &book.CoverImage, &book.Quantity,
+		&book.RatingAverage, &book.RatingCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return book, nil
+}
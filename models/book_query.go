@@ -0,0 +1,279 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// BookSort 图书排序字段
+type BookSort string
+
+const (
+	SortDefault      BookSort = ""             // 按ID（入库顺序）
+	SortTitle        BookSort = "title"        // 按书名
+	SortAuthor       BookSort = "author"       // 按作者
+	SortYear         BookSort = "year"         // 按出版年份
+	SortAvailability BookSort = "availability" // 按可借数量
+	SortRelevance    BookSort = "relevance"    // 按检索相关度，仅在有关键词时有效
+	SortCallNumber   BookSort = "call_number"  // 按索书号（排架顺序）
+	SortRating       BookSort = "rating"       // 按读者平均评分
+)
+
+// 分页大小限制
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// BookQuery 图书列表查询条件，各筛选条件之间为"且"关系
+type BookQuery struct {
+	Query         string   // 检索语句，支持字段限定，见 ParseSearchQuery
+	Category      string   // 分类
+	ClassIDs      []int    // 限定分类节点（通常为某节点及其全部下级），nil 表示不限制
+	Author        string   // 作者（精确匹配）
+	Tags          []string // 标签，须同时带有全部标签
+	Decade        int      // 出版年代，如 2000 表示 2000-2009 年
+	YearFrom      int      // 出版年份下限（含）
+	YearTo        int      // 出版年份上限（含）
+	AvailableOnly bool     // 只显示可借图书
+	Shelved       bool     // 只包含已编制索书号的图书
+	Sort          BookSort // 排序字段
+	Desc          bool     // 是否降序
+	Page          int      // 页码，从1开始
+	Size          int      // 每页数量
+	Cursor        string   // 游标，设置后忽略页码
+	IDs           []int    // 限定图书ID范围（全文检索结果），nil 表示不限制
+}
+
+// BookCursor 基于排序键的分页游标
+type BookCursor struct {
+	Sort BookSort `json:"s"`
+	Desc bool     `json:"d"`
+	Text string   `json:"t,omitempty"` // 书名、作者或索书号排序时的排序键
+	Num  int      `json:"n,omitempty"` // 年份、可借数量或评分（×100）排序时的排序键
+	ID   int      `json:"i"`
+}
+
+// BookPage 图书分页查询结果
+type BookPage struct {
+	Books      []*Book `json:"books"`
+	Total      int     `json:"total"`
+	Page       int     `json:"page"`
+	Size       int     `json:"size"`
+	TotalPages int     `json:"total_pages"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+// HasPrev 是否有上一页
+func (p *BookPage) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext 是否有下一页
+func (p *BookPage) HasNext() bool {
+	return p.Page < p.TotalPages
+}
+
+// normalize 校验并补全查询条件
+func (q *BookQuery) normalize() error {
+	q.Query = strings.TrimSpace(q.Query)
+
+	switch q.Sort {
+	case SortDefault, SortTitle, SortAuthor, SortYear, SortAvailability, SortRelevance, SortCallNumber, SortRating:
+	default:
+		return errors.New("无效的排序方式")
+	}
+
+	// 有关键词时默认按相关度排序
+	if q.Query != "" && q.Sort == SortDefault {
+		q.Sort = SortRelevance
+	}
+	if q.Query == "" && q.Sort == SortRelevance {
+		q.Sort = SortDefault
+	}
+
+	if q.Decade%10 != 0 {
+		return errors.New("无效的出版年代")
+	}
+	if q.YearFrom != 0 && q.YearTo != 0 && q.YearFrom > q.YearTo {
+		return errors.New("起始年份不能晚于结束年份")
+	}
+
+	if q.Size <= 0 {
+		q.Size = DefaultPageSize
+	}
+	if q.Size > MaxPageSize {
+		q.Size = MaxPageSize
+	}
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	return nil
+}
+
+// encode 编码游标
+func (c *BookCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeBookCursor 解码游标，并校验游标与当前排序方式一致
+func decodeBookCursor(s string, sort BookSort, desc bool) (*BookCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("无效的游标")
+	}
+
+	cursor := &BookCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.ID <= 0 {
+		return nil, errors.New("无效的游标")
+	}
+	if cursor.Sort != sort || cursor.Desc != desc {
+		return nil, errors.New("游标与排序方式不一致")
+	}
+	return cursor, nil
+}
+
+// cursorFor 以图书生成下一页游标
+func cursorFor(book *Book, available int, sort BookSort, desc bool) *BookCursor {
+	cursor := &BookCursor{Sort: sort, Desc: desc, ID: book.ID}
+	switch sort {
+	case SortTitle:
+		cursor.Text = book.Title
+	case SortAuthor:
+		cursor.Text = book.Author
+	case SortYear:
+		cursor.Num = book.PublishedYear
+	case SortAvailability:
+		cursor.Num = available
+	case SortCallNumber:
+		cursor.Text = book.CallNumberKey
+	case SortRating:
+		cursor.Num = book.ratingKey()
+	}
+	return cursor
+}
+
+// resolveIDs 将关键词检索结果转换为图书ID范围，保持检索结果的相关度顺序；
+// 标签条件由仓库直接筛选
+func (q *BookQuery) resolveIDs() error {
+	if q.Query == "" {
+		return nil
+	}
+	ids, err := SearchBookIDs(q.Query)
+	if err != nil {
+		return err
+	}
+	q.IDs = ids
+	return nil
+}
+
+// findByRelevance 按检索结果的相关度顺序分页，多返回一条用于判断是否还有下一页
+func findByRelevance(query BookQuery, after *BookCursor, offset int) ([]*Book, int, error) {
+	matched, total, err := GetRepositories().Books.Find(query, nil, 0, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[int]*Book, len(matched))
+	for _, book := range matched {
+		byID[book.ID] = book
+	}
+	ranked := make([]*Book, 0, len(matched))
+	for _, id := range query.IDs {
+		if book, ok := byID[id]; ok {
+			ranked = append(ranked, book)
+		}
+	}
+	if query.Desc {
+		for i, j := 0, len(ranked)-1; i < j; i, j = i+1, j-1 {
+			ranked[i], ranked[j] = ranked[j], ranked[i]
+		}
+	}
+
+	// 游标记录上一页最后一本书，从它之后继续
+	if after != nil {
+		offset = -1
+		for i, book := range ranked {
+			if book.ID == after.ID {
+				offset = i + 1
+				break
+			}
+		}
+		if offset < 0 {
+			return nil, 0, errors.New("游标已失效，请重新查询")
+		}
+	}
+
+	if offset >= len(ranked) {
+		return []*Book{}, total, nil
+	}
+	ranked = ranked[offset:]
+	if len(ranked) > query.Size+1 {
+		ranked = ranked[:query.Size+1]
+	}
+	return ranked, total, nil
+}
+
+// FindBooks 按条件分页查询图书
+func FindBooks(query BookQuery) (*BookPage, error) {
+	if err := query.normalize(); err != nil {
+		return nil, err
+	}
+
+	var after *BookCursor
+	offset := (query.Page - 1) * query.Size
+	if query.Cursor != "" {
+		cursor, err := decodeBookCursor(query.Cursor, query.Sort, query.Desc)
+		if err != nil {
+			return nil, err
+		}
+		after = cursor
+		offset = 0
+	}
+
+	// 关键词和标签先转换为候选图书，其余条件交给仓库筛选
+	if err := query.resolveIDs(); err != nil {
+		return nil, err
+	}
+
+	var books []*Book
+	var total int
+	var err error
+	if query.Sort == SortRelevance {
+		books, total, err = findByRelevance(query, after, offset)
+	} else {
+		// 多取一条用于判断是否还有下一页
+		books, total, err = GetRepositories().Books.Find(query, after, query.Size+1, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	page := &BookPage{
+		Books:      books,
+		Total:      total,
+		Page:       query.Page,
+		Size:       query.Size,
+		TotalPages: (total + query.Size - 1) / query.Size,
+	}
+	if after != nil {
+		page.Page = 0
+	}
+
+	if len(books) > query.Size {
+		page.Books = books[:query.Size]
+		last := page.Books[len(page.Books)-1]
+		available := 0
+		if query.Sort == SortAvailability {
+			available = last.GetAvailableQuantity()
+		}
+		page.NextCursor = cursorFor(last, available, query.Sort, query.Desc).encode()
+	}
+	if page.Books == nil {
+		page.Books = []*Book{}
+	}
+	return page, nil
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"sync"
 	"time"
 )
@@ -11,60 +13,183 @@ type BorrowRecord struct {
 	ID         int       `json:"id"`
 	UserID     int       `json:"user_id"`
 	BookID     int       `json:"book_id"`
+	CopyID     int       `json:"copy_id"`
 	BorrowDate time.Time `json:"borrow_date"`
 	DueDate    time.Time `json:"due_date"`
 	ReturnDate time.Time `json:"return_date"`
+	RenewCount int       `json:"renew_count"`
 }
 
-// BorrowRecords 全局借阅记录列表
-var (
-	BorrowRecords  []*BorrowRecord
-	NextBorrowID   = 1
-	borrowMutex    sync.Mutex
+// BorrowStatus 借阅记录查询状态
+type BorrowStatus string
+
+const (
+	BorrowActive   BorrowStatus = "active"   // 未归还
+	BorrowReturned BorrowStatus = "returned" // 已归还
+	BorrowOverdue  BorrowStatus = "overdue"  // 未归还且已逾期
 )
 
-// CreateBorrowRecord 创建新借阅记录
-func CreateBorrowRecord(userID, bookID int, borrowDate, dueDate time.Time) (*BorrowRecord, error) {
+// BorrowFilter 借阅记录查询条件，零值字段表示不限制
+type BorrowFilter struct {
+	UserID int
+	BookID int
+	Status BorrowStatus
+	From   time.Time // 借阅日期不早于
+	To     time.Time // 借阅日期早于
+}
+
+// Validate 校验查询条件
+func (f BorrowFilter) Validate() error {
+	switch f.Status {
+	case "", BorrowActive, BorrowReturned, BorrowOverdue:
+	default:
+		return errors.New("无效的借阅状态")
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return errors.New("开始日期必须早于结束日期")
+	}
+	return nil
+}
+
+// Match 判断借阅记录是否满足查询条件
+func (f BorrowFilter) Match(record *BorrowRecord, now time.Time) bool {
+	if f.UserID != 0 && record.UserID != f.UserID {
+		return false
+	}
+	if f.BookID != 0 && record.BookID != f.BookID {
+		return false
+	}
+	if !f.From.IsZero() && record.BorrowDate.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !record.BorrowDate.Before(f.To) {
+		return false
+	}
+
+	switch f.Status {
+	case BorrowActive:
+		return record.ReturnDate.IsZero()
+	case BorrowReturned:
+		return !record.ReturnDate.IsZero()
+	case BorrowOverdue:
+		return record.ReturnDate.IsZero() && now.After(record.DueDate)
+	}
+	return true
+}
+
+// borrowMutex 保证借阅校验与写入的原子性
+var borrowMutex sync.Mutex
+
+// CreateBorrowRecord 创建新借阅记录，自动分配一本在架副本，到期日按适用的流通规则计算
+func CreateBorrowRecord(userID, bookID int, borrowDate time.Time) (*BorrowRecord, error) {
 	borrowMutex.Lock()
 	defer borrowMutex.Unlock()
-	
+
+	return createBorrowRecord(userID, bookID, nil, borrowDate)
+}
+
+// CreateBorrowRecordByBarcode 按副本条码办理借阅
+func CreateBorrowRecordByBarcode(userID int, barcode string, borrowDate time.Time) (*BorrowRecord, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+
+	item, err := GetCopyByBarcode(barcode)
+	if err != nil {
+		return nil, errors.New("条码不存在")
+	}
+	if item.Status != CopyAvailable {
+		return nil, errors.New("该副本当前不可借（" + item.StatusText() + "）")
+	}
+
+	return createBorrowRecord(userID, item.BookID, item, borrowDate)
+}
+
+// createBorrowRecord 校验借阅条件并借出副本，item 为空时分配第一本在架副本，调用方需持有 borrowMutex
+func createBorrowRecord(userID, bookID int, item *BookCopy, borrowDate time.Time) (*BorrowRecord, error) {
 	// 验证用户是否存在
 	user, err := GetUserByID(userID)
-	if user == nil && err != nil {
+	if err != nil {
 		return nil, errors.New("用户不存在")
 	}
-	
+
+	// 未缴罚款超过限额时禁止借阅，无法查询罚款时也不借出
+	balance, err := GetFineBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+	if threshold := GetFineConfig().BlockThreshold; balance > threshold {
+		return nil, fmt.Errorf("未缴罚款%s元，超过%s元限额，请先缴纳罚款", FormatAmount(balance), FormatAmount(threshold))
+	}
+
 	// 验证图书是否存在
 	book, err := GetBookByID(bookID)
 	if err != nil {
 		return nil, errors.New("图书不存在")
 	}
-	
-	// 检查图书是否可借
-	if !book.IsAvailable() {
+
+	// 检查图书是否可借，用户的待取预约占用的库存可直接借出
+	hold := readyHoldFor(userID, bookID)
+	available := book.GetAvailableQuantity()
+	if hold != nil {
+		available++
+	}
+	if available <= 0 {
 		return nil, errors.New("该图书无可用库存")
 	}
-	
+
 	// 检查用户是否已借阅该图书
-	for _, record := range BorrowRecords {
-		if record.UserID == userID && record.BookID == bookID && record.ReturnDate.IsZero() {
+	active := GetActiveBorrowRecordsByUserID(userID)
+	for _, record := range active {
+		if record.BookID == bookID {
 			return nil, errors.New("您已借阅该图书，尚未归还")
 		}
 	}
-	
+
+	// 检查是否超过最多借阅数量
+	policy := ResolvePolicy(user.Role, book.Category)
+	if policy.MaxLoans > 0 && len(active) >= policy.MaxLoans {
+		return nil, fmt.Errorf("已达到最多借阅数量（%d本），请先归还部分图书", policy.MaxLoans)
+	}
+
+	// 分配在架副本
+	if item == nil {
+		for _, c := range GetCopiesByBookID(bookID) {
+			if c.Status == CopyAvailable {
+				item = c
+				break
+			}
+		}
+		if item == nil {
+			return nil, errors.New("该图书无可用库存")
+		}
+	}
+
 	// 创建借阅记录
 	record := &BorrowRecord{
-		ID:         NextBorrowID,
 		UserID:     userID,
 		BookID:     bookID,
+		CopyID:     item.ID,
 		BorrowDate: borrowDate,
-		DueDate:    dueDate,
+		DueDate:    borrowDate.AddDate(0, 0, policy.LoanDays),
 	}
-	
-	// 添加到列表并递增ID
-	BorrowRecords = append(BorrowRecords, record)
-	NextBorrowID++
-	
+
+	// 借阅记录和副本的借出状态一起保存
+	item.Status = CopyOnLoan
+	if err := GetRepositories().Borrows.Lend(record, item); err != nil {
+		item.Status = CopyAvailable
+		return nil, err
+	}
+
+	// 预约的图书已借出
+	if hold != nil {
+		holdMutex.Lock()
+		hold.Status = HoldFulfilled
+		if err := GetRepositories().Holds.Update(hold); err != nil {
+			log.Printf("更新预约状态失败: %v", err)
+		}
+		holdMutex.Unlock()
+	}
+
 	return record, nil
 }
 
@@ -72,109 +197,108 @@ func CreateBorrowRecord(userID, bookID int, borrowDate, dueDate time.Time) (*Bor
 func ReturnBook(recordID int) (*BorrowRecord, error) {
 	borrowMutex.Lock()
 	defer borrowMutex.Unlock()
-	
+
 	// 查找借阅记录
-	var record *BorrowRecord
-	for _, r := range BorrowRecords {
-		if r.ID == recordID {
-			record = r
-			break
-		}
-	}
-	
-	if record == nil {
-		return nil, errors.New("借阅记录不存在")
+	record, err := GetRepositories().Borrows.GetByID(recordID)
+	if err != nil {
+		return nil, err
 	}
-	
+
 	// 检查是否已归还
 	if !record.ReturnDate.IsZero() {
 		return nil, errors.New("该图书已归还")
 	}
-	
-	// 更新归还日期
+
+	// 借出的副本重新上架，已登记为遗失等状态的副本保持不变
+	var item *BookCopy
+	if record.CopyID != 0 {
+		c, err := GetCopyByID(record.CopyID)
+		if err != nil && !errors.Is(err, ErrCopyNotFound) {
+			return nil, err
+		}
+		if err == nil && c.Status == CopyOnLoan {
+			item = c
+		}
+	}
+
+	// 归还日期和副本状态一起保存
 	record.ReturnDate = time.Now()
-	
+	if item != nil {
+		item.Status = CopyAvailable
+	}
+	if err := GetRepositories().Borrows.Return(record, item); err != nil {
+		record.ReturnDate = time.Time{}
+		if item != nil {
+			item.Status = CopyOnLoan
+		}
+		return nil, err
+	}
+
+	// 逾期归还计入罚款
+	if _, err := accrueFine(record); err != nil {
+		log.Printf("计算逾期罚款失败: %v", err)
+	}
+
+	// 归还的图书优先留给预约队列
+	holdMutex.Lock()
+	promoteHolds(record.BookID, record.ReturnDate)
+	holdMutex.Unlock()
+
 	return record, nil
 }
 
 // GetBorrowRecordByID 根据ID获取借阅记录
 func GetBorrowRecordByID(id int) (*BorrowRecord, error) {
-	for _, record := range BorrowRecords {
-		if record.ID == id {
-			return record, nil
-		}
-	}
-	return nil, errors.New("借阅记录不存在")
+	return GetRepositories().Borrows.GetByID(id)
 }
 
 // GetAllBorrowRecords 获取所有借阅记录
 func GetAllBorrowRecords() []*BorrowRecord {
-	return BorrowRecords
+	return logBorrowErr(GetRepositories().Borrows.GetAll())
 }
 
 // GetBorrowRecordsByUserID 获取用户的所有借阅记录
 func GetBorrowRecordsByUserID(userID int) []*BorrowRecord {
-	var records []*BorrowRecord
-	for _, record := range BorrowRecords {
-		if record.UserID == userID {
-			records = append(records, record)
-		}
-	}
-	return records
+	return logBorrowErr(GetRepositories().Borrows.GetByUserID(userID))
 }
 
 // GetBorrowRecordsByBookID 获取图书的所有借阅记录
 func GetBorrowRecordsByBookID(bookID int) []*BorrowRecord {
-	var records []*BorrowRecord
-	for _, record := range BorrowRecords {
-		if record.BookID == bookID {
-			records = append(records, record)
-		}
-	}
-	return records
+	return logBorrowErr(GetRepositories().Borrows.GetByBookID(bookID))
 }
 
 // GetActiveBorrowRecordsByUserID 获取用户的未归还借阅记录
 func GetActiveBorrowRecordsByUserID(userID int) []*BorrowRecord {
-	var records []*BorrowRecord
-	for _, record := range BorrowRecords {
-		if record.UserID == userID && record.ReturnDate.IsZero() {
-			records = append(records, record)
-		}
-	}
-	return records
+	return logBorrowErr(GetRepositories().Borrows.GetActiveByUserID(userID))
 }
 
 // GetActiveBorrowRecordsByBookID 获取图书的未归还借阅记录
 func GetActiveBorrowRecordsByBookID(bookID int) []*BorrowRecord {
-	var records []*BorrowRecord
-	for _, record := range BorrowRecords {
-		if record.BookID == bookID && record.ReturnDate.IsZero() {
-			records = append(records, record)
-		}
-	}
-	return records
+	return logBorrowErr(GetRepositories().Borrows.GetActiveByBookID(bookID))
 }
 
 // GetAllActiveBorrowRecords 获取所有未归还的借阅记录
 func GetAllActiveBorrowRecords() []*BorrowRecord {
-	var records []*BorrowRecord
-	for _, record := range BorrowRecords {
-		if record.ReturnDate.IsZero() {
-			records = append(records, record)
-		}
-	}
-	return records
+	return logBorrowErr(GetRepositories().Borrows.GetAllActive())
 }
 
 // GetAllOverdueBorrowRecords 获取所有逾期的借阅记录
 func GetAllOverdueBorrowRecords() []*BorrowRecord {
-	var records []*BorrowRecord
-	now := time.Now()
-	for _, record := range BorrowRecords {
-		if record.ReturnDate.IsZero() && now.After(record.DueDate) {
-			records = append(records, record)
-		}
+	return logBorrowErr(GetRepositories().Borrows.GetAllOverdue(time.Now()))
+}
+
+// FindBorrowRecords 按条件查询借阅记录
+func FindBorrowRecords(filter BorrowFilter) ([]*BorrowRecord, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return GetRepositories().Borrows.Find(filter, time.Now())
+}
+
+// logBorrowErr 记录借阅查询错误并返回结果
+func logBorrowErr(records []*BorrowRecord, err error) []*BorrowRecord {
+	if err != nil {
+		log.Printf("查询借阅记录失败: %v", err)
 	}
 	return records
 }
@@ -211,71 +335,3 @@ func (r *BorrowRecord) OverdueDays() int {
 	}
 	return 0 // 未逾期或已归还且未逾期
 }
-
-// InitSampleBorrowRecords 初始化示例借阅记录
-func InitSampleBorrowRecords() {
-	// 清空借阅记录列表
-	borrowMutex.Lock()
-	defer borrowMutex.Unlock()
-	
-	BorrowRecords = nil
-	NextBorrowID = 1
-	
-	// 确保有足够的用户和图书
-	if len(Users) < 3 || len(Books) < 3 {
-		return
-	}
-	
-	// 设置时间
-	now := time.Now()
-	oneWeekAgo := now.AddDate(0, 0, -7)
-	twoWeeksAgo := now.AddDate(0, 0, -14)
-	inOneWeek := now.AddDate(0, 0, 7)
-	inTwoWeeks := now.AddDate(0, 0, 14)
-	overdueDate := now.AddDate(0, 0, -1) // 昨天到期
-	
-	// 管理员的借阅记录
-	record1 := &BorrowRecord{
-		ID:         NextBorrowID,
-		UserID:     1, // 管理员
-		BookID:     1, // 第一本书
-		BorrowDate: twoWeeksAgo,
-		DueDate:    twoWeeksAgo.AddDate(0, 0, 14),
-		ReturnDate: oneWeekAgo,
-	}
-	BorrowRecords = append(BorrowRecords, record1)
-	NextBorrowID++
-	
-	// 图书管理员的借阅记录
-	record2 := &BorrowRecord{
-		ID:         NextBorrowID,
-		UserID:     2, // 图书管理员
-		BookID:     2, // 第二本书
-		BorrowDate: oneWeekAgo,
-		DueDate:    inOneWeek,
-	}
-	BorrowRecords = append(BorrowRecords, record2)
-	NextBorrowID++
-	
-	// 读者的借阅记录 (未到期)
-	record3 := &BorrowRecord{
-		ID:         NextBorrowID,
-		UserID:     3, // 读者
-		BookID:     3, // 第三本书
-		BorrowDate: oneWeekAgo,
-		DueDate:    inTwoWeeks,
-	}
-	BorrowRecords = append(BorrowRecords, record3)
-	NextBorrowID++
-	
-	// 读者的借阅记录 (已逾期)
-	record4 := &BorrowRecord{
-		ID:         NextBorrowID,
-		UserID:     3, // 读者
-		BookID:     4, // 第四本书
-		BorrowDate: twoWeeksAgo,
-		DueDate:    overdueDate,
-	}
-	BorrowRecords = append(BorrowRecords, record4)
-	NextBorrowID++
-}
\ No newline at end of file
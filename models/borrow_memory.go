@@ -0,0 +1,251 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryBorrowRepository 基于内存切片的借阅记录仓库实现
+type MemoryBorrowRepository struct {
+	mu            sync.RWMutex
+	records       []*BorrowRecord
+	nextID        int
+	renewals      []*Renewal
+	nextRenewalID int
+}
+
+// NewMemoryBorrowRepository 创建内存借阅记录仓库
+func NewMemoryBorrowRepository() *MemoryBorrowRepository {
+	return &MemoryBorrowRepository{nextID: 1, nextRenewalID: 1}
+}
+
+// Create 添加借阅记录并分配ID
+func (r *MemoryBorrowRepository) Create(record *BorrowRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record.ID = r.nextID
+	r.records = append(r.records, record)
+	r.nextID++
+	return nil
+}
+
+// Lend 保存副本的借出状态并添加借阅记录，副本更新失败时不添加记录
+func (r *MemoryBorrowRepository) Lend(record *BorrowRecord, item *BookCopy) error {
+	if err := GetRepositories().Copies.Update(item); err != nil {
+		return err
+	}
+	return r.Create(record)
+}
+
+// Return 保存副本的在架状态并更新借阅记录的归还日期，item 为空时只更新借阅记录
+func (r *MemoryBorrowRepository) Return(record *BorrowRecord, item *BookCopy) error {
+	if item != nil {
+		if err := GetRepositories().Copies.Update(item); err != nil {
+			return err
+		}
+	}
+	return r.Update(record)
+}
+
+// Update 更新借阅记录
+func (r *MemoryBorrowRepository) Update(record *BorrowRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, rec := range r.records {
+		if rec.ID == record.ID {
+			r.records[i] = record
+			return nil
+		}
+	}
+	return ErrBorrowRecordNotFound
+}
+
+// GetByID 根据ID获取借阅记录
+func (r *MemoryBorrowRepository) GetByID(id int) (*BorrowRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, record := range r.records {
+		if record.ID == id {
+			return record, nil
+		}
+	}
+	return nil, ErrBorrowRecordNotFound
+}
+
+// GetAll 获取所有借阅记录
+func (r *MemoryBorrowRepository) GetAll() ([]*BorrowRecord, error) {
+	return r.filter(func(*BorrowRecord) bool { return true }), nil
+}
+
+// GetByUserID 获取用户的所有借阅记录
+func (r *MemoryBorrowRepository) GetByUserID(userID int) ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return record.UserID == userID
+	}), nil
+}
+
+// GetByBookID 获取图书的所有借阅记录
+func (r *MemoryBorrowRepository) GetByBookID(bookID int) ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return record.BookID == bookID
+	}), nil
+}
+
+// GetActiveByUserID 获取用户的未归还借阅记录
+func (r *MemoryBorrowRepository) GetActiveByUserID(userID int) ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return record.UserID == userID && record.ReturnDate.IsZero()
+	}), nil
+}
+
+// GetActiveByBookID 获取图书的未归还借阅记录
+func (r *MemoryBorrowRepository) GetActiveByBookID(bookID int) ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return record.BookID == bookID && record.ReturnDate.IsZero()
+	}), nil
+}
+
+// GetAllActive 获取所有未归还的借阅记录
+func (r *MemoryBorrowRepository) GetAllActive() ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return record.ReturnDate.IsZero()
+	}), nil
+}
+
+// GetAllOverdue 获取截至now已逾期的借阅记录
+func (r *MemoryBorrowRepository) GetAllOverdue(now time.Time) ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return record.ReturnDate.IsZero() && now.After(record.DueDate)
+	}), nil
+}
+
+// Find 按条件查询借阅记录
+func (r *MemoryBorrowRepository) Find(filter BorrowFilter, now time.Time) ([]*BorrowRecord, error) {
+	return r.filter(func(record *BorrowRecord) bool {
+		return filter.Match(record, now)
+	}), nil
+}
+
+// CountActiveByBookID 统计图书当前借出数量
+func (r *MemoryBorrowRepository) CountActiveByBookID(bookID int) (int, error) {
+	active, _ := r.GetActiveByBookID(bookID)
+	return len(active), nil
+}
+
+// Renew 更新借阅记录的到期日和续借次数，同时添加续借记录并分配ID
+func (r *MemoryBorrowRepository) Renew(record *BorrowRecord, renewal *Renewal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := false
+	for i, rec := range r.records {
+		if rec.ID == record.ID {
+			r.records[i] = record
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrBorrowRecordNotFound
+	}
+
+	renewal.ID = r.nextRenewalID
+	r.renewals = append(r.renewals, renewal)
+	r.nextRenewalID++
+	return nil
+}
+
+// GetRenewalsByRecordID 获取借阅记录的续借历史
+func (r *MemoryBorrowRepository) GetRenewalsByRecordID(recordID int) ([]*Renewal, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Renewal
+	for _, renewal := range r.renewals {
+		if renewal.RecordID == recordID {
+			result = append(result, renewal)
+		}
+	}
+	return result, nil
+}
+
+// filter 返回满足条件的借阅记录
+func (r *MemoryBorrowRepository) filter(match func(*BorrowRecord) bool) []*BorrowRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*BorrowRecord
+	for _, record := range r.records {
+		if match(record) {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+// InitSampleBorrowRecords 初始化示例借阅记录，
+// 引用的用户和图书需由 InitSampleUsers 与 InitSampleBooks 预先创建
+func (r *MemoryBorrowRepository) InitSampleBorrowRecords() {
+	// 清空借阅记录列表
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = nil
+	r.nextID = 1
+
+	// 设置时间
+	now := time.Now()
+	oneWeekAgo := now.AddDate(0, 0, -7)
+	twoWeeksAgo := now.AddDate(0, 0, -14)
+	inOneWeek := now.AddDate(0, 0, 7)
+	inTwoWeeks := now.AddDate(0, 0, 14)
+	overdueDate := now.AddDate(0, 0, -1) // 昨天到期
+
+	// 管理员的借阅记录
+	record1 := &BorrowRecord{
+		ID:         r.nextID,
+		UserID:     1, // 管理员
+		BookID:     1, // 第一本书
+		BorrowDate: twoWeeksAgo,
+		DueDate:    twoWeeksAgo.AddDate(0, 0, 14),
+		ReturnDate: oneWeekAgo,
+	}
+	r.records = append(r.records, record1)
+	r.nextID++
+
+	// 图书管理员的借阅记录
+	record2 := &BorrowRecord{
+		ID:         r.nextID,
+		UserID:     2, // 图书管理员
+		BookID:     2, // 第二本书
+		BorrowDate: oneWeekAgo,
+		DueDate:    inOneWeek,
+	}
+	r.records = append(r.records, record2)
+	r.nextID++
+
+	// 读者的借阅记录 (未到期)
+	record3 := &BorrowRecord{
+		ID:         r.nextID,
+		UserID:     3, // 读者
+		BookID:     3, // 第三本书
+		BorrowDate: oneWeekAgo,
+		DueDate:    inTwoWeeks,
+	}
+	r.records = append(r.records, record3)
+	r.nextID++
+
+	// 读者的借阅记录 (已逾期)
+	record4 := &BorrowRecord{
+		ID:         r.nextID,
+		UserID:     3, // 读者
+		BookID:     4, // 第四本书
+		BorrowDate: twoWeeksAgo,
+		DueDate:    overdueDate,
+	}
+	r.records = append(r.records, record4)
+	r.nextID++
+}
@@ -0,0 +1,295 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// borrowColumns 借阅记录表查询列
+const borrowColumns = "id, user_id, book_id, copy_id, borrow_date, due_date, return_date, renew_count"
+
+// MySQLBorrowRepository 基于MySQL的借阅记录仓库实现
+type MySQLBorrowRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLBorrowRepository 创建MySQL借阅记录仓库
+func NewMySQLBorrowRepository(db *sql.DB) *MySQLBorrowRepository {
+	return &MySQLBorrowRepository{db: db}
+}
+
+// Create 插入借阅记录并回填ID
+func (r *MySQLBorrowRepository) Create(record *BorrowRecord) error {
+	result, err := r.db.Exec(`
+		INSERT INTO borrow_records (user_id, book_id, copy_id, borrow_date, due_date, return_date, renew_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.UserID, record.BookID, nullInt(record.CopyID), record.BorrowDate, record.DueDate, nullTime(record.ReturnDate), record.RenewCount)
+	if err != nil {
+		return fmt.Errorf("创建借阅记录失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取借阅记录ID失败: %w", err)
+	}
+	record.ID = int(id)
+	return nil
+}
+
+// Lend 在同一事务中插入借阅记录并将副本标记为借出，回填借阅记录ID；
+// 副本已不在架（如被其他实例借出）时不借出
+func (r *MySQLBorrowRepository) Lend(record *BorrowRecord, item *BookCopy) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("借出图书失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO borrow_records (user_id, book_id, copy_id, borrow_date, due_date, return_date, renew_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		record.UserID, record.BookID, nullInt(record.CopyID), record.BorrowDate, record.DueDate, nullTime(record.ReturnDate), record.RenewCount)
+	if err != nil {
+		return fmt.Errorf("创建借阅记录失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取借阅记录ID失败: %w", err)
+	}
+
+	result, err = tx.Exec("UPDATE book_copies SET status = ? WHERE id = ? AND status = ?",
+		string(CopyOnLoan), item.ID, string(CopyAvailable))
+	if err != nil {
+		return fmt.Errorf("更新副本状态失败: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("更新副本状态失败: %w", err)
+	} else if n == 0 {
+		return errors.New("该副本当前不可借")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("借出图书失败: %w", err)
+	}
+	record.ID = int(id)
+	return nil
+}
+
+// Return 在同一事务中更新借阅记录的归还日期和副本状态，item 为空时只更新借阅记录
+func (r *MySQLBorrowRepository) Return(record *BorrowRecord, item *BookCopy) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("归还图书失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE borrow_records SET return_date = ? WHERE id = ?",
+		nullTime(record.ReturnDate), record.ID); err != nil {
+		return fmt.Errorf("更新借阅记录失败: %w", err)
+	}
+	if item != nil {
+		if _, err := tx.Exec("UPDATE book_copies SET status = ? WHERE id = ?", string(item.Status), item.ID); err != nil {
+			return fmt.Errorf("更新副本状态失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("归还图书失败: %w", err)
+	}
+	return nil
+}
+
+// Update 更新借阅记录
+func (r *MySQLBorrowRepository) Update(record *BorrowRecord) error {
+	_, err := r.db.Exec(`
+		UPDATE borrow_records SET user_id = ?, book_id = ?, copy_id = ?, borrow_date = ?, due_date = ?, return_date = ?, renew_count = ?
+		WHERE id = ?`,
+		record.UserID, record.BookID, nullInt(record.CopyID), record.BorrowDate, record.DueDate, nullTime(record.ReturnDate), record.RenewCount, record.ID)
+	if err != nil {
+		return fmt.Errorf("更新借阅记录失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取借阅记录
+func (r *MySQLBorrowRepository) GetByID(id int) (*BorrowRecord, error) {
+	return scanBorrowRecord(r.db.QueryRow("SELECT "+borrowColumns+" FROM borrow_records WHERE id = ?", id))
+}
+
+// GetAll 获取所有借阅记录
+func (r *MySQLBorrowRepository) GetAll() ([]*BorrowRecord, error) {
+	return r.query("SELECT " + borrowColumns + " FROM borrow_records ORDER BY id")
+}
+
+// GetByUserID 获取用户的所有借阅记录
+func (r *MySQLBorrowRepository) GetByUserID(userID int) ([]*BorrowRecord, error) {
+	return r.query("SELECT "+borrowColumns+" FROM borrow_records WHERE user_id = ? ORDER BY id", userID)
+}
+
+// GetByBookID 获取图书的所有借阅记录
+func (r *MySQLBorrowRepository) GetByBookID(bookID int) ([]*BorrowRecord, error) {
+	return r.query("SELECT "+borrowColumns+" FROM borrow_records WHERE book_id = ? ORDER BY id", bookID)
+}
+
+// GetActiveByUserID 获取用户的未归还借阅记录
+func (r *MySQLBorrowRepository) GetActiveByUserID(userID int) ([]*BorrowRecord, error) {
+	return r.query("SELECT "+borrowColumns+" FROM borrow_records WHERE user_id = ? AND return_date IS NULL ORDER BY id", userID)
+}
+
+// GetActiveByBookID 获取图书的未归还借阅记录
+func (r *MySQLBorrowRepository) GetActiveByBookID(bookID int) ([]*BorrowRecord, error) {
+	return r.query("SELECT "+borrowColumns+" FROM borrow_records WHERE book_id = ? AND return_date IS NULL ORDER BY id", bookID)
+}
+
+// GetAllActive 获取所有未归还的借阅记录
+func (r *MySQLBorrowRepository) GetAllActive() ([]*BorrowRecord, error) {
+	return r.query("SELECT " + borrowColumns + " FROM borrow_records WHERE return_date IS NULL ORDER BY id")
+}
+
+// GetAllOverdue 获取截至now已逾期的借阅记录
+func (r *MySQLBorrowRepository) GetAllOverdue(now time.Time) ([]*BorrowRecord, error) {
+	return r.query("SELECT "+borrowColumns+" FROM borrow_records WHERE return_date IS NULL AND due_date < ? ORDER BY id", now)
+}
+
+// Find 按条件查询借阅记录
+func (r *MySQLBorrowRepository) Find(filter BorrowFilter, now time.Time) ([]*BorrowRecord, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.UserID != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.BookID != 0 {
+		conditions = append(conditions, "book_id = ?")
+		args = append(args, filter.BookID)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "borrow_date >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "borrow_date < ?")
+		args = append(args, filter.To)
+	}
+
+	switch filter.Status {
+	case BorrowActive:
+		conditions = append(conditions, "return_date IS NULL")
+	case BorrowReturned:
+		conditions = append(conditions, "return_date IS NOT NULL")
+	case BorrowOverdue:
+		conditions = append(conditions, "return_date IS NULL AND due_date < ?")
+		args = append(args, now)
+	}
+
+	query := "SELECT " + borrowColumns + " FROM borrow_records"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return r.query(query+" ORDER BY id", args...)
+}
+
+// CountActiveByBookID 统计图书当前借出数量
+func (r *MySQLBorrowRepository) CountActiveByBookID(bookID int) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM borrow_records WHERE book_id = ? AND return_date IS NULL", bookID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("统计借阅记录失败: %w", err)
+	}
+	return count, nil
+}
+
+// Renew 在同一事务中更新借阅记录的到期日和续借次数并插入续借记录，回填续借记录ID
+func (r *MySQLBorrowRepository) Renew(record *BorrowRecord, renewal *Renewal) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("续借失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE borrow_records SET due_date = ?, renew_count = ? WHERE id = ?",
+		record.DueDate, record.RenewCount, record.ID); err != nil {
+		return fmt.Errorf("更新借阅记录失败: %w", err)
+	}
+	result, err := tx.Exec(`
+		INSERT INTO borrow_renewals (record_id, renewed_by, renewed_at, previous_due_date, new_due_date)
+		VALUES (?, ?, ?, ?, ?)`,
+		renewal.RecordID, renewal.RenewedBy, renewal.RenewedAt, renewal.PreviousDueDate, renewal.NewDueDate)
+	if err != nil {
+		return fmt.Errorf("创建续借记录失败: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取续借记录ID失败: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("续借失败: %w", err)
+	}
+	renewal.ID = int(id)
+	return nil
+}
+
+// GetRenewalsByRecordID 获取借阅记录的续借历史
+func (r *MySQLBorrowRepository) GetRenewalsByRecordID(recordID int) ([]*Renewal, error) {
+	rows, err := r.db.Query(`
+		SELECT id, record_id, renewed_by, renewed_at, previous_due_date, new_due_date
+		FROM borrow_renewals WHERE record_id = ? ORDER BY id`, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("查询续借记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var renewals []*Renewal
+	for rows.Next() {
+		renewal := &Renewal{}
+		if err := rows.Scan(&renewal.ID, &renewal.RecordID, &renewal.RenewedBy, &renewal.RenewedAt,
+			&renewal.PreviousDueDate, &renewal.NewDueDate); err != nil {
+			return nil, err
+		}
+		renewals = append(renewals, renewal)
+	}
+	return renewals, rows.Err()
+}
+
+// query 执行查询并扫描借阅记录列表
+func (r *MySQLBorrowRepository) query(query string, args ...interface{}) ([]*BorrowRecord, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询借阅记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*BorrowRecord
+	for rows.Next() {
+		record, err := scanBorrowRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// scanBorrowRecord 扫描一行借阅记录，NULL 归还日期映射为零值
+func scanBorrowRecord(row rowScanner) (*BorrowRecord, error) {
+	record := &BorrowRecord{}
+	var copyID sql.NullInt64
+	var returnDate sql.NullTime
+	err := row.Scan(&record.ID, &record.UserID, &record.BookID, &copyID, &record.BorrowDate, &record.DueDate, &returnDate, &record.RenewCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrBorrowRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	record.CopyID = int(copyID.Int64)
+	if returnDate.Valid {
+		record.ReturnDate = returnDate.Time
+	}
+	return record, nil
+}
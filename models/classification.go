@@ -0,0 +1,470 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 分类表错误
+var (
+	ErrClassificationNotFound = errors.New("分类不存在")
+	ErrClassificationInUse    = errors.New("分类正在使用中")
+)
+
+// Classification 分类表（中图法）中的一个节点
+type Classification struct {
+	ID       int               `json:"id"`
+	ParentID int               `json:"parent_id"` // 0 表示顶级类目
+	Code     string            `json:"code"`
+	Name     string            `json:"name"`
+	Depth    int               `json:"depth"`              // 层级，顶级为0，由分类树计算
+	Children []*Classification `json:"children,omitempty"` // 下级类目，由分类树填充
+}
+
+// Label 分类号与类名
+func (c *Classification) Label() string {
+	return c.Code + " " + c.Name
+}
+
+// CLCTopLevels 中国图书馆分类法的22个基本大类，用于初始化分类表
+var CLCTopLevels = []Classification{
+	{Code: "A", Name: "马克思主义、列宁主义、毛泽东思想、邓小平理论"},
+	{Code: "B", Name: "哲学、宗教"},
+	{Code: "C", Name: "社会科学总论"},
+	{Code: "D", Name: "政治、法律"},
+	{Code: "E", Name: "军事"},
+	{Code: "F", Name: "经济"},
+	{Code: "G", Name: "文化、科学、教育、体育"},
+	{Code: "H", Name: "语言、文字"},
+	{Code: "I", Name: "文学"},
+	{Code: "J", Name: "艺术"},
+	{Code: "K", Name: "历史、地理"},
+	{Code: "N", Name: "自然科学总论"},
+	{Code: "O", Name: "数理科学和化学"},
+	{Code: "P", Name: "天文学、地球科学"},
+	{Code: "Q", Name: "生物科学"},
+	{Code: "R", Name: "医药、卫生"},
+	{Code: "S", Name: "农业科学"},
+	{Code: "T", Name: "工业技术"},
+	{Code: "U", Name: "交通运输"},
+	{Code: "V", Name: "航空、航天"},
+	{Code: "X", Name: "环境科学、安全科学"},
+	{Code: "Z", Name: "综合性图书"},
+}
+
+// classCodePattern 分类号格式，如 I、I24、TP312、I247.5
+var classCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9./\-]{0,31}$`)
+
+// classificationMutex 保证分类表校验与写入的原子性，加锁顺序在 bookMutex 之后
+var classificationMutex sync.Mutex
+
+// ClassificationTree 内存中的分类树
+type ClassificationTree struct {
+	Roots  []*Classification
+	byID   map[int]*Classification
+	byCode map[string]*Classification
+}
+
+// buildClassificationTree 由节点列表构建分类树，同级按分类号排序
+func buildClassificationTree(nodes []*Classification) *ClassificationTree {
+	tree := &ClassificationTree{
+		byID:   make(map[int]*Classification, len(nodes)),
+		byCode: make(map[string]*Classification, len(nodes)),
+	}
+	for _, node := range nodes {
+		item := *node
+		item.Children = nil
+		tree.byID[item.ID] = &item
+		tree.byCode[item.Code] = &item
+	}
+
+	ids := make([]int, 0, len(tree.byID))
+	for id := range tree.byID {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return tree.byID[ids[i]].Code < tree.byID[ids[j]].Code })
+	for _, id := range ids {
+		node := tree.byID[id]
+		if parent := tree.byID[node.ParentID]; parent != nil {
+			parent.Children = append(parent.Children, node)
+		} else {
+			tree.Roots = append(tree.Roots, node)
+		}
+	}
+
+	var setDepth func(nodes []*Classification, depth int)
+	setDepth = func(nodes []*Classification, depth int) {
+		for _, node := range nodes {
+			node.Depth = depth
+			setDepth(node.Children, depth+1)
+		}
+	}
+	setDepth(tree.Roots, 0)
+	return tree
+}
+
+// GetClassificationTree 读取完整的分类树
+func GetClassificationTree() (*ClassificationTree, error) {
+	nodes, err := GetRepositories().Classifications.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return buildClassificationTree(nodes), nil
+}
+
+// Empty 分类表是否为空，为空时图书分类仍按自由文本处理
+func (t *ClassificationTree) Empty() bool {
+	return len(t.byID) == 0
+}
+
+// Get 根据ID获取节点
+func (t *ClassificationTree) Get(id int) *Classification {
+	return t.byID[id]
+}
+
+// GetByCode 根据分类号获取节点，不区分大小写
+func (t *ClassificationTree) GetByCode(code string) *Classification {
+	return t.byCode[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// Path 从顶级类目到该节点的路径
+func (t *ClassificationTree) Path(id int) []*Classification {
+	var path []*Classification
+	for node := t.byID[id]; node != nil; node = t.byID[node.ParentID] {
+		path = append([]*Classification{node}, path...)
+		if len(path) > len(t.byID) {
+			break
+		}
+	}
+	return path
+}
+
+// SubtreeIDs 节点及其全部下级的ID
+func (t *ClassificationTree) SubtreeIDs(id int) []int {
+	node := t.byID[id]
+	if node == nil {
+		return []int{}
+	}
+	ids := []int{node.ID}
+	for _, child := range node.Children {
+		ids = append(ids, t.SubtreeIDs(child.ID)...)
+	}
+	return ids
+}
+
+// Flatten 按先序遍历展开全部节点，用于下拉框和管理列表
+func (t *ClassificationTree) Flatten() []*Classification {
+	var result []*Classification
+	var walk func(nodes []*Classification)
+	walk = func(nodes []*Classification) {
+		for _, node := range nodes {
+			result = append(result, node)
+			walk(node.Children)
+		}
+	}
+	walk(t.Roots)
+	return result
+}
+
+// Resolve 按分类号或类名查找节点，类名有重复时要求使用分类号
+func (t *ClassificationTree) Resolve(category string) (*Classification, error) {
+	category = strings.TrimSpace(category)
+	if node := t.GetByCode(category); node != nil {
+		return node, nil
+	}
+	// 兼容"I24 小说"形式的输入
+	if code, _, ok := strings.Cut(category, " "); ok {
+		if node := t.GetByCode(code); node != nil {
+			return node, nil
+		}
+	}
+
+	var found *Classification
+	for _, node := range t.byID {
+		if node.Name == category {
+			if found != nil {
+				return nil, fmt.Errorf("分类名称\"%s\"不唯一，请使用分类号", category)
+			}
+			found = node
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w：%s，请从分类表中选择", ErrClassificationNotFound, category)
+	}
+	return found, nil
+}
+
+// SubtreeCounts 将各节点直接挂接的图书数量汇总为含下级的数量
+func (t *ClassificationTree) SubtreeCounts(direct map[int]int) map[int]int {
+	totals := make(map[int]int, len(t.byID))
+	var sum func(node *Classification) int
+	sum = func(node *Classification) int {
+		total := direct[node.ID]
+		for _, child := range node.Children {
+			total += sum(child)
+		}
+		totals[node.ID] = total
+		return total
+	}
+	for _, root := range t.Roots {
+		sum(root)
+	}
+	return totals
+}
+
+// resolveBookClassification 将图书表单中的分类解析为分类表节点，分类表为空时返回nil
+func resolveBookClassification(category string) (*Classification, error) {
+	tree, err := GetClassificationTree()
+	if err != nil {
+		return nil, err
+	}
+	if tree.Empty() {
+		return nil, nil
+	}
+	return tree.Resolve(category)
+}
+
+// CountBooksByClassification 统计满足条件的图书在各分类节点（含下级）的数量，忽略条件中的分类限定
+func CountBooksByClassification(query BookQuery, tree *ClassificationTree) (map[int]int, error) {
+	query.ClassIDs = nil
+	if err := query.resolveIDs(); err != nil {
+		return nil, err
+	}
+	counts, err := GetRepositories().Books.Facet(query, FacetClass)
+	if err != nil {
+		return nil, err
+	}
+	direct := make(map[int]int, len(counts))
+	for _, count := range counts {
+		if id, err := strconv.Atoi(count.Value); err == nil {
+			direct[id] = count.Count
+		}
+	}
+	return tree.SubtreeCounts(direct), nil
+}
+
+// validateClassification 校验节点字段，规范化分类号
+func validateClassification(c *Classification, tree *ClassificationTree) error {
+	c.Code = strings.ToUpper(strings.TrimSpace(c.Code))
+	c.Name = strings.TrimSpace(c.Name)
+	if !classCodePattern.MatchString(c.Code) {
+		return errors.New("分类号须以大写字母开头，只能包含字母、数字和 . / -，不超过32个字符")
+	}
+	if c.Name == "" || len([]rune(c.Name)) > 100 {
+		return errors.New("类名不能为空且不超过100个字符")
+	}
+	if existing := tree.GetByCode(c.Code); existing != nil && existing.ID != c.ID {
+		return fmt.Errorf("分类号 %s 已被\"%s\"使用", c.Code, existing.Name)
+	}
+	if c.ParentID != 0 {
+		if tree.Get(c.ParentID) == nil {
+			return errors.New("上级分类不存在")
+		}
+		// 不能移动到自身或自身的下级之下
+		for _, node := range tree.Path(c.ParentID) {
+			if node.ID == c.ID {
+				return errors.New("不能将分类移动到其自身或下级分类之下")
+			}
+		}
+	}
+	return nil
+}
+
+// CreateClassification 添加分类节点
+func CreateClassification(code, name string, parentID int) (*Classification, error) {
+	classificationMutex.Lock()
+	defer classificationMutex.Unlock()
+
+	tree, err := GetClassificationTree()
+	if err != nil {
+		return nil, err
+	}
+	c := &Classification{Code: code, Name: name, ParentID: parentID}
+	if err := validateClassification(c, tree); err != nil {
+		return nil, err
+	}
+	if err := GetRepositories().Classifications.Create(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// UpdateClassification 修改分类节点，改名后同步更新该节点下图书的分类名称和引用旧名称的流通规则
+func UpdateClassification(id int, code, name string, parentID int) (*Classification, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	classificationMutex.Lock()
+	defer classificationMutex.Unlock()
+
+	tree, err := GetClassificationTree()
+	if err != nil {
+		return nil, err
+	}
+	old := tree.Get(id)
+	if old == nil {
+		return nil, ErrClassificationNotFound
+	}
+	c := &Classification{ID: id, Code: code, Name: name, ParentID: parentID}
+	if err := validateClassification(c, tree); err != nil {
+		return nil, err
+	}
+	if err := GetRepositories().Classifications.Update(c); err != nil {
+		return nil, err
+	}
+
+	if c.Name != old.Name {
+		if err := renameClassifiedBooks(id, c.Name); err != nil {
+			return nil, err
+		}
+		if err := renamePolicyCategory(old.Name, c.Name); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// renameClassifiedBooks 更新挂接在节点上的图书的分类名称，调用方需持有 bookMutex
+func renameClassifiedBooks(id int, name string) error {
+	books, err := GetRepositories().Books.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, book := range books {
+		if book.ClassID != id {
+			continue
+		}
+		book.Category = name
+		if err := GetRepositories().Books.Update(book); err != nil {
+			return err
+		}
+		bookIndex.update(book)
+	}
+	return nil
+}
+
+// renamePolicyCategory 将引用旧分类名称的流通规则改为新名称
+func renamePolicyCategory(oldName, newName string) error {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	policies, err := GetRepositories().Policies.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, p := range policies {
+		if p.Category != oldName {
+			continue
+		}
+		p.Category = newName
+		if err := GetRepositories().Policies.Update(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteClassification 删除分类节点，有下级分类、挂接图书或被流通规则引用时拒绝删除
+func DeleteClassification(id int) error {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	classificationMutex.Lock()
+	defer classificationMutex.Unlock()
+
+	tree, err := GetClassificationTree()
+	if err != nil {
+		return err
+	}
+	node := tree.Get(id)
+	if node == nil {
+		return ErrClassificationNotFound
+	}
+	if len(node.Children) > 0 {
+		return fmt.Errorf("%w：还有 %d 个下级分类", ErrClassificationInUse, len(node.Children))
+	}
+
+	_, total, err := GetRepositories().Books.Find(BookQuery{ClassIDs: []int{id}}, nil, 1, 0)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return fmt.Errorf("%w：还有 %d 本图书属于该分类", ErrClassificationInUse, total)
+	}
+
+	policies, err := GetRepositories().Policies.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, p := range policies {
+		if p.Category == node.Name {
+			return fmt.Errorf("%w：流通规则（%s，%s）引用了该分类", ErrClassificationInUse, p.RoleText(), p.Category)
+		}
+	}
+
+	return GetRepositories().Classifications.Delete(id)
+}
+
+// SeedClassifications 分类表为空时写入中图法基本大类，并按类名为已有图书归类，返回写入的节点数量
+func SeedClassifications() (int, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	classificationMutex.Lock()
+	defer classificationMutex.Unlock()
+
+	nodes, err := GetRepositories().Classifications.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	if len(nodes) > 0 {
+		return 0, nil
+	}
+	for _, top := range CLCTopLevels {
+		c := top
+		if err := GetRepositories().Classifications.Create(&c); err != nil {
+			return 0, err
+		}
+	}
+
+	count, err := classifyStoredBooks()
+	if err != nil {
+		return len(CLCTopLevels), err
+	}
+	log.Printf("已按类名为 %d 本图书归类", count)
+	return len(CLCTopLevels), nil
+}
+
+// classifyStoredBooks 为尚未归类的图书按分类名称或分类号匹配节点，调用方需持有 bookMutex
+func classifyStoredBooks() (int, error) {
+	tree, err := GetClassificationTree()
+	if err != nil {
+		return 0, err
+	}
+	books, err := GetRepositories().Books.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, book := range books {
+		if book.ClassID != 0 {
+			continue
+		}
+		node, err := tree.Resolve(book.Category)
+		if err != nil {
+			continue
+		}
+		book.ClassID = node.ID
+		book.Category = node.Name
+		if err := GetRepositories().Books.Update(book); err != nil {
+			return count, err
+		}
+		bookIndex.update(book)
+		count++
+	}
+	return count, nil
+}
@@ -0,0 +1,64 @@
+package models
+
+import "sync"
+
+// MemoryClassificationRepository 基于内存切片的分类表仓库实现
+type MemoryClassificationRepository struct {
+	mu     sync.RWMutex
+	nodes  []*Classification
+	nextID int
+}
+
+// NewMemoryClassificationRepository 创建内存分类表仓库
+func NewMemoryClassificationRepository() *MemoryClassificationRepository {
+	return &MemoryClassificationRepository{nextID: 1}
+}
+
+// Create 添加节点并分配ID
+func (r *MemoryClassificationRepository) Create(c *Classification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.ID = r.nextID
+	r.nodes = append(r.nodes, c)
+	r.nextID++
+	return nil
+}
+
+// Update 更新节点
+func (r *MemoryClassificationRepository) Update(c *Classification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, node := range r.nodes {
+		if node.ID == c.ID {
+			r.nodes[i] = c
+			return nil
+		}
+	}
+	return ErrClassificationNotFound
+}
+
+// Delete 删除节点
+func (r *MemoryClassificationRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, node := range r.nodes {
+		if node.ID == id {
+			r.nodes = append(r.nodes[:i], r.nodes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrClassificationNotFound
+}
+
+// GetAll 获取全部节点
+func (r *MemoryClassificationRepository) GetAll() ([]*Classification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*Classification, len(r.nodes))
+	copy(result, r.nodes)
+	return result, nil
+}
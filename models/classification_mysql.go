@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MySQLClassificationRepository 基于MySQL的分类表仓库实现
+type MySQLClassificationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLClassificationRepository 创建MySQL分类表仓库
+func NewMySQLClassificationRepository(db *sql.DB) *MySQLClassificationRepository {
+	return &MySQLClassificationRepository{db: db}
+}
+
+// Create 插入节点并回填ID
+func (r *MySQLClassificationRepository) Create(c *Classification) error {
+	result, err := r.db.Exec("INSERT INTO classifications (parent_id, code, name) VALUES (?, ?, ?)",
+		c.ParentID, c.Code, c.Name)
+	if err != nil {
+		return fmt.Errorf("创建分类失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取分类ID失败: %w", err)
+	}
+	c.ID = int(id)
+	return nil
+}
+
+// Update 更新节点
+func (r *MySQLClassificationRepository) Update(c *Classification) error {
+	_, err := r.db.Exec("UPDATE classifications SET parent_id = ?, code = ?, name = ? WHERE id = ?",
+		c.ParentID, c.Code, c.Name, c.ID)
+	if err != nil {
+		return fmt.Errorf("更新分类失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除节点
+func (r *MySQLClassificationRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM classifications WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除分类失败: %w", err)
+	}
+	return checkAffected(result, ErrClassificationNotFound)
+}
+
+// GetAll 获取全部节点
+func (r *MySQLClassificationRepository) GetAll() ([]*Classification, error) {
+	rows, err := r.db.Query("SELECT id, parent_id, code, name FROM classifications ORDER BY code")
+	if err != nil {
+		return nil, fmt.Errorf("查询分类失败: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*Classification
+	for rows.Next() {
+		c := &Classification{}
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.Code, &c.Name); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, c)
+	}
+	return nodes, rows.Err()
+}
@@ -0,0 +1,476 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrContributorNotFound 责任者不存在
+var ErrContributorNotFound = errors.New("作者不存在")
+
+// ContributorRole 责任方式
+type ContributorRole string
+
+const (
+	CreditAuthor     ContributorRole = "author"     // 著
+	CreditTranslator ContributorRole = "translator" // 译
+	CreditEditor     ContributorRole = "editor"     // 编
+)
+
+// ContributorRoles 所有责任方式，按显示顺序排列
+var ContributorRoles = []ContributorRole{CreditAuthor, CreditTranslator, CreditEditor}
+
+// Text 责任方式文本
+func (r ContributorRole) Text() string {
+	switch r {
+	case CreditAuthor:
+		return "作者"
+	case CreditTranslator:
+		return "译者"
+	case CreditEditor:
+		return "编者"
+	}
+	return string(r)
+}
+
+// Valid 是否为支持的责任方式
+func (r ContributorRole) Valid() bool {
+	for _, role := range ContributorRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// order 责任方式的排序位置
+func (r ContributorRole) order() int {
+	for i, role := range ContributorRoles {
+		if r == role {
+			return i
+		}
+	}
+	return len(ContributorRoles)
+}
+
+// Contributor 作者、译者、编者等责任者
+type Contributor struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	Works     int       `json:"works"` // 关联图书数量，仅在列表查询时填充
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Credit 图书与责任者的关联，同一图书同一责任方式下按 Position 排列
+type Credit struct {
+	BookID        int             `json:"book_id"`
+	ContributorID int             `json:"contributor_id"`
+	Name          string          `json:"name"`
+	Role          ContributorRole `json:"role"`
+	Position      int             `json:"position"`
+}
+
+// RoleText 责任方式文本
+func (c *Credit) RoleText() string {
+	return c.Role.Text()
+}
+
+// ContributorWork 责任者的一部作品
+type ContributorWork struct {
+	Book *Book           `json:"book"`
+	Role ContributorRole `json:"role"`
+}
+
+// ContributorPage 责任者分页查询结果
+type ContributorPage struct {
+	Contributors []*Contributor `json:"contributors"`
+	Total        int            `json:"total"`
+	Page         int            `json:"page"`
+	Size         int            `json:"size"`
+	TotalPages   int            `json:"total_pages"`
+}
+
+// contributorMutex 保证按姓名查找或创建责任者的原子性，加锁顺序在 bookMutex 之后
+var contributorMutex sync.Mutex
+
+// contributorSeparators 多位责任者之间的分隔符
+var contributorSeparators = strings.NewReplacer("，", "、", ",", "、", "；", "、", ";", "、", "／", "、", "/", "、", " & ", "、")
+
+// ParseContributorNames 拆分"张三、李四"形式的责任者列表，去掉空白和重复项
+func ParseContributorNames(s string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(contributorSeparators.Replace(s), "、") {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// validateContributor 校验责任者字段
+func validateContributor(c *Contributor) error {
+	c.Name = strings.Join(strings.Fields(c.Name), " ")
+	if c.Name == "" {
+		return errors.New("姓名不能为空")
+	}
+	if len([]rune(c.Name)) > 100 {
+		return errors.New("姓名不能超过100个字符")
+	}
+	return nil
+}
+
+// findOrCreateContributor 按姓名查找责任者，不存在时创建，调用方需持有 contributorMutex
+func findOrCreateContributor(name string) (*Contributor, error) {
+	existing, err := GetRepositories().Contributors.GetByName(name)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrContributorNotFound) {
+		return nil, err
+	}
+
+	c := &Contributor{Name: name, CreatedAt: time.Now()}
+	if err := validateContributor(c); err != nil {
+		return nil, fmt.Errorf("%s：%w", name, err)
+	}
+	if err := GetRepositories().Contributors.Create(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// setCreditsLocked 替换图书某一责任方式下的责任者，不再关联任何图书且没有简介的责任者随之删除，
+// 调用方需持有 bookMutex
+func setCreditsLocked(bookID int, role ContributorRole, names []string) error {
+	contributorMutex.Lock()
+	defer contributorMutex.Unlock()
+
+	old, err := GetRepositories().Contributors.GetCreditsByBookID(bookID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		c, err := findOrCreateContributor(name)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, c.ID)
+	}
+	if err := GetRepositories().Contributors.SetCredits(bookID, role, ids); err != nil {
+		return err
+	}
+
+	for _, credit := range old {
+		if credit.Role == role {
+			pruneContributor(credit.ContributorID)
+		}
+	}
+	return nil
+}
+
+// pruneContributor 删除没有作品且没有简介的责任者，调用方需持有 contributorMutex
+func pruneContributor(id int) {
+	works, err := GetRepositories().Contributors.GetCreditsByContributorID(id)
+	if err != nil || len(works) > 0 {
+		return
+	}
+	c, err := GetRepositories().Contributors.GetByID(id)
+	if err != nil || c.Bio != "" {
+		return
+	}
+	if err := GetRepositories().Contributors.Delete(id); err != nil {
+		log.Printf("删除责任者失败: %v", err)
+	}
+}
+
+// validateAuthorNames 校验作者字段拆分出的每位作者，在写入图书前调用
+func validateAuthorNames(author string) error {
+	for _, name := range ParseContributorNames(author) {
+		if err := validateContributor(&Contributor{Name: name}); err != nil {
+			return fmt.Errorf("作者%s：%w", name, err)
+		}
+	}
+	return nil
+}
+
+// syncAuthorCreditsLocked 按 Book.Author 更新图书的作者关联，调用方需持有 bookMutex
+func syncAuthorCreditsLocked(book *Book) error {
+	err := setCreditsLocked(book.ID, CreditAuthor, ParseContributorNames(book.Author))
+	bookIndex.update(book)
+	if err != nil {
+		return fmt.Errorf("更新图书的作者关联失败: %w", err)
+	}
+	return nil
+}
+
+// ValidateBookCredits 在写入图书之前校验责任方式和责任者姓名
+func ValidateBookCredits(credits map[ContributorRole][]string) error {
+	for role, names := range credits {
+		if !role.Valid() {
+			return fmt.Errorf("不支持的责任方式: %s", role)
+		}
+		if role == CreditAuthor && len(names) == 0 {
+			return errors.New("至少需要一位作者")
+		}
+		for _, name := range names {
+			if err := validateContributor(&Contributor{Name: name}); err != nil {
+				return fmt.Errorf("%s：%w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SetBookCredits 按责任方式设置图书的责任者，未出现在 credits 中的责任方式保持不变；
+// 设置作者时同步更新 Book.Author
+func SetBookCredits(bookID int, credits map[ContributorRole][]string) error {
+	if err := ValidateBookCredits(credits); err != nil {
+		return err
+	}
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	book, err := GetRepositories().Books.GetByID(bookID)
+	if err != nil {
+		return err
+	}
+
+	if names, ok := credits[CreditAuthor]; ok {
+		if author := strings.Join(names, "、"); author != book.Author {
+			book.Author = author
+			if err := GetRepositories().Books.Update(book); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, role := range ContributorRoles {
+		names, ok := credits[role]
+		if !ok {
+			continue
+		}
+		if err := setCreditsLocked(bookID, role, names); err != nil {
+			return err
+		}
+	}
+	bookIndex.update(book)
+	return nil
+}
+
+// GetBookCredits 获取图书的责任者，按责任方式和顺序排列
+func GetBookCredits(bookID int) []*Credit {
+	credits, err := GetRepositories().Contributors.GetCreditsByBookID(bookID)
+	if err != nil {
+		log.Printf("获取图书责任者失败: %v", err)
+		return nil
+	}
+	sortCredits(credits)
+	return credits
+}
+
+// BookCreditNames 图书某一责任方式下的责任者姓名，用"、"连接
+func BookCreditNames(credits []*Credit, role ContributorRole) string {
+	var names []string
+	for _, credit := range credits {
+		if credit.Role == role {
+			names = append(names, credit.Name)
+		}
+	}
+	return strings.Join(names, "、")
+}
+
+// sortCredits 按责任方式和顺序排列
+func sortCredits(credits []*Credit) {
+	sort.SliceStable(credits, func(i, j int) bool {
+		if credits[i].Role != credits[j].Role {
+			return credits[i].Role.order() < credits[j].Role.order()
+		}
+		return credits[i].Position < credits[j].Position
+	})
+}
+
+// deleteBookCreditsLocked 在删除图书前读取其责任者关联，返回图书删除后
+// 移除关联并清理不再有作品的责任者的函数，调用方需持有 bookMutex
+func deleteBookCreditsLocked(bookID int) (func(), error) {
+	old, err := GetRepositories().Contributors.GetCreditsByBookID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		contributorMutex.Lock()
+		defer contributorMutex.Unlock()
+
+		if err := GetRepositories().Contributors.DeleteCreditsByBookID(bookID); err != nil {
+			log.Printf("删除图书 %d 的责任者关联失败: %v", bookID, err)
+		}
+		for _, credit := range old {
+			pruneContributor(credit.ContributorID)
+		}
+	}, nil
+}
+
+// GetContributorByID 根据ID获取责任者
+func GetContributorByID(id int) (*Contributor, error) {
+	return GetRepositories().Contributors.GetByID(id)
+}
+
+// FindContributors 按姓名关键词分页查询责任者，按作品数量降序排列
+func FindContributors(query string, page, size int) (*ContributorPage, error) {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	contributors, total, err := GetRepositories().Contributors.Find(strings.TrimSpace(query), size, (page-1)*size)
+	if err != nil {
+		return nil, err
+	}
+	return &ContributorPage{
+		Contributors: contributors,
+		Total:        total,
+		Page:         page,
+		Size:         size,
+		TotalPages:   (total + size - 1) / size,
+	}, nil
+}
+
+// GetContributorWorks 获取责任者的全部作品，按责任方式和出版年份排列
+func GetContributorWorks(id int) ([]*ContributorWork, error) {
+	credits, err := GetRepositories().Contributors.GetCreditsByContributorID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	works := make([]*ContributorWork, 0, len(credits))
+	for _, credit := range credits {
+		book, err := GetRepositories().Books.GetByID(credit.BookID)
+		if err != nil {
+			log.Printf("获取作品失败: %v", err)
+			continue
+		}
+		works = append(works, &ContributorWork{Book: book, Role: credit.Role})
+	}
+	sort.SliceStable(works, func(i, j int) bool {
+		if works[i].Role != works[j].Role {
+			return works[i].Role.order() < works[j].Role.order()
+		}
+		return works[i].Book.PublishedYear > works[j].Book.PublishedYear
+	})
+	return works, nil
+}
+
+// UpdateContributor 修改责任者的姓名和简介，改名后同步更新其作为作者的图书
+func UpdateContributor(id int, name, bio string) (*Contributor, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	contributorMutex.Lock()
+	defer contributorMutex.Unlock()
+
+	c, err := GetRepositories().Contributors.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	updated := *c
+	updated.Name = name
+	updated.Bio = strings.TrimSpace(bio)
+	if err := validateContributor(&updated); err != nil {
+		return nil, err
+	}
+	if existing, err := GetRepositories().Contributors.GetByName(updated.Name); err == nil && existing.ID != id {
+		return nil, fmt.Errorf("已存在同名的作者：%s", existing.Name)
+	}
+	if err := GetRepositories().Contributors.Update(&updated); err != nil {
+		return nil, err
+	}
+
+	if updated.Name != c.Name {
+		credits, err := GetRepositories().Contributors.GetCreditsByContributorID(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, credit := range credits {
+			book, err := GetRepositories().Books.GetByID(credit.BookID)
+			if err != nil {
+				continue
+			}
+			if credit.Role == CreditAuthor {
+				all, err := GetRepositories().Contributors.GetCreditsByBookID(book.ID)
+				if err != nil {
+					return nil, err
+				}
+				sortCredits(all)
+				book.Author = BookCreditNames(all, CreditAuthor)
+				if err := GetRepositories().Books.Update(book); err != nil {
+					return nil, err
+				}
+			}
+			bookIndex.update(book)
+		}
+	}
+	return &updated, nil
+}
+
+// BackfillContributors 为尚无作者关联的图书按 Book.Author 建立关联，返回处理的图书数量
+func BackfillContributors() (int, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	books, err := GetRepositories().Books.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	credits, err := GetRepositories().Contributors.GetAllCredits()
+	if err != nil {
+		return 0, err
+	}
+	linked := make(map[int]bool)
+	for _, credit := range credits {
+		if credit.Role == CreditAuthor {
+			linked[credit.BookID] = true
+		}
+	}
+
+	count := 0
+	for _, book := range books {
+		if linked[book.ID] {
+			continue
+		}
+		if err := setCreditsLocked(book.ID, CreditAuthor, ParseContributorNames(book.Author)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if count > 0 {
+		bookIndex.invalidate()
+	}
+	return count, nil
+}
+
+// creditSearchText 图书除作者外的责任者姓名，用于检索
+func creditSearchText(credits []*Credit) string {
+	var names []string
+	for _, credit := range credits {
+		if credit.Role != CreditAuthor {
+			names = append(names, credit.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,206 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryContributorRepository 基于内存切片的责任者仓库实现
+type MemoryContributorRepository struct {
+	mu           sync.RWMutex
+	contributors []*Contributor
+	credits      []*Credit
+	nextID       int
+}
+
+// NewMemoryContributorRepository 创建内存责任者仓库
+func NewMemoryContributorRepository() *MemoryContributorRepository {
+	return &MemoryContributorRepository{nextID: 1}
+}
+
+// Create 添加责任者并分配ID
+func (r *MemoryContributorRepository) Create(c *Contributor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.ID = r.nextID
+	r.contributors = append(r.contributors, c)
+	r.nextID++
+	return nil
+}
+
+// Update 更新责任者
+func (r *MemoryContributorRepository) Update(c *Contributor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.contributors {
+		if existing.ID == c.ID {
+			r.contributors[i] = c
+			for _, credit := range r.credits {
+				if credit.ContributorID == c.ID {
+					credit.Name = c.Name
+				}
+			}
+			return nil
+		}
+	}
+	return ErrContributorNotFound
+}
+
+// Delete 删除责任者及其关联
+func (r *MemoryContributorRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.contributors {
+		if c.ID == id {
+			r.contributors = append(r.contributors[:i], r.contributors[i+1:]...)
+			r.removeCredits(func(credit *Credit) bool { return credit.ContributorID == id })
+			return nil
+		}
+	}
+	return ErrContributorNotFound
+}
+
+// GetByID 根据ID获取责任者
+func (r *MemoryContributorRepository) GetByID(id int) (*Contributor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.contributors {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, ErrContributorNotFound
+}
+
+// GetByName 根据姓名获取责任者，不区分大小写
+func (r *MemoryContributorRepository) GetByName(name string) (*Contributor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.contributors {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+	return nil, ErrContributorNotFound
+}
+
+// Find 按姓名关键词分页查询，按作品数量降序、姓名升序排列
+func (r *MemoryContributorRepository) Find(query string, limit, offset int) ([]*Contributor, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	works := make(map[int]map[int]bool)
+	for _, credit := range r.credits {
+		if works[credit.ContributorID] == nil {
+			works[credit.ContributorID] = make(map[int]bool)
+		}
+		works[credit.ContributorID][credit.BookID] = true
+	}
+
+	query = strings.ToLower(query)
+	var matched []*Contributor
+	for _, c := range r.contributors {
+		if query != "" && !strings.Contains(strings.ToLower(c.Name), query) {
+			continue
+		}
+		item := *c
+		item.Works = len(works[c.ID])
+		matched = append(matched, &item)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Works != matched[j].Works {
+			return matched[i].Works > matched[j].Works
+		}
+		return matched[i].Name < matched[j].Name
+	})
+
+	total := len(matched)
+	if offset >= total {
+		return []*Contributor{}, total, nil
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return matched[offset:end], total, nil
+}
+
+// GetCreditsByBookID 获取图书的全部责任者关联
+func (r *MemoryContributorRepository) GetCreditsByBookID(bookID int) ([]*Credit, error) {
+	return r.filterCredits(func(credit *Credit) bool { return credit.BookID == bookID }), nil
+}
+
+// GetCreditsByContributorID 获取责任者的全部图书关联
+func (r *MemoryContributorRepository) GetCreditsByContributorID(contributorID int) ([]*Credit, error) {
+	return r.filterCredits(func(credit *Credit) bool { return credit.ContributorID == contributorID }), nil
+}
+
+// GetAllCredits 获取全部关联
+func (r *MemoryContributorRepository) GetAllCredits() ([]*Credit, error) {
+	return r.filterCredits(func(*Credit) bool { return true }), nil
+}
+
+// SetCredits 替换图书某一责任方式下的责任者，contributorIDs 的顺序即显示顺序
+func (r *MemoryContributorRepository) SetCredits(bookID int, role ContributorRole, contributorIDs []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeCredits(func(credit *Credit) bool { return credit.BookID == bookID && credit.Role == role })
+	for i, id := range contributorIDs {
+		name := ""
+		for _, c := range r.contributors {
+			if c.ID == id {
+				name = c.Name
+			}
+		}
+		r.credits = append(r.credits, &Credit{
+			BookID:        bookID,
+			ContributorID: id,
+			Name:          name,
+			Role:          role,
+			Position:      i + 1,
+		})
+	}
+	return nil
+}
+
+// DeleteCreditsByBookID 删除图书的全部责任者关联
+func (r *MemoryContributorRepository) DeleteCreditsByBookID(bookID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeCredits(func(credit *Credit) bool { return credit.BookID == bookID })
+	return nil
+}
+
+// filterCredits 返回满足条件的关联副本
+func (r *MemoryContributorRepository) filterCredits(match func(*Credit) bool) []*Credit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Credit
+	for _, credit := range r.credits {
+		if match(credit) {
+			item := *credit
+			result = append(result, &item)
+		}
+	}
+	return result
+}
+
+// removeCredits 删除满足条件的关联，调用方需持有写锁
+func (r *MemoryContributorRepository) removeCredits(match func(*Credit) bool) {
+	kept := r.credits[:0]
+	for _, credit := range r.credits {
+		if !match(credit) {
+			kept = append(kept, credit)
+		}
+	}
+	r.credits = kept
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// contributorColumns 责任者表查询列
+const contributorColumns = "id, name, bio, created_at"
+
+// creditQuery 关联查询，附带责任者姓名
+const creditQuery = `SELECT bc.book_id, bc.contributor_id, c.name, bc.role, bc.position
+	FROM book_contributors bc JOIN contributors c ON c.id = bc.contributor_id`
+
+// MySQLContributorRepository 基于MySQL的责任者仓库实现
+type MySQLContributorRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLContributorRepository 创建MySQL责任者仓库
+func NewMySQLContributorRepository(db *sql.DB) *MySQLContributorRepository {
+	return &MySQLContributorRepository{db: db}
+}
+
+// Create 插入责任者并回填ID
+func (r *MySQLContributorRepository) Create(c *Contributor) error {
+	result, err := r.db.Exec("INSERT INTO contributors (name, bio, created_at) VALUES (?, ?, ?)",
+		c.Name, c.Bio, c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("创建责任者失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取责任者ID失败: %w", err)
+	}
+	c.ID = int(id)
+	return nil
+}
+
+// Update 更新责任者
+func (r *MySQLContributorRepository) Update(c *Contributor) error {
+	_, err := r.db.Exec("UPDATE contributors SET name = ?, bio = ? WHERE id = ?", c.Name, c.Bio, c.ID)
+	if err != nil {
+		return fmt.Errorf("更新责任者失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除责任者，关联随外键级联删除
+func (r *MySQLContributorRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM contributors WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除责任者失败: %w", err)
+	}
+	return checkAffected(result, ErrContributorNotFound)
+}
+
+// GetByID 根据ID获取责任者
+func (r *MySQLContributorRepository) GetByID(id int) (*Contributor, error) {
+	return scanContributor(r.db.QueryRow("SELECT "+contributorColumns+" FROM contributors WHERE id = ?", id))
+}
+
+// GetByName 根据姓名获取责任者，按列排序规则不区分大小写
+func (r *MySQLContributorRepository) GetByName(name string) (*Contributor, error) {
+	return scanContributor(r.db.QueryRow("SELECT "+contributorColumns+" FROM contributors WHERE name = ?", name))
+}
+
+// Find 按姓名关键词分页查询，按作品数量降序、姓名升序排列
+func (r *MySQLContributorRepository) Find(query string, limit, offset int) ([]*Contributor, int, error) {
+	where := ""
+	var args []interface{}
+	if query != "" {
+		where = " WHERE c.name LIKE ?"
+		args = append(args, likePattern(query))
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM contributors c"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("统计责任者失败: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT c.id, c.name, c.bio, c.created_at, COUNT(DISTINCT bc.book_id) AS works
+		FROM contributors c LEFT JOIN book_contributors bc ON bc.contributor_id = c.id`+where+`
+		GROUP BY c.id ORDER BY works DESC, c.name LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询责任者失败: %w", err)
+	}
+	defer rows.Close()
+
+	contributors := []*Contributor{}
+	for rows.Next() {
+		c := &Contributor{}
+		if err := rows.Scan(&c.ID, &c.Name, &c.Bio, &c.CreatedAt, &c.Works); err != nil {
+			return nil, 0, err
+		}
+		contributors = append(contributors, c)
+	}
+	return contributors, total, rows.Err()
+}
+
+// GetCreditsByBookID 获取图书的全部责任者关联
+func (r *MySQLContributorRepository) GetCreditsByBookID(bookID int) ([]*Credit, error) {
+	return r.queryCredits(creditQuery+" WHERE bc.book_id = ? ORDER BY bc.role, bc.position", bookID)
+}
+
+// GetCreditsByContributorID 获取责任者的全部图书关联
+func (r *MySQLContributorRepository) GetCreditsByContributorID(contributorID int) ([]*Credit, error) {
+	return r.queryCredits(creditQuery+" WHERE bc.contributor_id = ? ORDER BY bc.book_id", contributorID)
+}
+
+// GetAllCredits 获取全部关联
+func (r *MySQLContributorRepository) GetAllCredits() ([]*Credit, error) {
+	return r.queryCredits(creditQuery + " ORDER BY bc.book_id, bc.role, bc.position")
+}
+
+// SetCredits 在事务中替换图书某一责任方式下的责任者，contributorIDs 的顺序即显示顺序
+func (r *MySQLContributorRepository) SetCredits(bookID int, role ContributorRole, contributorIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("更新图书责任者失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM book_contributors WHERE book_id = ? AND role = ?", bookID, string(role)); err != nil {
+		return fmt.Errorf("更新图书责任者失败: %w", err)
+	}
+	for i, id := range contributorIDs {
+		_, err := tx.Exec("INSERT INTO book_contributors (book_id, contributor_id, role, position) VALUES (?, ?, ?, ?)",
+			bookID, id, string(role), i+1)
+		if err != nil {
+			return fmt.Errorf("更新图书责任者失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("更新图书责任者失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteCreditsByBookID 删除图书的全部责任者关联
+func (r *MySQLContributorRepository) DeleteCreditsByBookID(bookID int) error {
+	if _, err := r.db.Exec("DELETE FROM book_contributors WHERE book_id = ?", bookID); err != nil {
+		return fmt.Errorf("删除图书责任者失败: %w", err)
+	}
+	return nil
+}
+
+// queryCredits 执行关联查询
+func (r *MySQLContributorRepository) queryCredits(query string, args ...interface{}) ([]*Credit, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询图书责任者失败: %w", err)
+	}
+	defer rows.Close()
+
+	var credits []*Credit
+	for rows.Next() {
+		credit := &Credit{}
+		var role string
+		if err := rows.Scan(&credit.BookID, &credit.ContributorID, &credit.Name, &role, &credit.Position); err != nil {
+			return nil, err
+		}
+		credit.Role = ContributorRole(role)
+		credits = append(credits, credit)
+	}
+	return credits, rows.Err()
+}
+
+// scanContributor 扫描一行责任者数据
+func scanContributor(row rowScanner) (*Contributor, error) {
+	c := &Contributor{}
+	err := row.Scan(&c.ID, &c.Name, &c.Bio, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrContributorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
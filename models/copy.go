@@ -0,0 +1,294 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// CopyStatus 副本流通状态
+type CopyStatus string
+
+const (
+	CopyAvailable CopyStatus = "available" // 在架可借
+	CopyOnLoan    CopyStatus = "on_loan"   // 已借出
+	CopyDamaged   CopyStatus = "damaged"   // 损坏待修
+	CopyLost      CopyStatus = "lost"      // 遗失
+	CopyWithdrawn CopyStatus = "withdrawn" // 已剔除
+)
+
+// CopyCondition 副本品相
+type CopyCondition string
+
+const (
+	ConditionGood CopyCondition = "good" // 完好
+	ConditionFair CopyCondition = "fair" // 一般
+	ConditionPoor CopyCondition = "poor" // 较差
+)
+
+// BookCopy 图书副本（单册实物）模型
+type BookCopy struct {
+	ID            int           `json:"id"`
+	BookID        int           `json:"book_id"`
+	Barcode       string        `json:"barcode"`
+	ShelfLocation string        `json:"shelf_location"`
+	Condition     CopyCondition `json:"condition"`
+	Status        CopyStatus    `json:"status"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// InCollection 副本是否仍属馆藏（计入图书总数）
+func (c *BookCopy) InCollection() bool {
+	return c.Status != CopyLost && c.Status != CopyWithdrawn
+}
+
+// StatusText 副本状态文本
+func (c *BookCopy) StatusText() string {
+	switch c.Status {
+	case CopyAvailable:
+		return "在架"
+	case CopyOnLoan:
+		return "借出"
+	case CopyDamaged:
+		return "损坏"
+	case CopyLost:
+		return "遗失"
+	case CopyWithdrawn:
+		return "剔除"
+	}
+	return string(c.Status)
+}
+
+// ConditionText 副本品相文本
+func (c *BookCopy) ConditionText() string {
+	switch c.Condition {
+	case ConditionGood:
+		return "完好"
+	case ConditionFair:
+		return "一般"
+	case ConditionPoor:
+		return "较差"
+	}
+	return string(c.Condition)
+}
+
+// validateCopy 校验副本的品相和状态
+func validateCopy(condition CopyCondition, status CopyStatus) error {
+	switch condition {
+	case ConditionGood, ConditionFair, ConditionPoor:
+	default:
+		return errors.New("无效的副本品相")
+	}
+
+	switch status {
+	case CopyAvailable, CopyOnLoan, CopyDamaged, CopyLost, CopyWithdrawn:
+	default:
+		return errors.New("无效的副本状态")
+	}
+
+	return nil
+}
+
+// AddBookCopy 为图书添加副本，条码为空时自动生成
+func AddBookCopy(bookID int, barcode, shelfLocation string, condition CopyCondition) (*BookCopy, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+
+	book, err := GetBookByID(bookID)
+	if err != nil {
+		return nil, errors.New("图书不存在")
+	}
+
+	item, err := createCopy(book, barcode, shelfLocation, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syncBookQuantity(book); err != nil {
+		return nil, err
+	}
+
+	// 新副本优先留给预约队列
+	holdMutex.Lock()
+	promoteHolds(bookID, time.Now())
+	holdMutex.Unlock()
+
+	return item, nil
+}
+
+// UpdateBookCopy 更新副本的架位、品相和状态，借出状态只能通过借阅和归还变更
+func UpdateBookCopy(id int, shelfLocation string, condition CopyCondition, status CopyStatus) (*BookCopy, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+
+	if err := validateCopy(condition, status); err != nil {
+		return nil, err
+	}
+
+	item, err := GetRepositories().Copies.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Status == CopyOnLoan && status != CopyOnLoan {
+		return nil, errors.New("该副本已借出，请先办理归还")
+	}
+	if item.Status != CopyOnLoan && status == CopyOnLoan {
+		return nil, errors.New("请通过借阅办理副本借出")
+	}
+
+	item.ShelfLocation = strings.TrimSpace(shelfLocation)
+	item.Condition = condition
+	item.Status = status
+	if err := GetRepositories().Copies.Update(item); err != nil {
+		return nil, err
+	}
+
+	book, err := GetBookByID(item.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := syncBookQuantity(book); err != nil {
+		return nil, err
+	}
+
+	// 副本重新上架后通知排队中的预约
+	if status == CopyAvailable {
+		holdMutex.Lock()
+		promoteHolds(item.BookID, time.Now())
+		holdMutex.Unlock()
+	}
+
+	return item, nil
+}
+
+// GetCopyByID 根据ID获取副本
+func GetCopyByID(id int) (*BookCopy, error) {
+	return GetRepositories().Copies.GetByID(id)
+}
+
+// GetCopyByBarcode 根据条码获取副本
+func GetCopyByBarcode(barcode string) (*BookCopy, error) {
+	return GetRepositories().Copies.GetByBarcode(strings.TrimSpace(barcode))
+}
+
+// GetCopiesByBookID 获取图书的所有副本
+func GetCopiesByBookID(bookID int) []*BookCopy {
+	copies, err := GetRepositories().Copies.GetByBookID(bookID)
+	if err != nil {
+		log.Printf("获取图书副本失败: %v", err)
+	}
+	return copies
+}
+
+// createCopy 创建副本，调用方需持有 borrowMutex
+func createCopy(book *Book, barcode, shelfLocation string, condition CopyCondition) (*BookCopy, error) {
+	if condition == "" {
+		condition = ConditionGood
+	}
+	if err := validateCopy(condition, CopyAvailable); err != nil {
+		return nil, err
+	}
+
+	barcode = strings.TrimSpace(barcode)
+	if barcode == "" {
+		generated, err := nextBarcode(book.ID)
+		if err != nil {
+			return nil, err
+		}
+		barcode = generated
+	} else if existing, _ := GetRepositories().Copies.GetByBarcode(barcode); existing != nil {
+		return nil, errors.New("条码已存在")
+	}
+
+	item := &BookCopy{
+		BookID:        book.ID,
+		Barcode:       barcode,
+		ShelfLocation: strings.TrimSpace(shelfLocation),
+		Condition:     condition,
+		Status:        CopyAvailable,
+		CreatedAt:     time.Now(),
+	}
+	if err := GetRepositories().Copies.Create(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// nextBarcode 按"B图书ID-序号"生成未被占用的条码
+func nextBarcode(bookID int) (string, error) {
+	copies, err := GetRepositories().Copies.GetByBookID(bookID)
+	if err != nil {
+		return "", err
+	}
+
+	for seq := len(copies) + 1; ; seq++ {
+		barcode := fmt.Sprintf("B%06d-%03d", bookID, seq)
+		if existing, _ := GetRepositories().Copies.GetByBarcode(barcode); existing == nil {
+			return barcode, nil
+		}
+	}
+}
+
+// reconcileCopies 按目标数量增加副本或剔除在架副本，调用方需持有 borrowMutex
+func reconcileCopies(book *Book, quantity int) error {
+	copies, err := GetRepositories().Copies.GetByBookID(book.ID)
+	if err != nil {
+		return err
+	}
+
+	var held, available []*BookCopy
+	for _, item := range copies {
+		if item.InCollection() {
+			held = append(held, item)
+		}
+		if item.Status == CopyAvailable {
+			available = append(available, item)
+		}
+	}
+
+	// 增加副本
+	for i := len(held); i < quantity; i++ {
+		if _, err := createCopy(book, "", "", ConditionGood); err != nil {
+			return err
+		}
+	}
+
+	// 减少副本时从最后添加的在架副本开始剔除
+	excess := len(held) - quantity
+	if excess > len(available) {
+		return fmt.Errorf("在架副本不足，最少只能减少到%d本", len(held)-len(available))
+	}
+	for i := 0; i < excess; i++ {
+		item := available[len(available)-1-i]
+		item.Status = CopyWithdrawn
+		if err := GetRepositories().Copies.Update(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncBookQuantity 将图书总数同步为馆藏副本数量
+func syncBookQuantity(book *Book) error {
+	copies, err := GetRepositories().Copies.GetByBookID(book.ID)
+	if err != nil {
+		return err
+	}
+
+	quantity := 0
+	for _, item := range copies {
+		if item.InCollection() {
+			quantity++
+		}
+	}
+	if quantity == book.Quantity {
+		return nil
+	}
+
+	book.Quantity = quantity
+	return GetRepositories().Books.Update(book)
+}
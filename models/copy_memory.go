@@ -0,0 +1,109 @@
+package models
+
+import "sync"
+
+// MemoryCopyRepository 基于内存切片的图书副本仓库实现
+type MemoryCopyRepository struct {
+	mu     sync.RWMutex
+	copies []*BookCopy
+	nextID int
+}
+
+// NewMemoryCopyRepository 创建内存图书副本仓库
+func NewMemoryCopyRepository() *MemoryCopyRepository {
+	return &MemoryCopyRepository{nextID: 1}
+}
+
+// Create 添加副本并分配ID
+func (r *MemoryCopyRepository) Create(item *BookCopy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	item.ID = r.nextID
+	r.copies = append(r.copies, item)
+	r.nextID++
+	return nil
+}
+
+// Update 更新副本
+func (r *MemoryCopyRepository) Update(item *BookCopy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.copies {
+		if c.ID == item.ID {
+			r.copies[i] = item
+			return nil
+		}
+	}
+	return ErrCopyNotFound
+}
+
+// DeleteByBookID 删除图书的所有副本
+func (r *MemoryCopyRepository) DeleteByBookID(bookID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.copies[:0]
+	for _, c := range r.copies {
+		if c.BookID != bookID {
+			kept = append(kept, c)
+		}
+	}
+	r.copies = kept
+	return nil
+}
+
+// GetByID 根据ID获取副本
+func (r *MemoryCopyRepository) GetByID(id int) (*BookCopy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.copies {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return nil, ErrCopyNotFound
+}
+
+// GetByBarcode 根据条码获取副本
+func (r *MemoryCopyRepository) GetByBarcode(barcode string) (*BookCopy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, c := range r.copies {
+		if c.Barcode == barcode {
+			return c, nil
+		}
+	}
+	return nil, ErrCopyNotFound
+}
+
+// GetByBookID 获取图书的所有副本，按添加顺序排列
+func (r *MemoryCopyRepository) GetByBookID(bookID int) ([]*BookCopy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*BookCopy
+	for _, c := range r.copies {
+		if c.BookID == bookID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// CountByStatus 统计图书处于指定状态的副本数量
+func (r *MemoryCopyRepository) CountByStatus(bookID int, status CopyStatus) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, c := range r.copies {
+		if c.BookID == bookID && c.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
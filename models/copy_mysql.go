@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// copyColumns 图书副本表查询列
+const copyColumns = "id, book_id, barcode, shelf_location, `condition`, status, created_at"
+
+// MySQLCopyRepository 基于MySQL的图书副本仓库实现
+type MySQLCopyRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLCopyRepository 创建MySQL图书副本仓库
+func NewMySQLCopyRepository(db *sql.DB) *MySQLCopyRepository {
+	return &MySQLCopyRepository{db: db}
+}
+
+// Create 插入副本并回填ID
+func (r *MySQLCopyRepository) Create(item *BookCopy) error {
+	result, err := r.db.Exec(`
+		INSERT INTO book_copies (book_id, barcode, shelf_location, `+"`condition`"+`, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		item.BookID, item.Barcode, item.ShelfLocation, string(item.Condition), string(item.Status), item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("创建图书副本失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取图书副本ID失败: %w", err)
+	}
+	item.ID = int(id)
+	return nil
+}
+
+// Update 更新副本
+func (r *MySQLCopyRepository) Update(item *BookCopy) error {
+	_, err := r.db.Exec(`
+		UPDATE book_copies SET barcode = ?, shelf_location = ?, `+"`condition`"+` = ?, status = ?
+		WHERE id = ?`,
+		item.Barcode, item.ShelfLocation, string(item.Condition), string(item.Status), item.ID)
+	if err != nil {
+		return fmt.Errorf("更新图书副本失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteByBookID 删除图书的所有副本
+func (r *MySQLCopyRepository) DeleteByBookID(bookID int) error {
+	if _, err := r.db.Exec("DELETE FROM book_copies WHERE book_id = ?", bookID); err != nil {
+		return fmt.Errorf("删除图书副本失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取副本
+func (r *MySQLCopyRepository) GetByID(id int) (*BookCopy, error) {
+	return scanCopy(r.db.QueryRow("SELECT "+copyColumns+" FROM book_copies WHERE id = ?", id))
+}
+
+// GetByBarcode 根据条码获取副本
+func (r *MySQLCopyRepository) GetByBarcode(barcode string) (*BookCopy, error) {
+	return scanCopy(r.db.QueryRow("SELECT "+copyColumns+" FROM book_copies WHERE barcode = ?", barcode))
+}
+
+// GetByBookID 获取图书的所有副本，按添加顺序排列
+func (r *MySQLCopyRepository) GetByBookID(bookID int) ([]*BookCopy, error) {
+	rows, err := r.db.Query("SELECT "+copyColumns+" FROM book_copies WHERE book_id = ? ORDER BY id", bookID)
+	if err != nil {
+		return nil, fmt.Errorf("查询图书副本失败: %w", err)
+	}
+	defer rows.Close()
+
+	var copies []*BookCopy
+	for rows.Next() {
+		item, err := scanCopy(rows)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, item)
+	}
+	return copies, rows.Err()
+}
+
+// CountByStatus 统计图书处于指定状态的副本数量
+func (r *MySQLCopyRepository) CountByStatus(bookID int, status CopyStatus) (int, error) {
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM book_copies WHERE book_id = ? AND status = ?",
+		bookID, string(status)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("统计图书副本失败: %w", err)
+	}
+	return count, nil
+}
+
+// scanCopy 扫描一行副本数据
+func scanCopy(row rowScanner) (*BookCopy, error) {
+	item := &BookCopy{}
+	var condition, status string
+	err := row.Scan(&item.ID, &item.BookID, &item.Barcode, &item.ShelfLocation, &condition, &status, &item.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCopyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	item.Condition = CopyCondition(condition)
+	item.Status = CopyStatus(status)
+	return item, nil
+}
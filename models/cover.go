@@ -0,0 +1,275 @@
+package models
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // 注册GIF解码
+	"image/jpeg"
+	_ "image/png" // 注册PNG解码
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// ErrCoverNotFound 封面文件不存在
+var ErrCoverNotFound = errors.New("封面不存在")
+
+// 封面尺寸，按比例缩放到不超过对应宽高
+const (
+	CoverThumb  = "thumb"  // 列表缩略图
+	CoverDetail = "detail" // 详情页大图
+)
+
+// coverSizes 各尺寸的最大宽高
+var coverSizes = map[string][2]int{
+	CoverThumb:  {160, 224},
+	CoverDetail: {480, 672},
+}
+
+// 上传限制
+const (
+	MaxCoverFileSize = 5 << 20
+	maxCoverPixels   = 40 << 20
+)
+
+// noCoverURL 没有封面时使用的占位图
+const noCoverURL = "/static/img/no-cover.svg"
+
+// coverFilePattern 封面文件名：<图书ID>-<版本>-<尺寸>.jpg
+var coverFilePattern = regexp.MustCompile(`^\d+-[0-9a-f]+-(thumb|detail)\.jpg$`)
+
+// CoverStorage 封面文件存储接口
+type CoverStorage interface {
+	// Save 保存文件，同名文件直接覆盖
+	Save(name string, data []byte) error
+	// Open 打开文件并返回修改时间，不存在时返回 ErrCoverNotFound
+	Open(name string) (io.ReadSeekCloser, time.Time, error)
+	// Delete 删除文件，文件不存在时不报错
+	Delete(name string) error
+}
+
+// DiskCoverStorage 保存在本地目录的封面存储
+type DiskCoverStorage struct {
+	Dir string
+}
+
+// NewDiskCoverStorage 创建本地目录封面存储
+func NewDiskCoverStorage(dir string) *DiskCoverStorage {
+	return &DiskCoverStorage{Dir: dir}
+}
+
+// Save 先写入临时文件再重命名，避免读到写了一半的文件
+func (s *DiskCoverStorage) Save(name string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("创建封面目录失败: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("保存封面失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("保存封面失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("保存封面失败: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("保存封面失败: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.Dir, name)); err != nil {
+		return fmt.Errorf("保存封面失败: %w", err)
+	}
+	return nil
+}
+
+// Open 打开封面文件
+func (s *DiskCoverStorage) Open(name string) (io.ReadSeekCloser, time.Time, error) {
+	f, err := os.Open(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, ErrCoverNotFound
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, time.Time{}, err
+	}
+	return f, info.ModTime(), nil
+}
+
+// Delete 删除封面文件
+func (s *DiskCoverStorage) Delete(name string) error {
+	err := os.Remove(filepath.Join(s.Dir, name))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+var (
+	coverStorage      CoverStorage = NewDiskCoverStorage(filepath.Join("uploads", "covers"))
+	coverStorageMutex sync.RWMutex
+)
+
+// SetCoverStorage 设置封面存储（程序启动时调用）
+func SetCoverStorage(s CoverStorage) {
+	coverStorageMutex.Lock()
+	defer coverStorageMutex.Unlock()
+	coverStorage = s
+}
+
+// GetCoverStorage 获取封面存储
+func GetCoverStorage() CoverStorage {
+	coverStorageMutex.RLock()
+	defer coverStorageMutex.RUnlock()
+	return coverStorage
+}
+
+// CoverImages 处理后的各尺寸封面，均为JPEG
+type CoverImages map[string][]byte
+
+// ProcessCoverImage 解码上传的JPEG、PNG或GIF图片，生成缩略图和详情图
+func ProcessCoverImage(data []byte) (CoverImages, error) {
+	if len(data) > MaxCoverFileSize {
+		return nil, fmt.Errorf("封面图片不能超过%dMB", MaxCoverFileSize>>20)
+	}
+
+	// 先读取尺寸，拒绝解码后占用内存过大的图片
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("无法识别的图片格式，请上传JPEG、PNG或GIF图片")
+	}
+	if config.Width*config.Height > maxCoverPixels {
+		return nil, errors.New("封面图片尺寸过大")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("图片已损坏，无法读取")
+	}
+	flat := flattenImage(src)
+
+	images := make(CoverImages, len(coverSizes))
+	for size, box := range coverSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resizeToFit(flat, box[0], box[1]), &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("生成封面失败: %w", err)
+		}
+		images[size] = buf.Bytes()
+	}
+	return images, nil
+}
+
+// coverFileName 封面文件名
+func coverFileName(key, size string) string {
+	return key + "-" + size + ".jpg"
+}
+
+// IsCoverFileName 判断是否为合法的封面文件名，用于拒绝路径穿越等请求
+func IsCoverFileName(name string) bool {
+	return coverFilePattern.MatchString(name)
+}
+
+// deleteCoverFiles 删除某个版本的全部尺寸，失败时只记录日志
+func deleteCoverFiles(key string) {
+	if key == "" {
+		return
+	}
+	for size := range coverSizes {
+		if err := GetCoverStorage().Delete(coverFileName(key, size)); err != nil {
+			log.Printf("删除封面文件失败: %v", err)
+		}
+	}
+}
+
+// SetBookCover 保存图书的上传封面，替换原有的上传封面
+func SetBookCover(bookID int, images CoverImages) (*Book, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	book, err := GetRepositories().Books.GetByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 每次上传使用新的版本号，浏览器可以长期缓存封面
+	version := make([]byte, 4)
+	if _, err := rand.Read(version); err != nil {
+		return nil, fmt.Errorf("生成封面文件名失败: %w", err)
+	}
+	key := fmt.Sprintf("%d-%s", bookID, hex.EncodeToString(version))
+
+	for size, data := range images {
+		if err := GetCoverStorage().Save(coverFileName(key, size), data); err != nil {
+			deleteCoverFiles(key)
+			return nil, err
+		}
+	}
+
+	old := book.CoverImage
+	book.CoverImage = key
+	if err := GetRepositories().Books.Update(book); err != nil {
+		book.CoverImage = old
+		deleteCoverFiles(key)
+		return nil, err
+	}
+	deleteCoverFiles(old)
+	return book, nil
+}
+
+// RemoveBookCover 删除图书的上传封面，之后使用封面URL
+func RemoveBookCover(bookID int) error {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	book, err := GetRepositories().Books.GetByID(bookID)
+	if err != nil {
+		return err
+	}
+	if book.CoverImage == "" {
+		return nil
+	}
+
+	old := book.CoverImage
+	book.CoverImage = ""
+	if err := GetRepositories().Books.Update(book); err != nil {
+		book.CoverImage = old
+		return err
+	}
+	deleteCoverFiles(old)
+	return nil
+}
+
+// coverURL 返回指定尺寸的封面地址：优先使用上传的封面，其次是外部URL，都没有时为占位图
+func (b *Book) coverURL(size string) string {
+	if b.CoverImage != "" {
+		return "/covers/" + coverFileName(b.CoverImage, size)
+	}
+	if b.CoverURL != "" {
+		return b.CoverURL
+	}
+	return noCoverURL
+}
+
+// CoverThumbURL 列表中使用的封面地址
+func (b *Book) CoverThumbURL() string {
+	return b.coverURL(CoverThumb)
+}
+
+// CoverDetailURL 详情页使用的封面地址
+func (b *Book) CoverDetailURL() string {
+	return b.coverURL(CoverDetail)
+}
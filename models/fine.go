@@ -0,0 +1,228 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FineEntryType 罚款台账条目类型
+type FineEntryType string
+
+const (
+	FineCharge  FineEntryType = "charge"  // 逾期罚款
+	FinePayment FineEntryType = "payment" // 缴纳
+	FineWaiver  FineEntryType = "waiver"  // 减免
+)
+
+// FineEntry 罚款台账条目，金额单位为分
+type FineEntry struct {
+	ID        int           `json:"id"`
+	UserID    int           `json:"user_id"`
+	RecordID  int           `json:"record_id"`
+	Type      FineEntryType `json:"type"`
+	Amount    int           `json:"amount"`
+	Note      string        `json:"note"`
+	CreatedBy int           `json:"created_by"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// TypeText 条目类型文本
+func (e *FineEntry) TypeText() string {
+	switch e.Type {
+	case FineCharge:
+		return "罚款"
+	case FinePayment:
+		return "缴纳"
+	case FineWaiver:
+		return "减免"
+	}
+	return string(e.Type)
+}
+
+// FineConfig 罚款规则，费率和上限由流通规则按角色和分类设定
+type FineConfig struct {
+	BlockThreshold int // 未缴罚款超过该金额（分）时禁止借阅
+}
+
+var (
+	fineConfig      = FineConfig{BlockThreshold: 1000}
+	fineConfigMutex sync.RWMutex
+)
+
+// fineMutex 保证罚款余额校验与写入的原子性，需要同时加锁时先取 borrowMutex
+var fineMutex sync.Mutex
+
+// SetFineConfig 设置罚款规则
+func SetFineConfig(cfg FineConfig) {
+	fineConfigMutex.Lock()
+	defer fineConfigMutex.Unlock()
+	fineConfig = cfg
+}
+
+// GetFineConfig 获取罚款规则
+func GetFineConfig() FineConfig {
+	fineConfigMutex.RLock()
+	defer fineConfigMutex.RUnlock()
+	return fineConfig
+}
+
+// ParseAmount 将以元为单位的金额字符串（如"1.50"）转换为分
+func ParseAmount(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	yuan, fen, hasFen := strings.Cut(s, ".")
+	if yuan == "" || len(fen) > 2 || (hasFen && fen == "") {
+		return 0, fmt.Errorf("无效的金额: %q", s)
+	}
+	for len(fen) < 2 {
+		fen += "0"
+	}
+
+	y, err := strconv.Atoi(yuan)
+	if err != nil || y < 0 {
+		return 0, fmt.Errorf("无效的金额: %q", s)
+	}
+	f, err := strconv.Atoi(fen)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("无效的金额: %q", s)
+	}
+	return y*100 + f, nil
+}
+
+// FormatAmount 将以分为单位的金额格式化为元
+func FormatAmount(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// CalculateFine 按流通规则计算借阅记录的逾期罚款
+func CalculateFine(record *BorrowRecord, policy *CirculationPolicy) int {
+	days := record.OverdueDays()
+	if days <= 0 {
+		return 0
+	}
+
+	amount := days * policy.FineDailyRate
+	if policy.FineMax > 0 && amount > policy.FineMax {
+		amount = policy.FineMax
+	}
+	return amount
+}
+
+// accrueFine 为逾期归还的借阅记录计入罚款
+func accrueFine(record *BorrowRecord) (*FineEntry, error) {
+	book, err := GetBookByID(record.BookID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := policyFor(record.UserID, record.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := CalculateFine(record, policy)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	fineMutex.Lock()
+	defer fineMutex.Unlock()
+
+	entry := &FineEntry{
+		UserID:    record.UserID,
+		RecordID:  record.ID,
+		Type:      FineCharge,
+		Amount:    amount,
+		Note:      fmt.Sprintf("《%s》逾期%d天", book.Title, record.OverdueDays()),
+		CreatedAt: record.ReturnDate,
+	}
+	if err := GetRepositories().Fines.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// PayFine 记录读者缴纳罚款，operatorID 为经办人的用户ID
+func PayFine(userID, amount, operatorID int, note string) (*FineEntry, error) {
+	return creditFine(userID, FinePayment, amount, operatorID, note)
+}
+
+// WaiveFine 减免读者罚款，operatorID 为经办人的用户ID
+func WaiveFine(userID, amount, operatorID int, note string) (*FineEntry, error) {
+	return creditFine(userID, FineWaiver, amount, operatorID, note)
+}
+
+// creditFine 记录冲减罚款的台账条目
+func creditFine(userID int, entryType FineEntryType, amount, operatorID int, note string) (*FineEntry, error) {
+	fineMutex.Lock()
+	defer fineMutex.Unlock()
+
+	if _, err := GetUserByID(userID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	if amount <= 0 {
+		return nil, errors.New("金额必须大于0")
+	}
+
+	balance, err := GetRepositories().Fines.GetBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+	if amount > balance {
+		return nil, fmt.Errorf("金额超过未缴罚款（%s元）", FormatAmount(balance))
+	}
+
+	entry := &FineEntry{
+		UserID:    userID,
+		Type:      entryType,
+		Amount:    amount,
+		Note:      note,
+		CreatedBy: operatorID,
+		CreatedAt: time.Now(),
+	}
+	if err := GetRepositories().Fines.Create(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetFineBalance 获取用户的未缴罚款
+func GetFineBalance(userID int) (int, error) {
+	balance, err := GetRepositories().Fines.GetBalance(userID)
+	if err != nil {
+		return 0, fmt.Errorf("查询罚款余额失败: %w", err)
+	}
+	return balance, nil
+}
+
+// GetFineEntriesByUserID 获取用户的罚款台账
+func GetFineEntriesByUserID(userID int) []*FineEntry {
+	return logFineErr(GetRepositories().Fines.GetByUserID(userID))
+}
+
+// GetFineEntriesByRecordID 获取借阅记录产生的罚款
+func GetFineEntriesByRecordID(recordID int) []*FineEntry {
+	return logFineErr(GetRepositories().Fines.GetByRecordID(recordID))
+}
+
+// GetAllFineEntries 获取所有罚款台账条目
+func GetAllFineEntries() []*FineEntry {
+	return logFineErr(GetRepositories().Fines.GetAll())
+}
+
+// logFineErr 记录罚款查询错误并返回结果
+func logFineErr(entries []*FineEntry, err error) []*FineEntry {
+	if err != nil {
+		log.Printf("查询罚款台账失败: %v", err)
+	}
+	return entries
+}
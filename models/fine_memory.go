@@ -0,0 +1,74 @@
+package models
+
+import "sync"
+
+// MemoryFineRepository 基于内存切片的罚款台账仓库实现
+type MemoryFineRepository struct {
+	mu      sync.RWMutex
+	entries []*FineEntry
+	nextID  int
+}
+
+// NewMemoryFineRepository 创建内存罚款台账仓库
+func NewMemoryFineRepository() *MemoryFineRepository {
+	return &MemoryFineRepository{nextID: 1}
+}
+
+// Create 添加台账条目并分配ID
+func (r *MemoryFineRepository) Create(entry *FineEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.ID = r.nextID
+	r.entries = append(r.entries, entry)
+	r.nextID++
+	return nil
+}
+
+// GetAll 获取所有台账条目
+func (r *MemoryFineRepository) GetAll() ([]*FineEntry, error) {
+	return r.filter(func(*FineEntry) bool { return true }), nil
+}
+
+// GetByUserID 获取用户的台账条目
+func (r *MemoryFineRepository) GetByUserID(userID int) ([]*FineEntry, error) {
+	return r.filter(func(entry *FineEntry) bool {
+		return entry.UserID == userID
+	}), nil
+}
+
+// GetByRecordID 获取借阅记录产生的台账条目
+func (r *MemoryFineRepository) GetByRecordID(recordID int) ([]*FineEntry, error) {
+	return r.filter(func(entry *FineEntry) bool {
+		return entry.RecordID == recordID
+	}), nil
+}
+
+// GetBalance 计算用户的未缴罚款（罚款减去缴纳和减免）
+func (r *MemoryFineRepository) GetBalance(userID int) (int, error) {
+	balance := 0
+	for _, entry := range r.filter(func(entry *FineEntry) bool {
+		return entry.UserID == userID
+	}) {
+		if entry.Type == FineCharge {
+			balance += entry.Amount
+		} else {
+			balance -= entry.Amount
+		}
+	}
+	return balance, nil
+}
+
+// filter 返回满足条件的台账条目，保持创建顺序
+func (r *MemoryFineRepository) filter(match func(*FineEntry) bool) []*FineEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*FineEntry
+	for _, entry := range r.entries {
+		if match(entry) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// fineColumns 罚款台账表查询列
+const fineColumns = "id, user_id, record_id, type, amount, note, created_by, created_at"
+
+// MySQLFineRepository 基于MySQL的罚款台账仓库实现
+type MySQLFineRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLFineRepository 创建MySQL罚款台账仓库
+func NewMySQLFineRepository(db *sql.DB) *MySQLFineRepository {
+	return &MySQLFineRepository{db: db}
+}
+
+// Create 插入台账条目并回填ID
+func (r *MySQLFineRepository) Create(entry *FineEntry) error {
+	result, err := r.db.Exec(`
+		INSERT INTO fine_entries (user_id, record_id, type, amount, note, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.UserID, nullInt(entry.RecordID), string(entry.Type), entry.Amount,
+		entry.Note, nullInt(entry.CreatedBy), entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("创建罚款记录失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取罚款记录ID失败: %w", err)
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+// GetAll 获取所有台账条目
+func (r *MySQLFineRepository) GetAll() ([]*FineEntry, error) {
+	return r.query("SELECT " + fineColumns + " FROM fine_entries ORDER BY id")
+}
+
+// GetByUserID 获取用户的台账条目
+func (r *MySQLFineRepository) GetByUserID(userID int) ([]*FineEntry, error) {
+	return r.query("SELECT "+fineColumns+" FROM fine_entries WHERE user_id = ? ORDER BY id", userID)
+}
+
+// GetByRecordID 获取借阅记录产生的台账条目
+func (r *MySQLFineRepository) GetByRecordID(recordID int) ([]*FineEntry, error) {
+	return r.query("SELECT "+fineColumns+" FROM fine_entries WHERE record_id = ? ORDER BY id", recordID)
+}
+
+// GetBalance 计算用户的未缴罚款（罚款减去缴纳和减免）
+func (r *MySQLFineRepository) GetBalance(userID int) (int, error) {
+	var balance int
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN type = ? THEN amount ELSE -amount END), 0)
+		FROM fine_entries WHERE user_id = ?`,
+		string(FineCharge), userID).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("查询罚款余额失败: %w", err)
+	}
+	return balance, nil
+}
+
+// query 执行查询并扫描台账条目列表
+func (r *MySQLFineRepository) query(query string, args ...interface{}) ([]*FineEntry, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询罚款记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*FineEntry
+	for rows.Next() {
+		entry, err := scanFineEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// scanFineEntry 扫描一行台账数据
+func scanFineEntry(row rowScanner) (*FineEntry, error) {
+	entry := &FineEntry{}
+	var entryType string
+	var recordID, createdBy sql.NullInt64
+	err := row.Scan(&entry.ID, &entry.UserID, &recordID, &entryType, &entry.Amount,
+		&entry.Note, &createdBy, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	entry.Type = FineEntryType(entryType)
+	entry.RecordID = int(recordID.Int64)
+	entry.CreatedBy = int(createdBy.Int64)
+	return entry, nil
+}
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// HoldStatus 预约状态
+type HoldStatus string
+
+const (
+	HoldWaiting   HoldStatus = "waiting"   // 排队中
+	HoldReady     HoldStatus = "ready"     // 已到书，待取
+	HoldFulfilled HoldStatus = "fulfilled" // 已借出
+	HoldCancelled HoldStatus = "cancelled" // 已取消
+	HoldExpired   HoldStatus = "expired"   // 逾期未取
+)
+
+// HoldPickupDays 到书后保留的取书天数
+const HoldPickupDays = 3
+
+// Hold 图书预约模型
+type Hold struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"user_id"`
+	BookID         int        `json:"book_id"`
+	Status         HoldStatus `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ReadyAt        time.Time  `json:"ready_at"`
+	PickupDeadline time.Time  `json:"pickup_deadline"`
+}
+
+// holdMutex 保证预约队列操作的原子性，需要同时加锁时先取 borrowMutex
+var holdMutex sync.Mutex
+
+// IsActive 预约是否仍在队列中（排队或待取）
+func (h *Hold) IsActive() bool {
+	return h.Status == HoldWaiting || h.Status == HoldReady
+}
+
+// StatusText 预约状态文本
+func (h *Hold) StatusText() string {
+	switch h.Status {
+	case HoldWaiting:
+		return "排队中"
+	case HoldReady:
+		return "待取书"
+	case HoldFulfilled:
+		return "已借出"
+	case HoldCancelled:
+		return "已取消"
+	case HoldExpired:
+		return "已过期"
+	}
+	return string(h.Status)
+}
+
+// PlaceHold 读者预约暂无库存的图书
+func PlaceHold(userID, bookID int) (*Hold, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+	holdMutex.Lock()
+	defer holdMutex.Unlock()
+
+	// 验证用户是否存在
+	if _, err := GetUserByID(userID); err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 验证图书是否存在
+	book, err := GetBookByID(bookID)
+	if err != nil {
+		return nil, errors.New("图书不存在")
+	}
+
+	// 有库存时直接借阅即可
+	if book.IsAvailable() {
+		return nil, errors.New("该图书有可用库存，可直接借阅")
+	}
+
+	// 检查用户是否已借阅该图书
+	for _, record := range GetActiveBorrowRecordsByUserID(userID) {
+		if record.BookID == bookID {
+			return nil, errors.New("您已借阅该图书，尚未归还")
+		}
+	}
+
+	// 检查是否重复预约
+	active, err := GetRepositories().Holds.GetActiveByBookID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hold := range active {
+		if hold.UserID == userID {
+			return nil, errors.New("您已预约该图书")
+		}
+	}
+
+	hold := &Hold{
+		UserID:    userID,
+		BookID:    bookID,
+		Status:    HoldWaiting,
+		CreatedAt: time.Now(),
+	}
+	if err := GetRepositories().Holds.Create(hold); err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// CancelHold 取消预约，待取的预约取消后将图书让给队列中的下一位
+func CancelHold(holdID int) (*Hold, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+	holdMutex.Lock()
+	defer holdMutex.Unlock()
+
+	hold, err := GetRepositories().Holds.GetByID(holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hold.IsActive() {
+		return nil, errors.New("该预约已结束，无法取消")
+	}
+
+	wasReady := hold.Status == HoldReady
+	hold.Status = HoldCancelled
+	if err := GetRepositories().Holds.Update(hold); err != nil {
+		return nil, err
+	}
+
+	if wasReady {
+		promoteHolds(hold.BookID, time.Now())
+	}
+
+	return hold, nil
+}
+
+// ExpireHolds 将超过取书期限的预约标记为过期，并通知队列中的下一位
+func ExpireHolds(now time.Time) (int, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+	holdMutex.Lock()
+	defer holdMutex.Unlock()
+
+	expired, err := GetRepositories().Holds.GetReadyExpired(now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, hold := range expired {
+		hold.Status = HoldExpired
+		if err := GetRepositories().Holds.Update(hold); err != nil {
+			return 0, err
+		}
+		promoteHolds(hold.BookID, now)
+	}
+
+	return len(expired), nil
+}
+
+// GetHoldByID 根据ID获取预约
+func GetHoldByID(id int) (*Hold, error) {
+	return GetRepositories().Holds.GetByID(id)
+}
+
+// GetAllHolds 获取所有预约
+func GetAllHolds() []*Hold {
+	return logHoldErr(GetRepositories().Holds.GetAll())
+}
+
+// GetHoldsByUserID 获取用户的所有预约
+func GetHoldsByUserID(userID int) []*Hold {
+	return logHoldErr(GetRepositories().Holds.GetByUserID(userID))
+}
+
+// GetActiveHoldsByBookID 获取图书的有效预约（按预约先后排序）
+func GetActiveHoldsByBookID(bookID int) []*Hold {
+	return logHoldErr(GetRepositories().Holds.GetActiveByBookID(bookID))
+}
+
+// CountReadyHoldsByBookID 统计图书为待取预约保留的数量
+func CountReadyHoldsByBookID(bookID int) int {
+	count := 0
+	for _, hold := range GetActiveHoldsByBookID(bookID) {
+		if hold.Status == HoldReady {
+			count++
+		}
+	}
+	return count
+}
+
+// HoldQueuePosition 获取排队中预约在队列中的位置（从1开始），非排队状态返回0
+func HoldQueuePosition(hold *Hold) int {
+	if hold.Status != HoldWaiting {
+		return 0
+	}
+
+	position := 0
+	for _, h := range GetActiveHoldsByBookID(hold.BookID) {
+		if h.Status == HoldWaiting {
+			position++
+		}
+		if h.ID == hold.ID {
+			return position
+		}
+	}
+	return 0
+}
+
+// readyHoldFor 查找用户对图书的待取预约
+func readyHoldFor(userID, bookID int) *Hold {
+	for _, hold := range GetActiveHoldsByBookID(bookID) {
+		if hold.UserID == userID && hold.Status == HoldReady {
+			return hold
+		}
+	}
+	return nil
+}
+
+// promoteHolds 按先后顺序将排队预约设为待取，直到没有空闲库存，调用方需依次持有 borrowMutex 和 holdMutex
+func promoteHolds(bookID int, now time.Time) {
+	book, err := GetBookByID(bookID)
+	if err != nil {
+		return
+	}
+
+	available := book.GetAvailableQuantity()
+	for _, hold := range GetActiveHoldsByBookID(bookID) {
+		if available <= 0 {
+			return
+		}
+		if hold.Status != HoldWaiting {
+			continue
+		}
+
+		hold.Status = HoldReady
+		hold.ReadyAt = now
+		hold.PickupDeadline = now.AddDate(0, 0, HoldPickupDays)
+		if err := GetRepositories().Holds.Update(hold); err != nil {
+			log.Printf("更新预约状态失败: %v", err)
+			return
+		}
+		available--
+	}
+}
+
+// logHoldErr 记录预约查询错误并返回结果
+func logHoldErr(holds []*Hold, err error) []*Hold {
+	if err != nil {
+		log.Printf("查询预约失败: %v", err)
+	}
+	return holds
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryHoldRepository 基于内存切片的预约仓库实现
+type MemoryHoldRepository struct {
+	mu     sync.RWMutex
+	holds  []*Hold
+	nextID int
+}
+
+// NewMemoryHoldRepository 创建内存预约仓库
+func NewMemoryHoldRepository() *MemoryHoldRepository {
+	return &MemoryHoldRepository{nextID: 1}
+}
+
+// Create 添加预约并分配ID
+func (r *MemoryHoldRepository) Create(hold *Hold) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	hold.ID = r.nextID
+	r.holds = append(r.holds, hold)
+	r.nextID++
+	return nil
+}
+
+// Update 更新预约
+func (r *MemoryHoldRepository) Update(hold *Hold) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, h := range r.holds {
+		if h.ID == hold.ID {
+			r.holds[i] = hold
+			return nil
+		}
+	}
+	return ErrHoldNotFound
+}
+
+// GetByID 根据ID获取预约
+func (r *MemoryHoldRepository) GetByID(id int) (*Hold, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, hold := range r.holds {
+		if hold.ID == id {
+			return hold, nil
+		}
+	}
+	return nil, ErrHoldNotFound
+}
+
+// GetAll 获取所有预约
+func (r *MemoryHoldRepository) GetAll() ([]*Hold, error) {
+	return r.filter(func(*Hold) bool { return true }), nil
+}
+
+// GetByUserID 获取用户的所有预约
+func (r *MemoryHoldRepository) GetByUserID(userID int) ([]*Hold, error) {
+	return r.filter(func(hold *Hold) bool {
+		return hold.UserID == userID
+	}), nil
+}
+
+// GetByBookID 获取图书的所有预约（含已结束的）
+func (r *MemoryHoldRepository) GetByBookID(bookID int) ([]*Hold, error) {
+	return r.filter(func(hold *Hold) bool {
+		return hold.BookID == bookID
+	}), nil
+}
+
+// GetActiveByBookID 获取图书排队中和待取的预约，按预约先后排序
+func (r *MemoryHoldRepository) GetActiveByBookID(bookID int) ([]*Hold, error) {
+	return r.filter(func(hold *Hold) bool {
+		return hold.BookID == bookID && hold.IsActive()
+	}), nil
+}
+
+// GetReadyExpired 获取截至now已超过取书期限的待取预约
+func (r *MemoryHoldRepository) GetReadyExpired(now time.Time) ([]*Hold, error) {
+	return r.filter(func(hold *Hold) bool {
+		return hold.Status == HoldReady && now.After(hold.PickupDeadline)
+	}), nil
+}
+
+// filter 返回满足条件的预约，保持创建顺序
+func (r *MemoryHoldRepository) filter(match func(*Hold) bool) []*Hold {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Hold
+	for _, hold := range r.holds {
+		if match(hold) {
+			result = append(result, hold)
+		}
+	}
+	return result
+}
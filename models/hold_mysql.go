@@ -0,0 +1,121 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// holdColumns 预约表查询列
+const holdColumns = "id, user_id, book_id, status, created_at, ready_at, pickup_deadline"
+
+// MySQLHoldRepository 基于MySQL的预约仓库实现
+type MySQLHoldRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLHoldRepository 创建MySQL预约仓库
+func NewMySQLHoldRepository(db *sql.DB) *MySQLHoldRepository {
+	return &MySQLHoldRepository{db: db}
+}
+
+// Create 插入预约并回填ID
+func (r *MySQLHoldRepository) Create(hold *Hold) error {
+	result, err := r.db.Exec(`
+		INSERT INTO holds (user_id, book_id, status, created_at, ready_at, pickup_deadline)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		hold.UserID, hold.BookID, string(hold.Status), hold.CreatedAt,
+		nullTime(hold.ReadyAt), nullTime(hold.PickupDeadline))
+	if err != nil {
+		return fmt.Errorf("创建预约失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取预约ID失败: %w", err)
+	}
+	hold.ID = int(id)
+	return nil
+}
+
+// Update 更新预约
+func (r *MySQLHoldRepository) Update(hold *Hold) error {
+	_, err := r.db.Exec(`
+		UPDATE holds SET status = ?, ready_at = ?, pickup_deadline = ?
+		WHERE id = ?`,
+		string(hold.Status), nullTime(hold.ReadyAt), nullTime(hold.PickupDeadline), hold.ID)
+	if err != nil {
+		return fmt.Errorf("更新预约失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取预约
+func (r *MySQLHoldRepository) GetByID(id int) (*Hold, error) {
+	return scanHold(r.db.QueryRow("SELECT "+holdColumns+" FROM holds WHERE id = ?", id))
+}
+
+// GetAll 获取所有预约
+func (r *MySQLHoldRepository) GetAll() ([]*Hold, error) {
+	return r.query("SELECT " + holdColumns + " FROM holds ORDER BY id")
+}
+
+// GetByUserID 获取用户的所有预约
+func (r *MySQLHoldRepository) GetByUserID(userID int) ([]*Hold, error) {
+	return r.query("SELECT "+holdColumns+" FROM holds WHERE user_id = ? ORDER BY id", userID)
+}
+
+// GetByBookID 获取图书的所有预约（含已结束的）
+func (r *MySQLHoldRepository) GetByBookID(bookID int) ([]*Hold, error) {
+	return r.query("SELECT "+holdColumns+" FROM holds WHERE book_id = ? ORDER BY id", bookID)
+}
+
+// GetActiveByBookID 获取图书排队中和待取的预约，按预约先后排序
+func (r *MySQLHoldRepository) GetActiveByBookID(bookID int) ([]*Hold, error) {
+	return r.query("SELECT "+holdColumns+" FROM holds WHERE book_id = ? AND status IN (?, ?) ORDER BY created_at, id",
+		bookID, string(HoldWaiting), string(HoldReady))
+}
+
+// GetReadyExpired 获取截至now已超过取书期限的待取预约
+func (r *MySQLHoldRepository) GetReadyExpired(now time.Time) ([]*Hold, error) {
+	return r.query("SELECT "+holdColumns+" FROM holds WHERE status = ? AND pickup_deadline < ? ORDER BY id",
+		string(HoldReady), now)
+}
+
+// query 执行查询并扫描预约列表
+func (r *MySQLHoldRepository) query(query string, args ...interface{}) ([]*Hold, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询预约失败: %w", err)
+	}
+	defer rows.Close()
+
+	var holds []*Hold
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+// scanHold 扫描一行预约数据
+func scanHold(row rowScanner) (*Hold, error) {
+	hold := &Hold{}
+	var status string
+	var readyAt, pickupDeadline sql.NullTime
+	err := row.Scan(&hold.ID, &hold.UserID, &hold.BookID, &status, &hold.CreatedAt, &readyAt, &pickupDeadline)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	hold.Status = HoldStatus(status)
+	hold.ReadyAt = readyAt.Time
+	hold.PickupDeadline = pickupDeadline.Time
+	return hold, nil
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var (
+	// ErrInvalidISBN ISBN格式或校验位错误
+	ErrInvalidISBN = errors.New("ISBN无效")
+	// ErrDuplicateISBN ISBN已被其他图书使用
+	ErrDuplicateISBN = errors.New("ISBN已存在")
+)
+
+// isbnSeparators 录入时常见的分隔符
+var isbnSeparators = strings.NewReplacer("-", "", " ", "", "‐", "", "－", "")
+
+// NormalizeISBN 去掉分隔符并校验ISBN-10或ISBN-13，统一返回13位形式
+func NormalizeISBN(isbn string) (string, error) {
+	s := strings.ToUpper(isbnSeparators.Replace(strings.TrimSpace(isbn)))
+	if strings.HasPrefix(s, "ISBN") {
+		s = strings.TrimLeft(s[4:], ":：")
+	}
+
+	switch len(s) {
+	case 10:
+		if !validISBN10(s) {
+			return "", fmt.Errorf("%w：ISBN-10校验位错误", ErrInvalidISBN)
+		}
+		base := "978" + s[:9]
+		return base + string(isbn13CheckDigit(base)), nil
+	case 13:
+		if !isDigits(s) {
+			return "", fmt.Errorf("%w：ISBN-13只能包含数字", ErrInvalidISBN)
+		}
+		if !strings.HasPrefix(s, "978") && !strings.HasPrefix(s, "979") {
+			return "", fmt.Errorf("%w：ISBN-13必须以978或979开头", ErrInvalidISBN)
+		}
+		if isbn13CheckDigit(s[:12]) != s[12] {
+			return "", fmt.Errorf("%w：ISBN-13校验位错误", ErrInvalidISBN)
+		}
+		return s, nil
+	}
+	return "", fmt.Errorf("%w：应为10位或13位", ErrInvalidISBN)
+}
+
+// validISBN10 校验ISBN-10，最后一位可以是X
+func validISBN10(s string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var d int
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			d = int(s[i] - '0')
+		case s[i] == 'X' && i == 9:
+			d = 10
+		default:
+			return false
+		}
+		sum += d * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// isbn13CheckDigit 计算ISBN-13前12位对应的校验位
+func isbn13CheckDigit(base string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(base[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// isDigits 判断字符串是否只包含数字
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeStoredISBNs 将已入库图书的ISBN规范化为13位形式，返回更新的数量，
+// 无效或规范化后与其他图书冲突的ISBN保持原样并记录日志
+func NormalizeStoredISBNs() (int, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	books, err := GetRepositories().Books.GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, book := range books {
+		isbn, err := NormalizeISBN(book.ISBN)
+		if err != nil {
+			log.Printf("图书 %d 的ISBN %q 无效: %v", book.ID, book.ISBN, err)
+			continue
+		}
+		if isbn == book.ISBN {
+			continue
+		}
+		if existing, _ := GetRepositories().Books.GetByISBN(isbn); existing != nil {
+			log.Printf("图书 %d 的ISBN %q 与图书 %d 重复，未更新", book.ID, book.ISBN, existing.ID)
+			continue
+		}
+
+		book.ISBN = isbn
+		if err := GetRepositories().Books.Update(book); err != nil {
+			return count, err
+		}
+		bookIndex.update(book)
+		count++
+	}
+	return count, nil
+}
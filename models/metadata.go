@@ -0,0 +1,127 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrMetadataNotFound 数据源中没有该ISBN的书目信息
+var ErrMetadataNotFound = errors.New("未找到该ISBN的书目信息")
+
+// BookMetadata 按ISBN查到的书目信息，未知字段为零值
+type BookMetadata struct {
+	ISBN          string `json:"isbn"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	PublishedYear int    `json:"published_year"`
+	Category      string `json:"category"`
+	Description   string `json:"description"`
+	CoverURL      string `json:"cover_url"`
+	Source        string `json:"source"`
+}
+
+// MetadataProvider 书目元数据数据源接口
+type MetadataProvider interface {
+	// Name 数据源名称
+	Name() string
+	// Lookup 按13位ISBN查询书目信息，查不到时返回 ErrMetadataNotFound
+	Lookup(ctx context.Context, isbn string) (*BookMetadata, error)
+}
+
+// MetadataConfig 书目元数据数据源配置
+type MetadataConfig struct {
+	Providers      []string // 按顺序查询的数据源：openlibrary、file
+	OpenLibraryURL string   // Open Library 兼容服务的地址，为空时使用官方地址
+	File           string   // 本地书目文件（JSON数组或JSON Lines）
+}
+
+var (
+	metadataProvider      MetadataProvider = NewOpenLibraryProvider("")
+	metadataProviderMutex sync.RWMutex
+)
+
+// SetMetadataProvider 设置书目元数据数据源
+func SetMetadataProvider(p MetadataProvider) {
+	metadataProviderMutex.Lock()
+	defer metadataProviderMutex.Unlock()
+	metadataProvider = p
+}
+
+// GetMetadataProvider 获取书目元数据数据源
+func GetMetadataProvider() MetadataProvider {
+	metadataProviderMutex.RLock()
+	defer metadataProviderMutex.RUnlock()
+	return metadataProvider
+}
+
+// NewMetadataProvider 根据配置创建数据源，配置多个时按顺序查询
+func NewMetadataProvider(cfg MetadataConfig) (MetadataProvider, error) {
+	var providers []MetadataProvider
+	for _, name := range cfg.Providers {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "openlibrary":
+			providers = append(providers, NewOpenLibraryProvider(cfg.OpenLibraryURL))
+		case "file":
+			if cfg.File == "" {
+				return nil, errors.New("使用本地书目文件时必须指定文件路径")
+			}
+			providers = append(providers, NewFileMetadataProvider(cfg.File))
+		default:
+			return nil, fmt.Errorf("不支持的书目数据源: %s", name)
+		}
+	}
+
+	switch len(providers) {
+	case 0:
+		return NewOpenLibraryProvider(cfg.OpenLibraryURL), nil
+	case 1:
+		return providers[0], nil
+	}
+	return ChainMetadataProvider(providers), nil
+}
+
+// ChainMetadataProvider 依次查询多个数据源，返回第一个查到的结果
+type ChainMetadataProvider []MetadataProvider
+
+// Name 数据源名称
+func (c ChainMetadataProvider) Name() string {
+	names := make([]string, len(c))
+	for i, p := range c {
+		names[i] = p.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Lookup 依次查询，某个数据源出错时继续尝试下一个
+func (c ChainMetadataProvider) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	var lastErr error = ErrMetadataNotFound
+	for _, p := range c {
+		metadata, err := p.Lookup(ctx, isbn)
+		if err == nil {
+			return metadata, nil
+		}
+		if !errors.Is(err, ErrMetadataNotFound) {
+			lastErr = err
+		}
+	}
+	return nil, lastErr
+}
+
+// LookupBookMetadata 规范化ISBN后查询书目信息
+func LookupBookMetadata(ctx context.Context, isbn string) (*BookMetadata, error) {
+	isbn, err := NormalizeISBN(isbn)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := GetMetadataProvider().Lookup(ctx, isbn)
+	if err != nil {
+		return nil, err
+	}
+	metadata.ISBN = isbn
+	return metadata, nil
+}
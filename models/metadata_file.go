@@ -0,0 +1,64 @@
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileMetadataProvider 从本地文件查询书目信息，文件为 BookMetadata 的JSON数组或JSON Lines，
+// 图书导出的 jsonl 文件可以直接使用；每次查询时重新读取，修改文件后立即生效
+type FileMetadataProvider struct {
+	Path string
+}
+
+// NewFileMetadataProvider 创建本地文件数据源
+func NewFileMetadataProvider(path string) *FileMetadataProvider {
+	return &FileMetadataProvider{Path: path}
+}
+
+// Name 数据源名称
+func (p *FileMetadataProvider) Name() string { return "file" }
+
+// Lookup 按ISBN查询书目信息，文件中的ISBN可以带连字符或为10位
+func (p *FileMetadataProvider) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取书目文件失败: %w", err)
+	}
+
+	var records []*BookMetadata
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &records); err != nil {
+			return nil, fmt.Errorf("解析书目文件失败: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			record := &BookMetadata{}
+			if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+				return nil, fmt.Errorf("解析书目文件第%d行失败: %w", line, err)
+			}
+			records = append(records, record)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("读取书目文件失败: %w", err)
+		}
+	}
+
+	for _, record := range records {
+		if normalized, err := NormalizeISBN(record.ISBN); err == nil && normalized == isbn {
+			record.ISBN = isbn
+			record.Source = p.Name()
+			return record, nil
+		}
+	}
+	return nil, ErrMetadataNotFound
+}
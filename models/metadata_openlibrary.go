@@ -0,0 +1,135 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultOpenLibraryURL Open Library 官方地址
+const defaultOpenLibraryURL = "https://openlibrary.org"
+
+// OpenLibraryProvider 通过 Open Library Books API（/api/books?jscmd=data）查询书目信息，
+// BaseURL 可以指向兼容的自建服务或测试桩
+type OpenLibraryProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewOpenLibraryProvider 创建 Open Library 数据源，baseURL 为空时使用官方地址
+func NewOpenLibraryProvider(baseURL string) *OpenLibraryProvider {
+	if baseURL == "" {
+		baseURL = defaultOpenLibraryURL
+	}
+	return &OpenLibraryProvider{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name 数据源名称
+func (p *OpenLibraryProvider) Name() string { return "openlibrary" }
+
+// openLibraryText Open Library 中可能是字符串或 {"value": ...} 对象的文本字段
+type openLibraryText string
+
+// UnmarshalJSON 兼容两种文本格式
+func (t *openLibraryText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = openLibraryText(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = openLibraryText(v.Value)
+	return nil
+}
+
+// openLibraryBook Books API jscmd=data 返回的单本图书
+type openLibraryBook struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Authors  []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	PublishDate string `json:"publish_date"`
+	Subjects    []struct {
+		Name string `json:"name"`
+	} `json:"subjects"`
+	Notes    openLibraryText `json:"notes"`
+	Excerpts []struct {
+		Text string `json:"text"`
+	} `json:"excerpts"`
+	Cover struct {
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+// Lookup 按ISBN查询书目信息
+func (p *OpenLibraryProvider) Lookup(ctx context.Context, isbn string) (*BookMetadata, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.BaseURL+"/api/books?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Open Library失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Open Library返回错误状态: %s", resp.Status)
+	}
+
+	var result map[string]openLibraryBook
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析Open Library响应失败: %w", err)
+	}
+	book, ok := result[key]
+	if !ok {
+		return nil, ErrMetadataNotFound
+	}
+
+	metadata := &BookMetadata{
+		ISBN:        isbn,
+		Title:       book.Title,
+		Description: string(book.Notes),
+		CoverURL:    book.Cover.Large,
+		Source:      p.Name(),
+	}
+	if book.Subtitle != "" {
+		metadata.Title += "：" + book.Subtitle
+	}
+	names := make([]string, 0, len(book.Authors))
+	for _, author := range book.Authors {
+		names = append(names, author.Name)
+	}
+	metadata.Author = strings.Join(names, "、")
+	if year := marcYearPattern.FindString(book.PublishDate); year != "" {
+		metadata.PublishedYear, _ = strconv.Atoi(year)
+	}
+	if len(book.Subjects) > 0 {
+		metadata.Category = book.Subjects[0].Name
+	}
+	if metadata.Description == "" && len(book.Excerpts) > 0 {
+		metadata.Description = book.Excerpts[0].Text
+	}
+	if metadata.CoverURL == "" {
+		metadata.CoverURL = book.Cover.Medium
+	}
+	return metadata, nil
+}
@@ -0,0 +1,257 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher 密码哈希算法接口，编码结果需自带算法标识和参数
+type PasswordHasher interface {
+	// Name 算法名称
+	Name() string
+	// Hash 生成编码后的密码哈希
+	Hash(password string) (string, error)
+	// Matches 判断编码后的哈希是否由本算法生成
+	Matches(encoded string) bool
+	// Verify 校验密码
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash 判断哈希参数是否已过时
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	// 当前用于生成新哈希的算法
+	passwordHasher      PasswordHasher = NewArgon2idHasher()
+	passwordHasherMutex sync.RWMutex
+
+	// 可识别的全部算法，用于校验旧哈希
+	knownHashers = []PasswordHasher{
+		NewArgon2idHasher(),
+		NewBcryptHasher(bcrypt.DefaultCost),
+		legacySHA256Hasher{},
+	}
+)
+
+// SetPasswordHasher 设置生成新哈希使用的算法
+func SetPasswordHasher(h PasswordHasher) {
+	passwordHasherMutex.Lock()
+	defer passwordHasherMutex.Unlock()
+	passwordHasher = h
+}
+
+// GetPasswordHasher 获取生成新哈希使用的算法
+func GetPasswordHasher() PasswordHasher {
+	passwordHasherMutex.RLock()
+	defer passwordHasherMutex.RUnlock()
+	return passwordHasher
+}
+
+// PasswordHasherByName 根据名称创建算法（argon2id 或 bcrypt）
+func PasswordHasherByName(name string) (PasswordHasher, error) {
+	switch name {
+	case "", "argon2id":
+		return NewArgon2idHasher(), nil
+	case "bcrypt":
+		return NewBcryptHasher(12), nil
+	default:
+		return nil, fmt.Errorf("不支持的密码哈希算法: %s", name)
+	}
+}
+
+// HashPassword 使用当前算法生成密码哈希
+func HashPassword(password string) (string, error) {
+	return GetPasswordHasher().Hash(password)
+}
+
+// findHasher 根据编码格式查找对应算法
+func findHasher(encoded string) PasswordHasher {
+	current := GetPasswordHasher()
+	if current.Matches(encoded) {
+		return current
+	}
+	for _, h := range knownHashers {
+		if h.Matches(encoded) {
+			return h
+		}
+	}
+	return nil
+}
+
+// verifyPassword 校验密码，并返回该哈希是否需要用当前算法重新生成
+func verifyPassword(encoded, password string) (ok bool, rehash bool) {
+	h := findHasher(encoded)
+	if h == nil {
+		return false, false
+	}
+
+	ok, err := h.Verify(encoded, password)
+	if err != nil || !ok {
+		return false, false
+	}
+
+	current := GetPasswordHasher()
+	return true, h.Name() != current.Name() || current.NeedsRehash(encoded)
+}
+
+// Argon2idHasher argon2id 实现，编码格式为
+// $argon2id$v=19$m=<内存KiB>,t=<迭代次数>,p=<并行度>$<盐>$<哈希>
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// NewArgon2idHasher 使用推荐参数创建 argon2id 算法
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      64 * 1024,
+		Iterations:  1,
+		Parallelism: 4,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2idHasher) Name() string { return "argon2id" }
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.Memory || params.Iterations != h.Iterations ||
+		params.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength || uint32(len(key)) != h.KeyLength
+}
+
+// decodeArgon2id 解析 argon2id 编码哈希
+func decodeArgon2id(encoded string) (*Argon2idHasher, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, errors.New("无效的argon2id哈希格式")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, err
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("不支持的argon2版本: %d", version)
+	}
+
+	params := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return nil, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return params, salt, key, nil
+}
+
+// BcryptHasher bcrypt 实现，编码格式自带成本参数（$2a$<cost>$...）
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 算法
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Name() string { return "bcrypt" }
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+// legacySHA256Hasher 旧版无盐 SHA-256 十六进制哈希，仅用于校验和升级
+type legacySHA256Hasher struct{}
+
+func (legacySHA256Hasher) Name() string { return "sha256" }
+
+func (legacySHA256Hasher) Hash(password string) (string, error) {
+	hash := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (legacySHA256Hasher) Matches(encoded string) bool {
+	if len(encoded) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
+
+func (h legacySHA256Hasher) Verify(encoded, password string) (bool, error) {
+	hash, _ := h.Hash(password)
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(encoded)), []byte(hash)) == 1, nil
+}
+
+func (legacySHA256Hasher) NeedsRehash(string) bool { return true }
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"errors"
+	"log"
+	"sync"
+)
+
+// CirculationPolicy 流通规则，按用户角色和图书分类匹配，空值表示适用于全部
+type CirculationPolicy struct {
+	ID            int      `json:"id"`
+	Role          UserRole `json:"role"`
+	Category      string   `json:"category"`
+	LoanDays      int      `json:"loan_days"`       // 借期（天），续借时同样按此延长
+	MaxLoans      int      `json:"max_loans"`       // 最多同时借阅数量，0表示不限
+	MaxRenewals   int      `json:"max_renewals"`    // 最多续借次数
+	FineDailyRate int      `json:"fine_daily_rate"` // 每逾期一天的罚款（分）
+	FineMax       int      `json:"fine_max"`        // 单次借阅罚款上限（分），0表示不设上限
+}
+
+// DefaultCirculationPolicy 没有匹配的规则时使用的默认规则
+var DefaultCirculationPolicy = CirculationPolicy{
+	LoanDays:      14,
+	MaxLoans:      5,
+	MaxRenewals:   2,
+	FineDailyRate: 50,
+	FineMax:       5000,
+}
+
+// policyMutex 保证流通规则唯一性校验与写入的原子性
+var policyMutex sync.Mutex
+
+// RoleText 适用角色文本
+func (p *CirculationPolicy) RoleText() string {
+	switch p.Role {
+	case "":
+		return "所有角色"
+	case RoleAdmin:
+		return "管理员"
+	case RoleLibrarian:
+		return "图书管理员"
+	case RoleReader:
+		return "读者"
+	}
+	return string(p.Role)
+}
+
+// CategoryText 适用分类文本
+func (p *CirculationPolicy) CategoryText() string {
+	if p.Category == "" {
+		return "所有分类"
+	}
+	return p.Category
+}
+
+// specificity 规则的匹配优先级：角色+分类 > 分类 > 角色 > 通用
+func (p *CirculationPolicy) specificity() int {
+	score := 0
+	if p.Category != "" {
+		score += 2
+	}
+	if p.Role != "" {
+		score++
+	}
+	return score
+}
+
+// validatePolicy 校验流通规则字段
+func validatePolicy(p *CirculationPolicy) error {
+	if p.Role != "" && p.Role != RoleAdmin && p.Role != RoleLibrarian && p.Role != RoleReader {
+		return errors.New("无效的角色")
+	}
+
+	if p.LoanDays < 1 {
+		return errors.New("借期必须大于0天")
+	}
+
+	if p.MaxLoans < 0 || p.MaxRenewals < 0 || p.FineDailyRate < 0 || p.FineMax < 0 {
+		return errors.New("数量和金额不能为负数")
+	}
+
+	return nil
+}
+
+// checkPolicyUnique 检查角色和分类组合是否已有规则
+func checkPolicyUnique(p *CirculationPolicy) error {
+	policies, err := GetRepositories().Policies.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, existing := range policies {
+		if existing.ID != p.ID && existing.Role == p.Role && existing.Category == p.Category {
+			return errors.New("该角色和分类的流通规则已存在")
+		}
+	}
+	return nil
+}
+
+// CreatePolicy 创建流通规则
+func CreatePolicy(p *CirculationPolicy) error {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	if err := validatePolicy(p); err != nil {
+		return err
+	}
+	if err := checkPolicyUnique(p); err != nil {
+		return err
+	}
+
+	return GetRepositories().Policies.Create(p)
+}
+
+// UpdatePolicy 更新流通规则
+func UpdatePolicy(p *CirculationPolicy) error {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	if err := validatePolicy(p); err != nil {
+		return err
+	}
+	if _, err := GetRepositories().Policies.GetByID(p.ID); err != nil {
+		return err
+	}
+	if err := checkPolicyUnique(p); err != nil {
+		return err
+	}
+
+	return GetRepositories().Policies.Update(p)
+}
+
+// DeletePolicy 删除流通规则
+func DeletePolicy(id int) error {
+	policyMutex.Lock()
+	defer policyMutex.Unlock()
+
+	return GetRepositories().Policies.Delete(id)
+}
+
+// GetPolicyByID 根据ID获取流通规则
+func GetPolicyByID(id int) (*CirculationPolicy, error) {
+	return GetRepositories().Policies.GetByID(id)
+}
+
+// GetAllPolicies 获取所有流通规则
+func GetAllPolicies() []*CirculationPolicy {
+	policies, err := GetRepositories().Policies.GetAll()
+	if err != nil {
+		log.Printf("获取流通规则失败: %v", err)
+	}
+	return policies
+}
+
+// ResolvePolicy 获取适用于角色和分类的流通规则，优先使用最具体的规则
+func ResolvePolicy(role UserRole, category string) *CirculationPolicy {
+	var best *CirculationPolicy
+	for _, p := range GetAllPolicies() {
+		if p.Role != "" && p.Role != role {
+			continue
+		}
+		if p.Category != "" && p.Category != category {
+			continue
+		}
+		if best == nil || p.specificity() > best.specificity() {
+			best = p
+		}
+	}
+
+	if best == nil {
+		policy := DefaultCirculationPolicy
+		return &policy
+	}
+	return best
+}
+
+// policyFor 获取用户借阅图书时适用的流通规则
+func policyFor(userID, bookID int) (*CirculationPolicy, error) {
+	user, err := GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	book, err := GetBookByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	return ResolvePolicy(user.Role, book.Category), nil
+}
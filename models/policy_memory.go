@@ -0,0 +1,77 @@
+package models
+
+import "sync"
+
+// MemoryPolicyRepository 基于内存切片的流通规则仓库实现
+type MemoryPolicyRepository struct {
+	mu       sync.RWMutex
+	policies []*CirculationPolicy
+	nextID   int
+}
+
+// NewMemoryPolicyRepository 创建内存流通规则仓库
+func NewMemoryPolicyRepository() *MemoryPolicyRepository {
+	return &MemoryPolicyRepository{nextID: 1}
+}
+
+// Create 添加流通规则并分配ID
+func (r *MemoryPolicyRepository) Create(policy *CirculationPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	policy.ID = r.nextID
+	r.policies = append(r.policies, policy)
+	r.nextID++
+	return nil
+}
+
+// Update 更新流通规则
+func (r *MemoryPolicyRepository) Update(policy *CirculationPolicy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.policies {
+		if p.ID == policy.ID {
+			r.policies[i] = policy
+			return nil
+		}
+	}
+	return ErrPolicyNotFound
+}
+
+// Delete 删除流通规则
+func (r *MemoryPolicyRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.policies {
+		if p.ID == id {
+			r.policies = append(r.policies[:i], r.policies[i+1:]...)
+			return nil
+		}
+	}
+	return ErrPolicyNotFound
+}
+
+// GetByID 根据ID获取流通规则
+func (r *MemoryPolicyRepository) GetByID(id int) (*CirculationPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, p := range r.policies {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, ErrPolicyNotFound
+}
+
+// GetAll 获取所有流通规则
+func (r *MemoryPolicyRepository) GetAll() ([]*CirculationPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*CirculationPolicy, len(r.policies))
+	copy(result, r.policies)
+	return result, nil
+}
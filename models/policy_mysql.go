@@ -0,0 +1,102 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// policyColumns 流通规则表查询列
+const policyColumns = "id, role, category, loan_days, max_loans, max_renewals, fine_daily_rate, fine_max"
+
+// MySQLPolicyRepository 基于MySQL的流通规则仓库实现
+type MySQLPolicyRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPolicyRepository 创建MySQL流通规则仓库
+func NewMySQLPolicyRepository(db *sql.DB) *MySQLPolicyRepository {
+	return &MySQLPolicyRepository{db: db}
+}
+
+// Create 插入流通规则并回填ID
+func (r *MySQLPolicyRepository) Create(policy *CirculationPolicy) error {
+	result, err := r.db.Exec(`
+		INSERT INTO circulation_policies (role, category, loan_days, max_loans, max_renewals, fine_daily_rate, fine_max)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		string(policy.Role), policy.Category, policy.LoanDays, policy.MaxLoans,
+		policy.MaxRenewals, policy.FineDailyRate, policy.FineMax)
+	if err != nil {
+		return fmt.Errorf("创建流通规则失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取流通规则ID失败: %w", err)
+	}
+	policy.ID = int(id)
+	return nil
+}
+
+// Update 更新流通规则
+func (r *MySQLPolicyRepository) Update(policy *CirculationPolicy) error {
+	_, err := r.db.Exec(`
+		UPDATE circulation_policies SET role = ?, category = ?, loan_days = ?, max_loans = ?,
+		max_renewals = ?, fine_daily_rate = ?, fine_max = ?
+		WHERE id = ?`,
+		string(policy.Role), policy.Category, policy.LoanDays, policy.MaxLoans,
+		policy.MaxRenewals, policy.FineDailyRate, policy.FineMax, policy.ID)
+	if err != nil {
+		return fmt.Errorf("更新流通规则失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除流通规则
+func (r *MySQLPolicyRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM circulation_policies WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除流通规则失败: %w", err)
+	}
+	return checkAffected(result, ErrPolicyNotFound)
+}
+
+// GetByID 根据ID获取流通规则
+func (r *MySQLPolicyRepository) GetByID(id int) (*CirculationPolicy, error) {
+	return scanPolicy(r.db.QueryRow("SELECT "+policyColumns+" FROM circulation_policies WHERE id = ?", id))
+}
+
+// GetAll 获取所有流通规则
+func (r *MySQLPolicyRepository) GetAll() ([]*CirculationPolicy, error) {
+	rows, err := r.db.Query("SELECT " + policyColumns + " FROM circulation_policies ORDER BY role, category")
+	if err != nil {
+		return nil, fmt.Errorf("查询流通规则失败: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*CirculationPolicy
+	for rows.Next() {
+		policy, err := scanPolicy(rows)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, rows.Err()
+}
+
+// scanPolicy 扫描一行流通规则数据
+func scanPolicy(row rowScanner) (*CirculationPolicy, error) {
+	policy := &CirculationPolicy{}
+	var role string
+	err := row.Scan(&policy.ID, &role, &policy.Category, &policy.LoanDays, &policy.MaxLoans,
+		&policy.MaxRenewals, &policy.FineDailyRate, &policy.FineMax)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPolicyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy.Role = UserRole(role)
+	return policy, nil
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Renewal 续借记录
+type Renewal struct {
+	ID              int       `json:"id"`
+	RecordID        int       `json:"record_id"`
+	RenewedBy       int       `json:"renewed_by"`
+	RenewedAt       time.Time `json:"renewed_at"`
+	PreviousDueDate time.Time `json:"previous_due_date"`
+	NewDueDate      time.Time `json:"new_due_date"`
+}
+
+// CanRenew 检查借阅记录能否续借，不能续借时返回原因
+func (r *BorrowRecord) CanRenew() error {
+	if !r.ReturnDate.IsZero() {
+		return errors.New("该图书已归还")
+	}
+
+	if r.IsOverdue() {
+		return errors.New("图书已逾期，请先归还")
+	}
+
+	policy, err := policyFor(r.UserID, r.BookID)
+	if err != nil {
+		return err
+	}
+	if r.RenewCount >= policy.MaxRenewals {
+		return fmt.Errorf("已达到最多续借次数（%d次）", policy.MaxRenewals)
+	}
+
+	if len(GetActiveHoldsByBookID(r.BookID)) > 0 {
+		return errors.New("该图书已有其他读者预约，无法续借")
+	}
+
+	return nil
+}
+
+// RenewBorrowRecord 续借图书，renewedBy 为操作人的用户ID
+func RenewBorrowRecord(recordID, renewedBy int) (*BorrowRecord, error) {
+	borrowMutex.Lock()
+	defer borrowMutex.Unlock()
+
+	// 查找借阅记录
+	record, err := GetRepositories().Borrows.GetByID(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查续借条件
+	if err := record.CanRenew(); err != nil {
+		return nil, err
+	}
+
+	policy, err := policyFor(record.UserID, record.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 从原到期日起按借期延长
+	renewal := &Renewal{
+		RecordID:        record.ID,
+		RenewedBy:       renewedBy,
+		RenewedAt:       time.Now(),
+		PreviousDueDate: record.DueDate,
+		NewDueDate:      record.DueDate.AddDate(0, 0, policy.LoanDays),
+	}
+
+	// 到期日和续借记录一起保存，任一失败都不续借
+	record.DueDate = renewal.NewDueDate
+	record.RenewCount++
+	if err := GetRepositories().Borrows.Renew(record, renewal); err != nil {
+		record.DueDate = renewal.PreviousDueDate
+		record.RenewCount--
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetRenewalsByRecordID 获取借阅记录的续借历史
+func GetRenewalsByRecordID(recordID int) []*Renewal {
+	renewals, err := GetRepositories().Borrows.GetRenewalsByRecordID(recordID)
+	if err != nil {
+		log.Printf("查询续借记录失败: %v", err)
+	}
+	return renewals
+}
@@ -0,0 +1,291 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 仓库层通用错误
+var (
+	ErrBookNotFound         = errors.New("图书不存在")
+	ErrUserNotFound         = errors.New("用户不存在")
+	ErrBorrowRecordNotFound = errors.New("借阅记录不存在")
+	ErrHoldNotFound         = errors.New("预约不存在")
+	ErrPolicyNotFound       = errors.New("流通规则不存在")
+	ErrCopyNotFound         = errors.New("图书副本不存在")
+	ErrAPITokenNotFound     = errors.New("API令牌无效")
+)
+
+// BookRepository 图书数据仓库接口
+type BookRepository interface {
+	Create(book *Book) error
+	Update(book *Book) error
+	Delete(id int) error
+	GetByID(id int) (*Book, error)
+	GetByISBN(isbn string) (*Book, error)
+	GetAll() ([]*Book, error)
+	GetByCategory(category string) ([]*Book, error)
+	Find(query BookQuery, after *BookCursor, limit, offset int) ([]*Book, int, error)
+	Facet(query BookQuery, facet BookFacet) ([]FacetCount, error)
+	GetCategories() ([]string, error)
+	CatalogVersion() (int64, error)
+	BumpCatalogVersion() (int64, error)
+}
+
+// UserRepository 用户数据仓库接口
+type UserRepository interface {
+	Create(user *User) error
+	Update(user *User) error
+	GetByID(id int) (*User, error)
+	GetByUsername(username string) (*User, error)
+	GetByEmail(email string) (*User, error)
+	GetAll() ([]*User, error)
+}
+
+// BorrowRepository 借阅记录数据仓库接口
+type BorrowRepository interface {
+	Create(record *BorrowRecord) error
+	Update(record *BorrowRecord) error
+	GetByID(id int) (*BorrowRecord, error)
+	GetAll() ([]*BorrowRecord, error)
+	GetByUserID(userID int) ([]*BorrowRecord, error)
+	GetByBookID(bookID int) ([]*BorrowRecord, error)
+	GetActiveByUserID(userID int) ([]*BorrowRecord, error)
+	GetActiveByBookID(bookID int) ([]*BorrowRecord, error)
+	GetAllActive() ([]*BorrowRecord, error)
+	GetAllOverdue(now time.Time) ([]*BorrowRecord, error)
+	Find(filter BorrowFilter, now time.Time) ([]*BorrowRecord, error)
+	CountActiveByBookID(bookID int) (int, error)
+	Renew(record *BorrowRecord, renewal *Renewal) error
+	Lend(record *BorrowRecord, item *BookCopy) error
+	Return(record *BorrowRecord, item *BookCopy) error
+	GetRenewalsByRecordID(recordID int) ([]*Renewal, error)
+}
+
+// HoldRepository 图书预约数据仓库接口
+type HoldRepository interface {
+	Create(hold *Hold) error
+	Update(hold *Hold) error
+	GetByID(id int) (*Hold, error)
+	GetAll() ([]*Hold, error)
+	GetByUserID(userID int) ([]*Hold, error)
+	GetByBookID(bookID int) ([]*Hold, error)
+	GetActiveByBookID(bookID int) ([]*Hold, error)
+	GetReadyExpired(now time.Time) ([]*Hold, error)
+}
+
+// FineRepository 罚款台账数据仓库接口
+type FineRepository interface {
+	Create(entry *FineEntry) error
+	GetAll() ([]*FineEntry, error)
+	GetByUserID(userID int) ([]*FineEntry, error)
+	GetByRecordID(recordID int) ([]*FineEntry, error)
+	GetBalance(userID int) (int, error)
+}
+
+// CopyRepository 图书副本数据仓库接口
+type CopyRepository interface {
+	Create(item *BookCopy) error
+	Update(item *BookCopy) error
+	DeleteByBookID(bookID int) error
+	GetByID(id int) (*BookCopy, error)
+	GetByBarcode(barcode string) (*BookCopy, error)
+	GetByBookID(bookID int) ([]*BookCopy, error)
+	CountByStatus(bookID int, status CopyStatus) (int, error)
+}
+
+// APITokenRepository API令牌数据仓库接口
+type APITokenRepository interface {
+	Create(token *APIToken) error
+	Delete(id int) error
+	Touch(id int, usedAt time.Time) error
+	GetByID(id int) (*APIToken, error)
+	GetByHash(hash string) (*APIToken, error)
+	GetByUserID(userID int) ([]*APIToken, error)
+}
+
+// ContributorRepository 责任者及其与图书关联的数据仓库接口
+type ContributorRepository interface {
+	Create(c *Contributor) error
+	Update(c *Contributor) error
+	Delete(id int) error
+	GetByID(id int) (*Contributor, error)
+	GetByName(name string) (*Contributor, error)
+	Find(query string, limit, offset int) ([]*Contributor, int, error)
+	GetCreditsByBookID(bookID int) ([]*Credit, error)
+	GetCreditsByContributorID(contributorID int) ([]*Credit, error)
+	GetAllCredits() ([]*Credit, error)
+	SetCredits(bookID int, role ContributorRole, contributorIDs []int) error
+	DeleteCreditsByBookID(bookID int) error
+}
+
+// TagRepository 标签数据仓库接口
+type TagRepository interface {
+	Create(t *Tag) error
+	Update(t *Tag) error
+	Delete(id int) error
+	GetByID(id int) (*Tag, error)
+	GetByName(name string) (*Tag, error)
+	GetAll() ([]*Tag, error)
+	GetByBookID(bookID int) ([]*Tag, error)
+	GetAllBookTags() ([]*BookTag, error)
+	GetBookIDs(tagID int) ([]int, error)
+	SetBookTags(bookID int, kind TagKind, tagIDs []int) error
+	DeleteByBookID(bookID int) error
+	Merge(fromID, intoID int) error
+}
+
+// SeriesRepository 丛书数据仓库接口
+type SeriesRepository interface {
+	Create(s *Series) error
+	Update(s *Series) error
+	Delete(id int) error
+	GetByID(id int) (*Series, error)
+	GetByName(name string) (*Series, error)
+	GetAll() ([]*Series, error)
+	GetVolumes(seriesID int) ([]*SeriesVolume, error)
+	GetVolumeByBookID(bookID int) (*SeriesVolume, error)
+	SetVolume(v *SeriesVolume) error
+	DeleteVolume(bookID int) error
+}
+
+// ReviewRepository 读者评价数据仓库接口
+type ReviewRepository interface {
+	Create(review *Review) error
+	Update(review *Review) error
+	GetByID(id int) (*Review, error)
+	GetByBookAndUser(bookID, userID int) (*Review, error)
+	GetByBookID(bookID int) ([]*Review, error)
+	GetByStatus(status ReviewStatus) ([]*Review, error)
+	DeleteByBookID(bookID int) error
+}
+
+// ClassificationRepository 分类表数据仓库接口
+type ClassificationRepository interface {
+	Create(c *Classification) error
+	Update(c *Classification) error
+	Delete(id int) error
+	GetAll() ([]*Classification, error)
+}
+
+// PolicyRepository 流通规则数据仓库接口
+type PolicyRepository interface {
+	Create(policy *CirculationPolicy) error
+	Update(policy *CirculationPolicy) error
+	Delete(id int) error
+	GetByID(id int) (*CirculationPolicy, error)
+	GetAll() ([]*CirculationPolicy, error)
+}
+
+// Repositories 数据仓库集合
+type Repositories struct {
+	Books           BookRepository
+	Users           UserRepository
+	Borrows         BorrowRepository
+	Holds           HoldRepository
+	Fines           FineRepository
+	Policies        PolicyRepository
+	Copies          CopyRepository
+	APITokens       APITokenRepository
+	Contributors    ContributorRepository
+	Classifications ClassificationRepository
+	Tags            TagRepository
+	Series          SeriesRepository
+	Reviews         ReviewRepository
+}
+
+var (
+	repos      = NewMemoryRepositories()
+	reposMutex sync.RWMutex
+)
+
+// SetRepositories 设置模型层使用的数据仓库（程序启动时调用）
+func SetRepositories(r *Repositories) {
+	reposMutex.Lock()
+	defer reposMutex.Unlock()
+	repos = r
+
+	// 切换仓库后检索索引需要重新构建
+	bookIndex.reset()
+}
+
+// GetRepositories 获取当前使用的数据仓库
+func GetRepositories() *Repositories {
+	reposMutex.RLock()
+	defer reposMutex.RUnlock()
+	return repos
+}
+
+// NewMemoryRepositories 创建基于内存的数据仓库（用于测试和无数据库环境）
+func NewMemoryRepositories() *Repositories {
+	return &Repositories{
+		Books:           NewMemoryBookRepository(),
+		Users:           NewMemoryUserRepository(),
+		Borrows:         NewMemoryBorrowRepository(),
+		Holds:           NewMemoryHoldRepository(),
+		Fines:           NewMemoryFineRepository(),
+		Policies:        NewMemoryPolicyRepository(),
+		Copies:          NewMemoryCopyRepository(),
+		APITokens:       NewMemoryAPITokenRepository(),
+		Contributors:    NewMemoryContributorRepository(),
+		Classifications: NewMemoryClassificationRepository(),
+		Tags:            NewMemoryTagRepository(),
+		Series:          NewMemorySeriesRepository(),
+		Reviews:         NewMemoryReviewRepository(),
+	}
+}
+
+// NewMySQLRepositories 创建基于MySQL的数据仓库
+func NewMySQLRepositories(db *sql.DB) *Repositories {
+	return &Repositories{
+		Books:           NewMySQLBookRepository(db),
+		Users:           NewMySQLUserRepository(db),
+		Borrows:         NewMySQLBorrowRepository(db),
+		Holds:           NewMySQLHoldRepository(db),
+		Fines:           NewMySQLFineRepository(db),
+		Policies:        NewMySQLPolicyRepository(db),
+		Copies:          NewMySQLCopyRepository(db),
+		APITokens:       NewMySQLAPITokenRepository(db),
+		Contributors:    NewMySQLContributorRepository(db),
+		Classifications: NewMySQLClassificationRepository(db),
+		Tags:            NewMySQLTagRepository(db),
+		Series:          NewMySQLSeriesRepository(db),
+		Reviews:         NewMySQLReviewRepository(db),
+	}
+}
+
+// likePattern 将查询词转义为LIKE模式
+func likePattern(query string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(query) + "%"
+}
+
+// rowScanner 兼容 *sql.Row 与 *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// checkAffected 检查更新/删除是否命中记录
+func checkAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// nullTime 将零值时间转换为数据库NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// nullInt 将零值ID转换为数据库NULL
+func nullInt(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
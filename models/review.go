@@ -0,0 +1,265 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrReviewNotFound 评价不存在
+var ErrReviewNotFound = errors.New("评价不存在")
+
+// ErrReviewNotAllowed 读者没有借阅过该图书，不能评价
+var ErrReviewNotAllowed = errors.New("借阅过这本书后才能评价")
+
+// 评价限制
+const (
+	MinRating              = 1
+	MaxRating              = 5
+	maxReviewContentLength = 2000
+)
+
+// ReviewStatus 评价审核状态
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"  // 待审核
+	ReviewApproved ReviewStatus = "approved" // 已通过，公开显示并计入评分
+	ReviewHidden   ReviewStatus = "hidden"   // 已隐藏
+)
+
+// Review 读者对图书的评分和评价，每位读者对每本书只有一条评价
+type Review struct {
+	ID        int          `json:"id"`
+	BookID    int          `json:"book_id"`
+	UserID    int          `json:"user_id"`
+	Rating    int          `json:"rating"`
+	Content   string       `json:"content"`
+	Status    ReviewStatus `json:"status"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// reviewMutex 保证评价写入与评分汇总的原子性，加锁顺序在 bookMutex 之后
+var reviewMutex sync.Mutex
+
+// StatusText 评价状态文本
+func (r *Review) StatusText() string {
+	switch r.Status {
+	case ReviewPending:
+		return "待审核"
+	case ReviewApproved:
+		return "已通过"
+	case ReviewHidden:
+		return "已隐藏"
+	}
+	return string(r.Status)
+}
+
+// Stars 评分对应的星级，用于模板中逐个显示
+func (r *Review) Stars() []bool {
+	return ratingStars(r.Rating)
+}
+
+// RatingStars 平均评分四舍五入后的星级
+func (b *Book) RatingStars() []bool {
+	return ratingStars(int(math.Round(b.RatingAverage)))
+}
+
+// ratingStars 返回 MaxRating 个元素，前 rating 个为 true
+func ratingStars(rating int) []bool {
+	stars := make([]bool, MaxRating)
+	for i := 0; i < rating && i < MaxRating; i++ {
+		stars[i] = true
+	}
+	return stars
+}
+
+// ratingKey 平均评分×100取整，作为排序和游标的键
+func (b *Book) ratingKey() int {
+	return int(math.Round(b.RatingAverage * 100))
+}
+
+// CanReview 读者是否借阅过该图书（含已归还的借阅）
+func CanReview(userID, bookID int) bool {
+	for _, record := range GetBorrowRecordsByUserID(userID) {
+		if record.BookID == bookID {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReview 校验评分和评价内容
+func validateReview(rating int, content string) error {
+	if rating < MinRating || rating > MaxRating {
+		return fmt.Errorf("评分须在%d到%d之间", MinRating, MaxRating)
+	}
+	if len([]rune(content)) > maxReviewContentLength {
+		return fmt.Errorf("评价不能超过%d个字符", maxReviewContentLength)
+	}
+	return nil
+}
+
+// SubmitReview 读者提交或修改对图书的评价，修改后需重新审核
+func SubmitReview(userID, bookID, rating int, content string) (*Review, error) {
+	content = strings.TrimSpace(content)
+	if err := validateReview(rating, content); err != nil {
+		return nil, err
+	}
+	if _, err := GetUserByID(userID); err != nil {
+		return nil, err
+	}
+	if !CanReview(userID, bookID) {
+		return nil, ErrReviewNotAllowed
+	}
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	if _, err := GetRepositories().Books.GetByID(bookID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	review, err := GetRepositories().Reviews.GetByBookAndUser(bookID, userID)
+	if errors.Is(err, ErrReviewNotFound) {
+		review = &Review{BookID: bookID, UserID: userID, Rating: rating, Content: content,
+			Status: ReviewPending, CreatedAt: now, UpdatedAt: now}
+		if err := GetRepositories().Reviews.Create(review); err != nil {
+			return nil, err
+		}
+		return review, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	wasApproved := review.Status == ReviewApproved
+	review.Rating = rating
+	review.Content = content
+	review.Status = ReviewPending
+	review.UpdatedAt = now
+	if err := GetRepositories().Reviews.Update(review); err != nil {
+		return nil, err
+	}
+	if wasApproved {
+		refreshBookRatingLocked(bookID)
+	}
+	return review, nil
+}
+
+// ModerateReview 审核评价：通过后公开显示并计入评分，隐藏后不再显示
+func ModerateReview(id int, status ReviewStatus) (*Review, error) {
+	if status != ReviewApproved && status != ReviewHidden {
+		return nil, errors.New("无效的审核结果")
+	}
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	review, err := GetRepositories().Reviews.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status == status {
+		return review, nil
+	}
+
+	review.Status = status
+	if err := GetRepositories().Reviews.Update(review); err != nil {
+		return nil, err
+	}
+	refreshBookRatingLocked(review.BookID)
+	return review, nil
+}
+
+// refreshBookRatingLocked 按已通过审核的评价重新汇总图书评分，调用方需持有 bookMutex 和 reviewMutex
+func refreshBookRatingLocked(bookID int) {
+	book, err := GetRepositories().Books.GetByID(bookID)
+	if err != nil {
+		log.Printf("汇总图书评分失败: %v", err)
+		return
+	}
+	reviews, err := GetRepositories().Reviews.GetByBookID(bookID)
+	if err != nil {
+		log.Printf("汇总图书评分失败: %v", err)
+		return
+	}
+
+	sum, count := 0, 0
+	for _, review := range reviews {
+		if review.Status == ReviewApproved {
+			sum += review.Rating
+			count++
+		}
+	}
+	book.RatingCount = count
+	book.RatingAverage = 0
+	if count > 0 {
+		book.RatingAverage = math.Round(float64(sum)/float64(count)*100) / 100
+	}
+	if err := GetRepositories().Books.Update(book); err != nil {
+		log.Printf("更新图书评分失败: %v", err)
+	}
+}
+
+// deleteBookReviewsLocked 删除图书时删除其评价，调用方需持有 bookMutex
+func deleteBookReviewsLocked(bookID int) error {
+	reviewMutex.Lock()
+	defer reviewMutex.Unlock()
+
+	return GetRepositories().Reviews.DeleteByBookID(bookID)
+}
+
+// GetReviewByID 根据ID获取评价
+func GetReviewByID(id int) (*Review, error) {
+	return GetRepositories().Reviews.GetByID(id)
+}
+
+// GetBookReviews 获取图书已通过审核的评价，最近更新的在前
+func GetBookReviews(bookID int) []*Review {
+	reviews, err := GetRepositories().Reviews.GetByBookID(bookID)
+	if err != nil {
+		log.Printf("获取图书评价失败: %v", err)
+		return nil
+	}
+
+	var approved []*Review
+	for _, review := range reviews {
+		if review.Status == ReviewApproved {
+			approved = append(approved, review)
+		}
+	}
+	return approved
+}
+
+// GetUserReview 获取读者对图书的评价，尚未评价时返回 nil
+func GetUserReview(userID, bookID int) *Review {
+	review, err := GetRepositories().Reviews.GetByBookAndUser(bookID, userID)
+	if err != nil {
+		if !errors.Is(err, ErrReviewNotFound) {
+			log.Printf("获取读者评价失败: %v", err)
+		}
+		return nil
+	}
+	return review
+}
+
+// GetReviewsByStatus 按审核状态获取评价，status 为空时返回全部，最近更新的在前
+func GetReviewsByStatus(status ReviewStatus) []*Review {
+	reviews, err := GetRepositories().Reviews.GetByStatus(status)
+	if err != nil {
+		log.Printf("获取评价列表失败: %v", err)
+		return nil
+	}
+	return reviews
+}
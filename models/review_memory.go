@@ -0,0 +1,115 @@
+package models
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryReviewRepository 基于内存切片的评价仓库实现
+type MemoryReviewRepository struct {
+	mu      sync.RWMutex
+	reviews []*Review
+	nextID  int
+}
+
+// NewMemoryReviewRepository 创建内存评价仓库
+func NewMemoryReviewRepository() *MemoryReviewRepository {
+	return &MemoryReviewRepository{nextID: 1}
+}
+
+// Create 添加评价并分配ID
+func (r *MemoryReviewRepository) Create(review *Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review.ID = r.nextID
+	copied := *review
+	r.reviews = append(r.reviews, &copied)
+	r.nextID++
+	return nil
+}
+
+// Update 更新评价
+func (r *MemoryReviewRepository) Update(review *Review) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.reviews {
+		if existing.ID == review.ID {
+			copied := *review
+			r.reviews[i] = &copied
+			return nil
+		}
+	}
+	return ErrReviewNotFound
+}
+
+// GetByID 根据ID获取评价
+func (r *MemoryReviewRepository) GetByID(id int) (*Review, error) {
+	return r.find(func(review *Review) bool { return review.ID == id })
+}
+
+// GetByBookAndUser 获取读者对图书的评价
+func (r *MemoryReviewRepository) GetByBookAndUser(bookID, userID int) (*Review, error) {
+	return r.find(func(review *Review) bool { return review.BookID == bookID && review.UserID == userID })
+}
+
+// GetByBookID 获取图书的全部评价，最近更新的在前
+func (r *MemoryReviewRepository) GetByBookID(bookID int) ([]*Review, error) {
+	return r.filter(func(review *Review) bool { return review.BookID == bookID }), nil
+}
+
+// GetByStatus 按审核状态获取评价，status 为空时返回全部，最近更新的在前
+func (r *MemoryReviewRepository) GetByStatus(status ReviewStatus) ([]*Review, error) {
+	return r.filter(func(review *Review) bool { return status == "" || review.Status == status }), nil
+}
+
+// DeleteByBookID 删除图书的全部评价
+func (r *MemoryReviewRepository) DeleteByBookID(bookID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.reviews[:0]
+	for _, review := range r.reviews {
+		if review.BookID != bookID {
+			kept = append(kept, review)
+		}
+	}
+	r.reviews = kept
+	return nil
+}
+
+// find 返回第一条满足条件的评价副本
+func (r *MemoryReviewRepository) find(match func(*Review) bool) (*Review, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, review := range r.reviews {
+		if match(review) {
+			copied := *review
+			return &copied, nil
+		}
+	}
+	return nil, ErrReviewNotFound
+}
+
+// filter 返回满足条件的评价副本，最近更新的在前
+func (r *MemoryReviewRepository) filter(match func(*Review) bool) []*Review {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Review
+	for _, review := range r.reviews {
+		if match(review) {
+			copied := *review
+			result = append(result, &copied)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].UpdatedAt.Equal(result[j].UpdatedAt) {
+			return result[i].UpdatedAt.After(result[j].UpdatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	return result
+}
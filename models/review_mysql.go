@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// reviewColumns 评价表查询列
+const reviewColumns = "id, book_id, user_id, rating, content, status, created_at, updated_at"
+
+// MySQLReviewRepository 基于MySQL的评价仓库实现
+type MySQLReviewRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLReviewRepository 创建MySQL评价仓库
+func NewMySQLReviewRepository(db *sql.DB) *MySQLReviewRepository {
+	return &MySQLReviewRepository{db: db}
+}
+
+// Create 插入评价并回填ID
+func (r *MySQLReviewRepository) Create(review *Review) error {
+	result, err := r.db.Exec(`
+		INSERT INTO reviews (book_id, user_id, rating, content, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		review.BookID, review.UserID, review.Rating, review.Content, review.Status, review.CreatedAt, review.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("创建评价失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取评价ID失败: %w", err)
+	}
+	review.ID = int(id)
+	return nil
+}
+
+// Update 更新评价
+func (r *MySQLReviewRepository) Update(review *Review) error {
+	_, err := r.db.Exec("UPDATE reviews SET rating = ?, content = ?, status = ?, updated_at = ? WHERE id = ?",
+		review.Rating, review.Content, review.Status, review.UpdatedAt, review.ID)
+	if err != nil {
+		return fmt.Errorf("更新评价失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取评价
+func (r *MySQLReviewRepository) GetByID(id int) (*Review, error) {
+	return scanReview(r.db.QueryRow("SELECT "+reviewColumns+" FROM reviews WHERE id = ?", id))
+}
+
+// GetByBookAndUser 获取读者对图书的评价
+func (r *MySQLReviewRepository) GetByBookAndUser(bookID, userID int) (*Review, error) {
+	return scanReview(r.db.QueryRow("SELECT "+reviewColumns+" FROM reviews WHERE book_id = ? AND user_id = ?", bookID, userID))
+}
+
+// GetByBookID 获取图书的全部评价，最近更新的在前
+func (r *MySQLReviewRepository) GetByBookID(bookID int) ([]*Review, error) {
+	return r.query("SELECT "+reviewColumns+" FROM reviews WHERE book_id = ? ORDER BY updated_at DESC, id DESC", bookID)
+}
+
+// GetByStatus 按审核状态获取评价，status 为空时返回全部，最近更新的在前
+func (r *MySQLReviewRepository) GetByStatus(status ReviewStatus) ([]*Review, error) {
+	if status == "" {
+		return r.query("SELECT " + reviewColumns + " FROM reviews ORDER BY updated_at DESC, id DESC")
+	}
+	return r.query("SELECT "+reviewColumns+" FROM reviews WHERE status = ? ORDER BY updated_at DESC, id DESC", status)
+}
+
+// DeleteByBookID 删除图书的全部评价
+func (r *MySQLReviewRepository) DeleteByBookID(bookID int) error {
+	if _, err := r.db.Exec("DELETE FROM reviews WHERE book_id = ?", bookID); err != nil {
+		return fmt.Errorf("删除图书评价失败: %w", err)
+	}
+	return nil
+}
+
+// query 执行查询并扫描评价列表
+func (r *MySQLReviewRepository) query(query string, args ...interface{}) ([]*Review, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询评价失败: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*Review
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// scanReview 扫描一行评价数据
+func scanReview(row rowScanner) (*Review, error) {
+	review := &Review{}
+	err := row.Scan(&review.ID, &review.BookID, &review.UserID, &review.Rating, &review.Content,
+		&review.Status, &review.CreatedAt, &review.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReviewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
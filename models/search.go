@@ -0,0 +1,479 @@
+package models
+
+import (
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SearchField 可检索的图书字段
+type SearchField string
+
+const (
+	SearchAll         SearchField = ""            // 所有字段
+	SearchTitle       SearchField = "title"       // 书名
+	SearchAuthor      SearchField = "author"      // 作者
+	SearchISBN        SearchField = "isbn"        // ISBN
+	SearchCategory    SearchField = "category"    // 分类
+	SearchTag         SearchField = "tag"         // 标签和主题词
+	SearchDescription SearchField = "description" // 简介
+)
+
+// searchFieldWeights 各字段命中时的权重
+var searchFieldWeights = map[SearchField]float64{
+	SearchTitle:       3,
+	SearchAuthor:      2.5,
+	SearchISBN:        2,
+	SearchCategory:    1.5,
+	SearchTag:         1.5,
+	SearchDescription: 1,
+}
+
+// searchFieldAliases 查询语法中的字段名，支持中文别名
+var searchFieldAliases = map[string]SearchField{
+	"title":       SearchTitle,
+	"书名":          SearchTitle,
+	"author":      SearchAuthor,
+	"作者":          SearchAuthor,
+	"isbn":        SearchISBN,
+	"category":    SearchCategory,
+	"分类":          SearchCategory,
+	"tag":         SearchTag,
+	"标签":          SearchTag,
+	"主题":          SearchTag,
+	"description": SearchDescription,
+	"简介":          SearchDescription,
+}
+
+// 匹配方式对得分的折扣
+const (
+	exactMatchBoost   = 1.0
+	prefixMatchBoost  = 0.8
+	pinyinMatchBoost  = 0.7
+	initialMatchBoost = 0.5
+	maxPrefixExpand   = 50 // 单个查询词最多展开的前缀匹配词数
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// SearchClause 查询子句，多个子句之间为"且"关系
+type SearchClause struct {
+	Field SearchField
+	Text  string
+}
+
+// ParseSearchQuery 解析查询语句，支持 author:刘慈欣、title:"三体 全集" 等字段限定写法
+func ParseSearchQuery(query string) []SearchClause {
+	var clauses []SearchClause
+	runes := []rune(strings.TrimSpace(query))
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		// 读取一个词，引号内的空格不作为分隔
+		var word []rune
+		quoted := false
+		for ; i < len(runes); i++ {
+			r := runes[i]
+			if r == '"' {
+				quoted = !quoted
+				continue
+			}
+			if unicode.IsSpace(r) && !quoted {
+				break
+			}
+			word = append(word, r)
+		}
+
+		clause := SearchClause{Text: string(word)}
+		if name, value, found := strings.Cut(clause.Text, ":"); found {
+			if field, ok := searchFieldAliases[strings.ToLower(name)]; ok {
+				clause = SearchClause{Field: field, Text: value}
+			}
+		}
+		if strings.TrimSpace(clause.Text) != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// searchIndex 图书倒排索引
+type searchIndex struct {
+	mu       sync.RWMutex
+	built    bool
+	postings map[string]map[int]map[SearchField]int // 词 -> 图书ID -> 字段 -> 词频
+	docTerms map[int][]string                       // 图书包含的词，用于删除
+	fieldLen map[int]map[SearchField]int            // 图书各字段的词数
+	totalLen map[SearchField]int                    // 各字段总词数
+	terms    []string                               // 有序词表，用于前缀匹配
+	sorted   bool
+	version  int64 // 构建索引时的图书目录版本号
+}
+
+// bookIndex 全局图书检索索引，首次检索时从仓库构建；多个实例共用数据库时，
+// 每次检索前比较图书目录版本号，其他实例修改过图书后重新构建
+var bookIndex = newSearchIndex()
+
+// newSearchIndex 创建空索引
+func newSearchIndex() *searchIndex {
+	idx := &searchIndex{}
+	idx.clear()
+	return idx
+}
+
+// clear 清空索引，调用方需持有写锁
+func (idx *searchIndex) clear() {
+	idx.postings = make(map[string]map[int]map[SearchField]int)
+	idx.docTerms = make(map[int][]string)
+	idx.fieldLen = make(map[int]map[SearchField]int)
+	idx.totalLen = make(map[SearchField]int)
+	idx.terms = nil
+	idx.sorted = false
+}
+
+// reset 标记索引失效，下次检索时重新构建
+func (idx *searchIndex) reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.clear()
+	idx.built = false
+}
+
+// invalidate 修改了大量图书后使各实例的索引失效
+func (idx *searchIndex) invalidate() {
+	bumpCatalogVersion()
+	idx.reset()
+}
+
+// ensureBuilt 索引未构建或图书目录版本号已变化时从图书仓库全量构建
+func (idx *searchIndex) ensureBuilt() error {
+	// 先读取版本号再读取图书，构建期间发生的修改会使下次检索重新构建
+	version, err := GetRepositories().Books.CatalogVersion()
+	if err != nil {
+		return err
+	}
+	idx.mu.RLock()
+	current := idx.built && idx.version == version
+	idx.mu.RUnlock()
+	if current {
+		return nil
+	}
+
+	books, err := GetRepositories().Books.GetAll()
+	if err != nil {
+		return err
+	}
+	credits, err := GetRepositories().Contributors.GetAllCredits()
+	if err != nil {
+		return err
+	}
+	creditsByBook := make(map[int][]*Credit)
+	for _, credit := range credits {
+		creditsByBook[credit.BookID] = append(creditsByBook[credit.BookID], credit)
+	}
+	links, err := GetRepositories().Tags.GetAllBookTags()
+	if err != nil {
+		return err
+	}
+	tagsByBook := make(map[int][]*Tag)
+	for _, link := range links {
+		tagsByBook[link.BookID] = append(tagsByBook[link.BookID], &Tag{ID: link.TagID, Name: link.Name, Kind: link.Kind})
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.built && idx.version == version {
+		return nil
+	}
+	idx.clear()
+	for _, book := range books {
+		idx.addLocked(book, creditSearchText(creditsByBook[book.ID]), tagSearchText(tagsByBook[book.ID]))
+	}
+	idx.built = true
+	idx.version = version
+	return nil
+}
+
+// update 更新图书的索引并递增图书目录版本号，索引尚未构建时只递增版本号
+func (idx *searchIndex) update(book *Book) {
+	version, ok := bumpCatalogVersion()
+	idx.mu.RLock()
+	built := idx.built
+	idx.mu.RUnlock()
+	if !built {
+		return
+	}
+	credits := creditSearchText(GetBookCredits(book.ID))
+	tags := tagSearchText(GetBookTags(book.ID))
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.advanceLocked(version, ok) {
+		return
+	}
+	idx.removeLocked(book.ID)
+	idx.addLocked(book, credits, tags)
+}
+
+// remove 从索引中删除图书并递增图书目录版本号
+func (idx *searchIndex) remove(bookID int) {
+	version, ok := bumpCatalogVersion()
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.advanceLocked(version, ok) {
+		idx.removeLocked(bookID)
+	}
+}
+
+// advanceLocked 本实例修改图书后记录新的版本号，返回能否在现有索引上增量更新；
+// 版本号不连续说明其他实例也修改过图书，此时清空索引，下次检索时重新构建。调用方需持有写锁
+func (idx *searchIndex) advanceLocked(version int64, ok bool) bool {
+	if !idx.built {
+		return false
+	}
+	if !ok || version != idx.version+1 {
+		idx.clear()
+		idx.built = false
+		return false
+	}
+	idx.version = version
+	return true
+}
+
+// bumpCatalogVersion 递增图书目录版本号，使其他实例的检索索引失效
+func bumpCatalogVersion() (int64, bool) {
+	version, err := GetRepositories().Books.BumpCatalogVersion()
+	if err != nil {
+		log.Printf("更新图书目录版本失败: %v", err)
+		return 0, false
+	}
+	return version, true
+}
+
+// bookSearchFields 图书各字段的原文，译者、编者等责任者并入作者字段
+func bookSearchFields(book *Book, credits, tags string) map[SearchField]string {
+	return map[SearchField]string{
+		SearchTitle:       book.Title,
+		SearchAuthor:      strings.TrimSpace(book.Author + " " + credits),
+		SearchISBN:        book.ISBN,
+		SearchCategory:    book.Category,
+		SearchTag:         tags,
+		SearchDescription: book.Description,
+	}
+}
+
+// addLocked 将图书加入索引，credits 为除作者外的责任者姓名，tags 为标签名称，调用方需持有写锁
+func (idx *searchIndex) addLocked(book *Book, credits, tags string) {
+	lengths := make(map[SearchField]int)
+	seen := make(map[string]bool)
+
+	for field, text := range bookSearchFields(book, credits, tags) {
+		// 书名和作者额外索引拼音
+		withPinyin := field == SearchTitle || field == SearchAuthor
+		terms := indexTerms(text, withPinyin)
+		lengths[field] = len(terms)
+		idx.totalLen[field] += len(terms)
+
+		for _, term := range terms {
+			docs := idx.postings[term]
+			if docs == nil {
+				docs = make(map[int]map[SearchField]int)
+				idx.postings[term] = docs
+				idx.sorted = false
+			}
+			if docs[book.ID] == nil {
+				docs[book.ID] = make(map[SearchField]int)
+			}
+			docs[book.ID][field]++
+
+			if !seen[term] {
+				seen[term] = true
+				idx.docTerms[book.ID] = append(idx.docTerms[book.ID], term)
+			}
+		}
+	}
+	idx.fieldLen[book.ID] = lengths
+}
+
+// removeLocked 从索引中删除图书，调用方需持有写锁
+func (idx *searchIndex) removeLocked(bookID int) {
+	for _, term := range idx.docTerms[bookID] {
+		delete(idx.postings[term], bookID)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+			idx.sorted = false
+		}
+	}
+	for field, n := range idx.fieldLen[bookID] {
+		idx.totalLen[field] -= n
+	}
+	delete(idx.docTerms, bookID)
+	delete(idx.fieldLen, bookID)
+}
+
+// prefixTerms 返回以prefix开头的索引词，调用方需持有读锁
+func (idx *searchIndex) prefixTerms(prefix string) []string {
+	start := sort.SearchStrings(idx.terms, prefix)
+	var result []string
+	for i := start; i < len(idx.terms) && strings.HasPrefix(idx.terms[i], prefix); i++ {
+		result = append(result, idx.terms[i])
+		if len(result) >= maxPrefixExpand {
+			break
+		}
+	}
+	return result
+}
+
+// sortTerms 重建有序词表
+func (idx *searchIndex) sortTerms() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if idx.sorted {
+		return
+	}
+	idx.terms = idx.terms[:0]
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+	idx.sorted = true
+}
+
+// scoreTerm 计算一个索引词在指定字段中的BM25得分，结果累加到scores
+func (idx *searchIndex) scoreTerm(term string, field SearchField, boost float64, scores map[int]float64) {
+	docs := idx.postings[term]
+	if len(docs) == 0 {
+		return
+	}
+
+	n := float64(len(idx.fieldLen))
+	idf := math.Log(1 + (n-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+
+	for bookID, fields := range docs {
+		for f, tf := range fields {
+			if field != SearchAll && f != field {
+				continue
+			}
+			avg := float64(idx.totalLen[f]) / n
+			if avg == 0 {
+				avg = 1
+			}
+			length := float64(idx.fieldLen[bookID][f])
+			norm := float64(tf) * (bm25K1 + 1) / (float64(tf) + bm25K1*(1-bm25B+bm25B*length/avg))
+			scores[bookID] += searchFieldWeights[f] * idf * norm * boost
+		}
+	}
+}
+
+// matchClause 计算一个查询子句的命中图书和得分，子句内的各个词需全部命中
+func (idx *searchIndex) matchClause(clause SearchClause) map[int]float64 {
+	var result map[int]float64
+
+	for _, seg := range queryTerms(clause.Text) {
+		scores := make(map[int]float64)
+		if seg.CJK {
+			idx.scoreTerm(seg.Text, clause.Field, exactMatchBoost, scores)
+		} else {
+			// 字母数字词：整词累加得分；前缀、拼音和首字母等展开匹配只取每本书的最高分，
+			// 避免长书名因拼音组合多而得分虚高
+			idx.scoreTerm(seg.Text, clause.Field, exactMatchBoost, scores)
+
+			best := make(map[int]float64)
+			expand := func(term string, boost float64) {
+				expanded := make(map[int]float64)
+				idx.scoreTerm(term, clause.Field, boost, expanded)
+				for bookID, score := range expanded {
+					best[bookID] = math.Max(best[bookID], score)
+				}
+			}
+			for _, term := range idx.prefixTerms(seg.Text) {
+				if term != seg.Text && !strings.Contains(term, ":") {
+					expand(term, prefixMatchBoost)
+				}
+			}
+			if isPinyinQuery(seg.Text) {
+				for _, term := range idx.prefixTerms(pinyinTermPrefix + seg.Text) {
+					expand(term, pinyinMatchBoost)
+				}
+				for _, term := range idx.prefixTerms(initialTermPrefix + seg.Text) {
+					expand(term, initialMatchBoost)
+				}
+			}
+			for bookID, score := range best {
+				scores[bookID] += score
+			}
+		}
+
+		if result == nil {
+			result = scores
+			continue
+		}
+		for bookID := range result {
+			if score, ok := scores[bookID]; ok {
+				result[bookID] += score
+			} else {
+				delete(result, bookID)
+			}
+		}
+	}
+	return result
+}
+
+// search 执行检索，返回按得分降序排列的图书ID
+func (idx *searchIndex) search(clauses []SearchClause) []int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var scores map[int]float64
+	for _, clause := range clauses {
+		matched := idx.matchClause(clause)
+		if scores == nil {
+			scores = matched
+			continue
+		}
+		for bookID := range scores {
+			if score, ok := matched[bookID]; ok {
+				scores[bookID] += score
+			} else {
+				delete(scores, bookID)
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(scores))
+	for bookID := range scores {
+		ids = append(ids, bookID)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// SearchBookIDs 全文检索图书，返回按相关度排序的图书ID
+func SearchBookIDs(query string) ([]int, error) {
+	clauses := ParseSearchQuery(query)
+	if len(clauses) == 0 {
+		return []int{}, nil
+	}
+
+	if err := bookIndex.ensureBuilt(); err != nil {
+		return nil, err
+	}
+	bookIndex.sortTerms()
+	return bookIndex.search(clauses), nil
+}
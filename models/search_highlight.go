@@ -0,0 +1,108 @@
+package models
+
+import (
+	"html"
+	"strings"
+)
+
+// Highlight 将文本中与检索语句匹配的片段用 <mark> 标出，返回转义后的HTML；
+// field 为文本所属字段，限定了其他字段的查询子句不参与高亮
+func Highlight(text string, field SearchField, query string) string {
+	runes := []rune(text)
+	marked := make([]bool, len(runes))
+	lower := []rune(strings.ToLower(text))
+
+	for _, clause := range ParseSearchQuery(query) {
+		if clause.Field != SearchAll && clause.Field != field {
+			continue
+		}
+		for _, seg := range splitSegments(normalizeISBNText(clause.Text)) {
+			if markAll(lower, seg.Runes, marked) || !seg.CJK {
+				if !seg.CJK && isPinyinQuery(seg.Text) {
+					markPinyin(runes, seg.Text, marked)
+				}
+				continue
+			}
+			// 整段未命中时按双字分别标记
+			for i := 0; i+1 < len(seg.Runes); i++ {
+				markAll(lower, seg.Runes[i:i+2], marked)
+			}
+		}
+	}
+
+	var b strings.Builder
+	open := false
+	for i, r := range runes {
+		if marked[i] && !open {
+			b.WriteString("<mark>")
+			open = true
+		} else if !marked[i] && open {
+			b.WriteString("</mark>")
+			open = false
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	return b.String()
+}
+
+// markAll 标记text中所有与word相同的片段，返回是否有命中
+func markAll(text, word []rune, marked []bool) bool {
+	if len(word) == 0 {
+		return false
+	}
+
+	found := false
+	for i := 0; i+len(word) <= len(text); i++ {
+		match := true
+		for j, r := range word {
+			if text[i+j] != r {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = true
+			for j := range word {
+				marked[i+j] = true
+			}
+		}
+	}
+	return found
+}
+
+// markPinyin 标记拼音全拼或首字母与word匹配的汉字片段
+func markPinyin(runes []rune, word string, marked []bool) {
+	for i := range runes {
+		if !isCJK(runes[i]) {
+			continue
+		}
+
+		var full, initials strings.Builder
+		for j := i; j < len(runes) && isCJK(runes[j]); j++ {
+			syllable := runePinyin(runes[j])
+			if syllable == "" {
+				break
+			}
+			full.WriteString(syllable)
+			initials.WriteByte(syllable[0])
+
+			if coversWord(full.String(), word) || coversWord(initials.String(), word) {
+				for k := i; k <= j; k++ {
+					marked[k] = true
+				}
+				break
+			}
+			if !strings.HasPrefix(word, full.String()) && !strings.HasPrefix(word, initials.String()) {
+				break
+			}
+		}
+	}
+}
+
+// coversWord 判断拼音串是否刚好覆盖了查询词（查询词可以只写到最后一个音节的一部分）
+func coversWord(pinyin, word string) bool {
+	return len(pinyin) >= len(word) && strings.HasPrefix(pinyin, word)
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// 检索词前缀：拼音全拼和拼音首字母与普通词分开存放，避免与英文单词混淆
+const (
+	pinyinTermPrefix  = "py:"
+	initialTermPrefix = "in:"
+)
+
+// maxPinyinRun 生成拼音后缀组合的最大汉字片段长度
+const maxPinyinRun = 20
+
+// textSegment 文本片段，连续的汉字或连续的字母数字
+type textSegment struct {
+	Text  string
+	Runes []rune
+	Start int // 在原文中的起始字符位置
+	CJK   bool
+}
+
+// isCJK 判断字符是否为汉字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
+
+// splitSegments 将文本切分为汉字片段和字母数字片段，其余字符作为分隔符
+func splitSegments(text string) []textSegment {
+	var segments []textSegment
+	var current []rune
+	start, cjk := 0, false
+
+	flush := func() {
+		if len(current) > 0 {
+			segments = append(segments, textSegment{Text: string(current), Runes: current, Start: start, CJK: cjk})
+			current = nil
+		}
+	}
+
+	for i, r := range []rune(text) {
+		switch {
+		case isCJK(r):
+			if len(current) > 0 && !cjk {
+				flush()
+			}
+			if len(current) == 0 {
+				start, cjk = i, true
+			}
+			current = append(current, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if len(current) > 0 && cjk {
+				flush()
+			}
+			if len(current) == 0 {
+				start, cjk = i, false
+			}
+			current = append(current, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return segments
+}
+
+// normalizeISBNText 去掉ISBN中的连字符和空格，非ISBN文本原样返回
+func normalizeISBNText(text string) string {
+	stripped := strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(text))
+	if len(stripped) < 10 {
+		return text
+	}
+	for _, r := range stripped {
+		if !unicode.IsDigit(r) && r != 'x' && r != 'X' {
+			return text
+		}
+	}
+	return strings.ToLower(stripped)
+}
+
+// indexTerms 生成字段的索引词：汉字切为单字和相邻双字，字母数字按整词索引
+func indexTerms(text string, withPinyin bool) []string {
+	var terms []string
+	for _, seg := range splitSegments(normalizeISBNText(text)) {
+		if !seg.CJK {
+			terms = append(terms, seg.Text)
+			continue
+		}
+
+		for i, r := range seg.Runes {
+			terms = append(terms, string(r))
+			if i+1 < len(seg.Runes) {
+				terms = append(terms, string(seg.Runes[i:i+2]))
+			}
+		}
+		if withPinyin {
+			terms = append(terms, pinyinTerms(seg.Runes)...)
+		}
+	}
+	return terms
+}
+
+// queryTerms 生成查询词：单个汉字直接匹配，多个汉字按相邻双字匹配
+func queryTerms(text string) []textSegment {
+	var terms []textSegment
+	for _, seg := range splitSegments(normalizeISBNText(text)) {
+		if !seg.CJK || len(seg.Runes) == 1 {
+			terms = append(terms, seg)
+			continue
+		}
+		for i := 0; i+1 < len(seg.Runes); i++ {
+			terms = append(terms, textSegment{Text: string(seg.Runes[i : i+2]), Runes: seg.Runes[i : i+2], CJK: true})
+		}
+	}
+	return terms
+}
+
+// pinyinArgs 拼音转换参数，不带声调
+var pinyinArgs = pinyin.NewArgs()
+
+// runePinyin 获取单个汉字的拼音，无拼音时返回空字符串
+func runePinyin(r rune) string {
+	if readings := pinyin.SinglePinyin(r, pinyinArgs); len(readings) > 0 {
+		return readings[0]
+	}
+	return ""
+}
+
+// pinyinTerms 生成汉字片段从每个位置开始的拼音全拼和首字母组合，用于前缀匹配
+func pinyinTerms(runes []rune) []string {
+	if len(runes) > maxPinyinRun {
+		runes = runes[:maxPinyinRun]
+	}
+
+	syllables := make([]string, len(runes))
+	for i, r := range runes {
+		syllables[i] = runePinyin(r)
+	}
+
+	var terms []string
+	for i := range syllables {
+		var full, initials strings.Builder
+		for _, s := range syllables[i:] {
+			if s == "" {
+				break
+			}
+			full.WriteString(s)
+			initials.WriteByte(s[0])
+		}
+		if full.Len() > 0 {
+			terms = append(terms, pinyinTermPrefix+full.String(), initialTermPrefix+initials.String())
+		}
+	}
+	return terms
+}
+
+// isPinyinQuery 判断查询词是否可能是拼音（纯小写字母）
+func isPinyinQuery(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, r := range text {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,393 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSeriesNotFound 丛书不存在，或图书不属于任何丛书
+var ErrSeriesNotFound = errors.New("丛书不存在")
+
+// maxSeriesVolume 卷次上限
+const maxSeriesVolume = 9999
+
+// Series 丛书或多卷书，如三部曲、系列丛书
+type Series struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Volumes     int       `json:"volumes,omitempty"` // 馆藏册数，仅在列表查询时填充
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SeriesVolume 图书在丛书中的卷次，一本书只属于一套丛书
+type SeriesVolume struct {
+	SeriesID int `json:"series_id"`
+	BookID   int `json:"book_id"`
+	Volume   int `json:"volume"`
+}
+
+// VolumeBook 丛书中的一册及其可借数量
+type VolumeBook struct {
+	Volume    int   `json:"volume"`
+	Book      *Book `json:"book"`
+	Available int   `json:"available"`
+}
+
+// BookSeries 图书所属的丛书、卷次及下一册
+type BookSeries struct {
+	Series *Series `json:"series"`
+	Volume int     `json:"volume"`
+	Total  int     `json:"total"`          // 馆藏册数
+	Next   *Book   `json:"next,omitempty"` // 下一册，已是最后一册时为空
+}
+
+// SeriesSuggestion 读者借阅过的丛书中接下来可以读的一册
+type SeriesSuggestion struct {
+	Series *Series
+	Last   *Book // 读者借过的卷次最大的一册
+	Next   *Book
+	Volume int // 下一册的卷次
+}
+
+// seriesMutex 保证按名称查找或创建丛书及卷次校验的原子性，加锁顺序在 bookMutex 之后
+var seriesMutex sync.Mutex
+
+// validateSeries 校验丛书字段
+func validateSeries(s *Series) error {
+	s.Name = strings.Join(strings.Fields(s.Name), " ")
+	s.Description = strings.TrimSpace(s.Description)
+	if s.Name == "" {
+		return errors.New("丛书名不能为空")
+	}
+	if len([]rune(s.Name)) > 100 {
+		return errors.New("丛书名不能超过100个字符")
+	}
+	if len([]rune(s.Description)) > 2000 {
+		return errors.New("丛书简介不能超过2000个字符")
+	}
+	return nil
+}
+
+// findOrCreateSeries 按名称查找丛书，不存在时创建，调用方需持有 seriesMutex
+func findOrCreateSeries(name string) (*Series, error) {
+	s := &Series{Name: name, CreatedAt: time.Now()}
+	if err := validateSeries(s); err != nil {
+		return nil, err
+	}
+
+	existing, err := GetRepositories().Series.GetByName(s.Name)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, ErrSeriesNotFound) {
+		return nil, err
+	}
+	if err := GetRepositories().Series.Create(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// pruneSeries 删除不再包含任何图书且没有简介的丛书，调用方需持有 seriesMutex
+func pruneSeries(id int) {
+	volumes, err := GetRepositories().Series.GetVolumes(id)
+	if err != nil || len(volumes) > 0 {
+		return
+	}
+	s, err := GetRepositories().Series.GetByID(id)
+	if err != nil || s.Description != "" {
+		return
+	}
+	if err := GetRepositories().Series.Delete(id); err != nil {
+		log.Printf("删除丛书失败: %v", err)
+	}
+}
+
+// ValidateBookSeries 在写入图书之前校验丛书名和卷次，指定的卷次已被其他图书占用时返回错误；
+// bookID 为0表示新建的图书
+func ValidateBookSeries(bookID int, name string, volume int) error {
+	if volume < 0 || volume > maxSeriesVolume {
+		return fmt.Errorf("卷次须在1到%d之间", maxSeriesVolume)
+	}
+	if strings.TrimSpace(name) == "" {
+		return nil
+	}
+	s := &Series{Name: name}
+	if err := validateSeries(s); err != nil {
+		return err
+	}
+	if volume == 0 {
+		return nil
+	}
+
+	existing, err := GetRepositories().Series.GetByName(s.Name)
+	if errors.Is(err, ErrSeriesNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	volumes, err := GetRepositories().Series.GetVolumes(existing.ID)
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes {
+		if v.Volume == volume && v.BookID != bookID {
+			title := ""
+			if other, err := GetRepositories().Books.GetByID(v.BookID); err == nil {
+				title = other.Title
+			}
+			return fmt.Errorf("《%s》第%d册已是《%s》", existing.Name, volume, title)
+		}
+	}
+	return nil
+}
+
+// SetBookSeries 将图书归入丛书的第 volume 册，丛书不存在时自动创建；
+// volume 为0时沿用原卷次或排在最后，name 为空表示图书不属于任何丛书
+func SetBookSeries(bookID int, name string, volume int) error {
+	if volume < 0 || volume > maxSeriesVolume {
+		return fmt.Errorf("卷次须在1到%d之间", maxSeriesVolume)
+	}
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	seriesMutex.Lock()
+	defer seriesMutex.Unlock()
+
+	if _, err := GetRepositories().Books.GetByID(bookID); err != nil {
+		return err
+	}
+	old, err := GetRepositories().Series.GetVolumeByBookID(bookID)
+	if err != nil && !errors.Is(err, ErrSeriesNotFound) {
+		return err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		if old == nil {
+			return nil
+		}
+		if err := GetRepositories().Series.DeleteVolume(bookID); err != nil {
+			return err
+		}
+		pruneSeries(old.SeriesID)
+		return nil
+	}
+
+	s, err := findOrCreateSeries(name)
+	if err != nil {
+		return err
+	}
+	volumes, err := GetRepositories().Series.GetVolumes(s.ID)
+	if err != nil {
+		return err
+	}
+
+	if volume == 0 {
+		if old != nil && old.SeriesID == s.ID {
+			volume = old.Volume
+		} else {
+			volume = 1
+			if len(volumes) > 0 {
+				volume = volumes[len(volumes)-1].Volume + 1
+			}
+		}
+	}
+	for _, v := range volumes {
+		if v.Volume == volume && v.BookID != bookID {
+			title := ""
+			if other, err := GetRepositories().Books.GetByID(v.BookID); err == nil {
+				title = other.Title
+			}
+			return fmt.Errorf("《%s》第%d册已是《%s》", s.Name, volume, title)
+		}
+	}
+
+	if err := GetRepositories().Series.SetVolume(&SeriesVolume{SeriesID: s.ID, BookID: bookID, Volume: volume}); err != nil {
+		return err
+	}
+	if old != nil && old.SeriesID != s.ID {
+		pruneSeries(old.SeriesID)
+	}
+	return nil
+}
+
+// deleteBookSeriesLocked 在删除图书前读取其所在丛书，返回图书删除后
+// 将图书移出丛书并清理空丛书的函数，调用方需持有 bookMutex
+func deleteBookSeriesLocked(bookID int) (func(), error) {
+	old, err := GetRepositories().Series.GetVolumeByBookID(bookID)
+	if errors.Is(err, ErrSeriesNotFound) {
+		return func() {}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		seriesMutex.Lock()
+		defer seriesMutex.Unlock()
+
+		if err := GetRepositories().Series.DeleteVolume(bookID); err != nil {
+			log.Printf("将图书 %d 移出丛书失败: %v", bookID, err)
+		}
+		pruneSeries(old.SeriesID)
+	}, nil
+}
+
+// GetSeriesByID 根据ID获取丛书
+func GetSeriesByID(id int) (*Series, error) {
+	return GetRepositories().Series.GetByID(id)
+}
+
+// GetAllSeries 获取全部丛书及其册数，按名称排序
+func GetAllSeries() ([]*Series, error) {
+	return GetRepositories().Series.GetAll()
+}
+
+// GetSeriesVolumes 获取丛书各册及其可借数量，按卷次排列
+func GetSeriesVolumes(seriesID int) ([]*VolumeBook, error) {
+	volumes, err := GetRepositories().Series.GetVolumes(seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]*VolumeBook, 0, len(volumes))
+	for _, v := range volumes {
+		book, err := GetRepositories().Books.GetByID(v.BookID)
+		if err != nil {
+			log.Printf("获取丛书图书失败: %v", err)
+			continue
+		}
+		books = append(books, &VolumeBook{Volume: v.Volume, Book: book, Available: book.GetAvailableQuantity()})
+	}
+	return books, nil
+}
+
+// GetBookSeries 获取图书所属的丛书、卷次和下一册，图书不属于任何丛书时返回 nil
+func GetBookSeries(bookID int) *BookSeries {
+	v, err := GetRepositories().Series.GetVolumeByBookID(bookID)
+	if err != nil {
+		if !errors.Is(err, ErrSeriesNotFound) {
+			log.Printf("获取图书丛书失败: %v", err)
+		}
+		return nil
+	}
+	s, err := GetRepositories().Series.GetByID(v.SeriesID)
+	if err != nil {
+		log.Printf("获取丛书失败: %v", err)
+		return nil
+	}
+	volumes, err := GetRepositories().Series.GetVolumes(s.ID)
+	if err != nil {
+		log.Printf("获取丛书卷次失败: %v", err)
+		return nil
+	}
+
+	info := &BookSeries{Series: s, Volume: v.Volume, Total: len(volumes)}
+	for _, other := range volumes {
+		if other.Volume > v.Volume {
+			if next, err := GetRepositories().Books.GetByID(other.BookID); err == nil {
+				info.Next = next
+			}
+			break
+		}
+	}
+	return info
+}
+
+// NextInSeries 获取图书在丛书中的下一册，没有时返回 nil
+func NextInSeries(bookID int) *Book {
+	if info := GetBookSeries(bookID); info != nil {
+		return info.Next
+	}
+	return nil
+}
+
+// SeriesSuggestionsForUser 按读者的借阅记录推荐各丛书中接下来的一册：
+// 取读者借过的卷次最大的一册，推荐其后第一本尚未借过的图书
+func SeriesSuggestionsForUser(userID int) []*SeriesSuggestion {
+	borrowed := make(map[int]bool)
+	for _, record := range GetBorrowRecordsByUserID(userID) {
+		borrowed[record.BookID] = true
+	}
+
+	// 读者借过的每套丛书中卷次最大的一册
+	last := make(map[int]*SeriesVolume)
+	for bookID := range borrowed {
+		v, err := GetRepositories().Series.GetVolumeByBookID(bookID)
+		if err != nil {
+			continue
+		}
+		if current, ok := last[v.SeriesID]; !ok || v.Volume > current.Volume {
+			last[v.SeriesID] = v
+		}
+	}
+
+	var suggestions []*SeriesSuggestion
+	for seriesID, read := range last {
+		volumes, err := GetRepositories().Series.GetVolumes(seriesID)
+		if err != nil {
+			log.Printf("获取丛书卷次失败: %v", err)
+			continue
+		}
+		for _, v := range volumes {
+			if v.Volume <= read.Volume || borrowed[v.BookID] {
+				continue
+			}
+			s, err := GetRepositories().Series.GetByID(seriesID)
+			if err != nil {
+				break
+			}
+			next, err := GetRepositories().Books.GetByID(v.BookID)
+			if err != nil {
+				break
+			}
+			lastBook, err := GetRepositories().Books.GetByID(read.BookID)
+			if err != nil {
+				break
+			}
+			suggestions = append(suggestions, &SeriesSuggestion{Series: s, Last: lastBook, Next: next, Volume: v.Volume})
+			break
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Series.Name < suggestions[j].Series.Name })
+	return suggestions
+}
+
+// UpdateSeries 修改丛书名称和简介
+func UpdateSeries(id int, name, description string) (*Series, error) {
+	seriesMutex.Lock()
+	defer seriesMutex.Unlock()
+
+	s, err := GetRepositories().Series.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	updated := *s
+	updated.Name = name
+	updated.Description = description
+	if err := validateSeries(&updated); err != nil {
+		return nil, err
+	}
+	if existing, err := GetRepositories().Series.GetByName(updated.Name); err == nil && existing.ID != id {
+		return nil, fmt.Errorf("已存在同名的丛书：%s", existing.Name)
+	}
+	if err := GetRepositories().Series.Update(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteSeries 删除丛书，其中的图书不再属于任何丛书
+func DeleteSeries(id int) error {
+	seriesMutex.Lock()
+	defer seriesMutex.Unlock()
+
+	return GetRepositories().Series.Delete(id)
+}
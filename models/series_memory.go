@@ -0,0 +1,168 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemorySeriesRepository 基于内存切片的丛书仓库实现
+type MemorySeriesRepository struct {
+	mu      sync.RWMutex
+	series  []*Series
+	volumes []*SeriesVolume
+	nextID  int
+}
+
+// NewMemorySeriesRepository 创建内存丛书仓库
+func NewMemorySeriesRepository() *MemorySeriesRepository {
+	return &MemorySeriesRepository{nextID: 1}
+}
+
+// Create 添加丛书并分配ID
+func (r *MemorySeriesRepository) Create(s *Series) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.ID = r.nextID
+	r.series = append(r.series, s)
+	r.nextID++
+	return nil
+}
+
+// Update 更新丛书
+func (r *MemorySeriesRepository) Update(s *Series) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.series {
+		if existing.ID == s.ID {
+			r.series[i] = s
+			return nil
+		}
+	}
+	return ErrSeriesNotFound
+}
+
+// Delete 删除丛书及其卷次
+func (r *MemorySeriesRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, s := range r.series {
+		if s.ID == id {
+			r.series = append(r.series[:i], r.series[i+1:]...)
+			r.removeVolumes(func(v *SeriesVolume) bool { return v.SeriesID == id })
+			return nil
+		}
+	}
+	return ErrSeriesNotFound
+}
+
+// GetByID 根据ID获取丛书
+func (r *MemorySeriesRepository) GetByID(id int) (*Series, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.series {
+		if s.ID == id {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, ErrSeriesNotFound
+}
+
+// GetByName 根据名称获取丛书，不区分大小写
+func (r *MemorySeriesRepository) GetByName(name string) (*Series, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.series {
+		if strings.EqualFold(s.Name, name) {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, ErrSeriesNotFound
+}
+
+// GetAll 获取全部丛书并统计册数，按名称排序
+func (r *MemorySeriesRepository) GetAll() ([]*Series, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, v := range r.volumes {
+		counts[v.SeriesID]++
+	}
+	result := make([]*Series, 0, len(r.series))
+	for _, s := range r.series {
+		copied := *s
+		copied.Volumes = counts[s.ID]
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// GetVolumes 获取丛书的全部卷次，按卷次排列
+func (r *MemorySeriesRepository) GetVolumes(seriesID int) ([]*SeriesVolume, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*SeriesVolume
+	for _, v := range r.volumes {
+		if v.SeriesID == seriesID {
+			copied := *v
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Volume < result[j].Volume })
+	return result, nil
+}
+
+// GetVolumeByBookID 获取图书所在的丛书卷次，图书不属于任何丛书时返回 ErrSeriesNotFound
+func (r *MemorySeriesRepository) GetVolumeByBookID(bookID int) (*SeriesVolume, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.volumes {
+		if v.BookID == bookID {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, ErrSeriesNotFound
+}
+
+// SetVolume 设置图书所在的丛书和卷次，替换图书原有的归属
+func (r *MemorySeriesRepository) SetVolume(v *SeriesVolume) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeVolumes(func(existing *SeriesVolume) bool { return existing.BookID == v.BookID })
+	copied := *v
+	r.volumes = append(r.volumes, &copied)
+	return nil
+}
+
+// DeleteVolume 将图书移出丛书
+func (r *MemorySeriesRepository) DeleteVolume(bookID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeVolumes(func(v *SeriesVolume) bool { return v.BookID == bookID })
+	return nil
+}
+
+// removeVolumes 删除满足条件的卷次，调用方需持有写锁
+func (r *MemorySeriesRepository) removeVolumes(match func(*SeriesVolume) bool) {
+	kept := r.volumes[:0]
+	for _, v := range r.volumes {
+		if !match(v) {
+			kept = append(kept, v)
+		}
+	}
+	r.volumes = kept
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// seriesColumns 丛书表查询列
+const seriesColumns = "id, name, description, created_at"
+
+// MySQLSeriesRepository 基于MySQL的丛书仓库实现
+type MySQLSeriesRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLSeriesRepository 创建MySQL丛书仓库
+func NewMySQLSeriesRepository(db *sql.DB) *MySQLSeriesRepository {
+	return &MySQLSeriesRepository{db: db}
+}
+
+// Create 插入丛书并回填ID
+func (r *MySQLSeriesRepository) Create(s *Series) error {
+	result, err := r.db.Exec("INSERT INTO series (name, description, created_at) VALUES (?, ?, ?)",
+		s.Name, s.Description, s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("创建丛书失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取丛书ID失败: %w", err)
+	}
+	s.ID = int(id)
+	return nil
+}
+
+// Update 更新丛书
+func (r *MySQLSeriesRepository) Update(s *Series) error {
+	_, err := r.db.Exec("UPDATE series SET name = ?, description = ? WHERE id = ?", s.Name, s.Description, s.ID)
+	if err != nil {
+		return fmt.Errorf("更新丛书失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除丛书，卷次随外键级联删除
+func (r *MySQLSeriesRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM series WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除丛书失败: %w", err)
+	}
+	return checkAffected(result, ErrSeriesNotFound)
+}
+
+// GetByID 根据ID获取丛书
+func (r *MySQLSeriesRepository) GetByID(id int) (*Series, error) {
+	return scanSeries(r.db.QueryRow("SELECT "+seriesColumns+" FROM series WHERE id = ?", id))
+}
+
+// GetByName 根据名称获取丛书，按列排序规则不区分大小写
+func (r *MySQLSeriesRepository) GetByName(name string) (*Series, error) {
+	return scanSeries(r.db.QueryRow("SELECT "+seriesColumns+" FROM series WHERE name = ?", name))
+}
+
+// GetAll 获取全部丛书并统计册数，按名称排序
+func (r *MySQLSeriesRepository) GetAll() ([]*Series, error) {
+	rows, err := r.db.Query(`
+		SELECT s.id, s.name, s.description, s.created_at, COUNT(v.book_id)
+		FROM series s LEFT JOIN series_volumes v ON v.series_id = s.id
+		GROUP BY s.id ORDER BY s.name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询丛书失败: %w", err)
+	}
+	defer rows.Close()
+
+	result := []*Series{}
+	for rows.Next() {
+		s := &Series{}
+		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt, &s.Volumes); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// GetVolumes 获取丛书的全部卷次，按卷次排列
+func (r *MySQLSeriesRepository) GetVolumes(seriesID int) ([]*SeriesVolume, error) {
+	rows, err := r.db.Query("SELECT series_id, book_id, volume FROM series_volumes WHERE series_id = ? ORDER BY volume", seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("查询丛书卷次失败: %w", err)
+	}
+	defer rows.Close()
+
+	var volumes []*SeriesVolume
+	for rows.Next() {
+		v := &SeriesVolume{}
+		if err := rows.Scan(&v.SeriesID, &v.BookID, &v.Volume); err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes, rows.Err()
+}
+
+// GetVolumeByBookID 获取图书所在的丛书卷次，图书不属于任何丛书时返回 ErrSeriesNotFound
+func (r *MySQLSeriesRepository) GetVolumeByBookID(bookID int) (*SeriesVolume, error) {
+	v := &SeriesVolume{}
+	err := r.db.QueryRow("SELECT series_id, book_id, volume FROM series_volumes WHERE book_id = ?", bookID).
+		Scan(&v.SeriesID, &v.BookID, &v.Volume)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSeriesNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询图书丛书失败: %w", err)
+	}
+	return v, nil
+}
+
+// SetVolume 设置图书所在的丛书和卷次，替换图书原有的归属
+func (r *MySQLSeriesRepository) SetVolume(v *SeriesVolume) error {
+	_, err := r.db.Exec(`
+		INSERT INTO series_volumes (book_id, series_id, volume) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE series_id = VALUES(series_id), volume = VALUES(volume)`,
+		v.BookID, v.SeriesID, v.Volume)
+	if err != nil {
+		return fmt.Errorf("设置图书丛书失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteVolume 将图书移出丛书
+func (r *MySQLSeriesRepository) DeleteVolume(bookID int) error {
+	if _, err := r.db.Exec("DELETE FROM series_volumes WHERE book_id = ?", bookID); err != nil {
+		return fmt.Errorf("删除图书丛书失败: %w", err)
+	}
+	return nil
+}
+
+// scanSeries 扫描一行丛书数据
+func scanSeries(row rowScanner) (*Series, error) {
+	s := &Series{}
+	err := row.Scan(&s.ID, &s.Name, &s.Description, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSeriesNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
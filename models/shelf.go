@@ -0,0 +1,178 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrNoCallNumber 图书尚未编制索书号，无法在书架上定位
+var ErrNoCallNumber = errors.New("该图书尚未编制索书号")
+
+// 索书号限制
+const (
+	maxCallNumberLength    = 64
+	maxShelfLocationLength = 100
+	callNumberKeyLength    = 160
+	callNumberDigitWidth   = 8 // 书次号中的数字补齐到的位数
+)
+
+// ShelfView 书架浏览：按索书号顺序排在某本书前后的图书
+type ShelfView struct {
+	Book   *Book   `json:"book"`
+	Before []*Book `json:"before"` // 排在前面的图书，按索书号升序
+	After  []*Book `json:"after"`  // 排在后面的图书，按索书号升序
+}
+
+// NormalizeCallNumber 规范化索书号：合并连续空白、去掉斜杠两侧的空白、字母转为大写，全角斜杠转为半角
+func NormalizeCallNumber(callNumber string) string {
+	parts := strings.Split(strings.ReplaceAll(callNumber, "／", "/"), "/")
+	for i, part := range parts {
+		parts[i] = strings.Join(strings.Fields(part), " ")
+	}
+	return strings.ToUpper(strings.Join(parts, "/"))
+}
+
+// ValidateShelving 校验索书号和默认架位
+func ValidateShelving(callNumber, shelfLocation string) error {
+	callNumber = NormalizeCallNumber(callNumber)
+	if len([]rune(callNumber)) > maxCallNumberLength {
+		return fmt.Errorf("索书号不能超过%d个字符", maxCallNumberLength)
+	}
+	if callNumber != "" {
+		first := []rune(callNumber)[0]
+		if first > unicode.MaxASCII || !(unicode.IsLetter(first) || unicode.IsDigit(first)) {
+			return errors.New("索书号须以分类号开头，如 I247.5/123")
+		}
+	}
+	if len([]rune(strings.TrimSpace(shelfLocation))) > maxShelfLocationLength {
+		return fmt.Errorf("架位不能超过%d个字符", maxShelfLocationLength)
+	}
+	return nil
+}
+
+// callNumberSortKey 生成索书号的排序键，使字符串顺序即排架顺序：
+// 分类号逐位比较，小数点不参与比较（I247 < I247.5 < I25）；
+// 斜杠后的书次号、卷册号中的数字按数值比较（/9 < /12）。
+// 各部分之间以空格连接，保证较短的分类号排在其下位类之前。
+func callNumberSortKey(callNumber string) string {
+	if callNumber == "" {
+		return ""
+	}
+
+	parts := strings.Split(callNumber, "/")
+	keys := make([]string, 0, len(parts))
+	for i, part := range parts {
+		part = strings.ReplaceAll(part, " ", "")
+		if i == 0 {
+			keys = append(keys, strings.ReplaceAll(part, ".", ""))
+			continue
+		}
+		keys = append(keys, padDigitRuns(part))
+	}
+
+	key := strings.Join(keys, " ")
+	if len(key) > callNumberKeyLength {
+		key = key[:callNumberKeyLength]
+	}
+	return key
+}
+
+// padDigitRuns 将连续数字左侧补零到固定宽度，使数字按数值比较
+func padDigitRuns(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if runes[i] < '0' || runes[i] > '9' {
+			b.WriteRune(runes[i])
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+			j++
+		}
+		digits := strings.TrimLeft(string(runes[i:j]), "0")
+		if len(digits) < callNumberDigitWidth {
+			digits = strings.Repeat("0", callNumberDigitWidth-len(digits)) + digits
+		}
+		b.WriteString(digits)
+		i = j
+	}
+	return b.String()
+}
+
+// SetBookShelving 设置图书的索书号和默认架位，索书号为空表示取消排架
+func SetBookShelving(bookID int, callNumber, shelfLocation string) (*Book, error) {
+	if err := ValidateShelving(callNumber, shelfLocation); err != nil {
+		return nil, err
+	}
+	callNumber = NormalizeCallNumber(callNumber)
+	shelfLocation = strings.TrimSpace(shelfLocation)
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	book, err := GetRepositories().Books.GetByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	if book.CallNumber == callNumber && book.ShelfLocation == shelfLocation {
+		return book, nil
+	}
+
+	oldCallNumber, oldKey, oldLocation := book.CallNumber, book.CallNumberKey, book.ShelfLocation
+	book.CallNumber = callNumber
+	book.CallNumberKey = callNumberSortKey(callNumber)
+	book.ShelfLocation = shelfLocation
+	if err := GetRepositories().Books.Update(book); err != nil {
+		book.CallNumber, book.CallNumberKey, book.ShelfLocation = oldCallNumber, oldKey, oldLocation
+		return nil, err
+	}
+	return book, nil
+}
+
+// BrowseShelf 按索书号取排在图书前后各 n 本的相邻图书，模拟在书架前浏览
+func BrowseShelf(bookID, n int) (*ShelfView, error) {
+	book, err := GetBookByID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	if book.CallNumber == "" {
+		return nil, ErrNoCallNumber
+	}
+
+	query := BookQuery{Sort: SortCallNumber, Shelved: true}
+	after, _, err := GetRepositories().Books.Find(query, cursorFor(book, 0, SortCallNumber, false), n, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 向前浏览时倒序取最近的 n 本，再翻转为升序
+	query.Desc = true
+	before, _, err := GetRepositories().Books.Find(query, cursorFor(book, 0, SortCallNumber, true), n, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+		before[i], before[j] = before[j], before[i]
+	}
+
+	view := &ShelfView{Book: book, Before: before, After: after}
+	if view.Before == nil {
+		view.Before = []*Book{}
+	}
+	if view.After == nil {
+		view.After = []*Book{}
+	}
+	return view, nil
+}
+
+// LocationFor 副本所在架位，副本未登记架位时使用图书的默认架位
+func (c *BookCopy) LocationFor(book *Book) string {
+	if c.ShelfLocation != "" {
+		return c.ShelfLocation
+	}
+	return book.ShelfLocation
+}
@@ -0,0 +1,463 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTagNotFound 标签不存在
+var ErrTagNotFound = errors.New("标签不存在")
+
+// TagKind 标签类型
+type TagKind string
+
+const (
+	TagTopic   TagKind = "tag"     // 面向读者的标签，如"入门"、"经典"
+	TagSubject TagKind = "subject" // 主题词
+)
+
+// TagKinds 全部标签类型，按显示顺序排列
+var TagKinds = []TagKind{TagSubject, TagTopic}
+
+// Text 标签类型的中文名称
+func (k TagKind) Text() string {
+	switch k {
+	case TagSubject:
+		return "主题词"
+	case TagTopic:
+		return "标签"
+	default:
+		return string(k)
+	}
+}
+
+// Valid 是否为支持的标签类型
+func (k TagKind) Valid() bool {
+	return k == TagTopic || k == TagSubject
+}
+
+// Tag 标签或主题词，同名标签只有一个，与图书为多对多关系
+type Tag struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Kind      TagKind   `json:"kind"`
+	Books     int       `json:"books,omitempty"` // 关联图书数量，列表查询时填充
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// KindText 标签类型的中文名称
+func (t *Tag) KindText() string {
+	return t.Kind.Text()
+}
+
+// BookTag 图书与标签的关联
+type BookTag struct {
+	BookID int     `json:"book_id"`
+	TagID  int     `json:"tag_id"`
+	Name   string  `json:"name"`
+	Kind   TagKind `json:"kind"`
+}
+
+// TagCloudItem 标签云中的一个标签，Weight 为1-5的显示权重
+type TagCloudItem struct {
+	*Tag
+	Weight int
+}
+
+// maxTagNameLength 标签名称的最大字符数
+const maxTagNameLength = 50
+
+// tagMutex 保证标签查找、创建与关联更新的原子性，加锁顺序在 bookMutex 之后
+var tagMutex sync.Mutex
+
+// tagSeparators 标签列表支持的分隔符，统一替换为顿号；不拆分空格和斜杠以保留"Go 语言"、"C/C++"
+var tagSeparators = strings.NewReplacer("，", "、", ",", "、", "；", "、", ";", "、")
+
+// ParseTagNames 拆分"入门、经典"形式的标签列表，去掉空白和重复项
+func ParseTagNames(s string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(tagSeparators.Replace(s), "、") {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// validateTag 校验并规范化标签字段
+func validateTag(t *Tag) error {
+	t.Name = strings.Join(strings.Fields(t.Name), " ")
+	if t.Name == "" {
+		return errors.New("标签名称不能为空")
+	}
+	if len([]rune(t.Name)) > maxTagNameLength {
+		return fmt.Errorf("标签名称不能超过%d个字符", maxTagNameLength)
+	}
+	if !t.Kind.Valid() {
+		return fmt.Errorf("不支持的标签类型: %s", t.Kind)
+	}
+	return nil
+}
+
+// findOrCreateTag 按名称查找标签，不存在时以指定类型创建，调用方需持有 tagMutex
+func findOrCreateTag(name string, kind TagKind) (*Tag, error) {
+	if t, err := GetRepositories().Tags.GetByName(name); err == nil {
+		return t, nil
+	} else if !errors.Is(err, ErrTagNotFound) {
+		return nil, err
+	}
+
+	t := &Tag{Name: name, Kind: kind, CreatedAt: time.Now()}
+	if err := validateTag(t); err != nil {
+		return nil, err
+	}
+	if err := GetRepositories().Tags.Create(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// pruneTags 删除不再关联任何图书的标签，调用方需持有 tagMutex
+func pruneTags(ids []int) {
+	for _, id := range ids {
+		bookIDs, err := GetRepositories().Tags.GetBookIDs(id)
+		if err != nil || len(bookIDs) > 0 {
+			continue
+		}
+		if err := GetRepositories().Tags.Delete(id); err != nil {
+			log.Printf("删除无关联标签 %d 失败: %v", id, err)
+		}
+	}
+}
+
+// ValidateBookTags 在写入图书之前校验标签类型和名称
+func ValidateBookTags(tags map[TagKind][]string) error {
+	for kind, names := range tags {
+		if !kind.Valid() {
+			return fmt.Errorf("不支持的标签类型: %s", kind)
+		}
+		for _, name := range names {
+			if err := validateTag(&Tag{Name: name, Kind: kind}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetBookTags 按类型替换图书的标签，未出现在 tags 中的类型保持不变；
+// 名称已存在的标签沿用原有类型
+func SetBookTags(bookID int, tags map[TagKind][]string) error {
+	if err := ValidateBookTags(tags); err != nil {
+		return err
+	}
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+
+	book, err := GetRepositories().Books.GetByID(bookID)
+	if err != nil {
+		return err
+	}
+
+	if err := setBookTagsLocked(bookID, tags); err != nil {
+		return err
+	}
+	bookIndex.update(book)
+	return nil
+}
+
+// setBookTagsLocked 替换图书的标签并清理无关联的标签，调用方需持有 bookMutex
+func setBookTagsLocked(bookID int, tags map[TagKind][]string) error {
+	tagMutex.Lock()
+	defer tagMutex.Unlock()
+
+	old, err := GetRepositories().Tags.GetByBookID(bookID)
+	if err != nil {
+		return err
+	}
+
+	for _, kind := range TagKinds {
+		names, ok := tags[kind]
+		if !ok {
+			continue
+		}
+		ids := make([]int, 0, len(names))
+		for _, name := range names {
+			t, err := findOrCreateTag(name, kind)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, t.ID)
+		}
+		if err := GetRepositories().Tags.SetBookTags(bookID, kind, ids); err != nil {
+			return err
+		}
+	}
+
+	oldIDs := make([]int, 0, len(old))
+	for _, t := range old {
+		oldIDs = append(oldIDs, t.ID)
+	}
+	pruneTags(oldIDs)
+	return nil
+}
+
+// deleteBookTagsLocked 在删除图书前读取其标签，返回图书删除后
+// 移除标签关联并清理不再关联任何图书的标签的函数，调用方需持有 bookMutex
+func deleteBookTagsLocked(bookID int) (func(), error) {
+	old, err := GetRepositories().Tags.GetByBookID(bookID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, 0, len(old))
+	for _, t := range old {
+		ids = append(ids, t.ID)
+	}
+	return func() {
+		tagMutex.Lock()
+		defer tagMutex.Unlock()
+
+		if err := GetRepositories().Tags.DeleteByBookID(bookID); err != nil {
+			log.Printf("删除图书 %d 的标签关联失败: %v", bookID, err)
+		}
+		pruneTags(ids)
+	}, nil
+}
+
+// GetBookTags 获取图书的全部标签，按名称排序
+func GetBookTags(bookID int) []*Tag {
+	tags, err := GetRepositories().Tags.GetByBookID(bookID)
+	if err != nil {
+		log.Printf("获取图书标签失败: %v", err)
+	}
+	return tags
+}
+
+// BookTagNames 取出指定类型的标签名称，以顿号连接，用于回填表单
+func BookTagNames(tags []*Tag, kind TagKind) string {
+	var names []string
+	for _, t := range tags {
+		if t.Kind == kind {
+			names = append(names, t.Name)
+		}
+	}
+	return strings.Join(names, "、")
+}
+
+// tagSearchText 图书标签名称，用于检索
+func tagSearchText(tags []*Tag) string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+// GetTagByID 根据ID获取标签
+func GetTagByID(id int) (*Tag, error) {
+	return GetRepositories().Tags.GetByID(id)
+}
+
+// GetAllTags 获取全部标签及其图书数量，按名称排序
+func GetAllTags() ([]*Tag, error) {
+	return GetRepositories().Tags.GetAll()
+}
+
+// GetTagCloud 取图书最多的 limit 个标签，按名称排序并按图书数量计算显示权重
+func GetTagCloud(limit int) []*TagCloudItem {
+	tags, err := GetRepositories().Tags.GetAll()
+	if err != nil {
+		log.Printf("获取标签云失败: %v", err)
+		return nil
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Books > tags[j].Books })
+	if limit > 0 && len(tags) > limit {
+		tags = tags[:limit]
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	min, max := tags[len(tags)-1].Books, tags[0].Books
+	items := make([]*TagCloudItem, 0, len(tags))
+	for _, t := range tags {
+		weight := 3
+		if max > min {
+			weight = 1 + (t.Books-min)*4/(max-min)
+		}
+		items = append(items, &TagCloudItem{Tag: t, Weight: weight})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+// bookIDsWithTags 同时带有全部指定标签的图书ID，按ID升序；有标签不存在时结果为空
+func bookIDsWithTags(names []string) ([]int, error) {
+	var result map[int]bool
+	for _, name := range names {
+		t, err := GetRepositories().Tags.GetByName(strings.Join(strings.Fields(name), " "))
+		if errors.Is(err, ErrTagNotFound) {
+			return []int{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids, err := GetRepositories().Tags.GetBookIDs(t.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			if result == nil || result[id] {
+				next[id] = true
+			}
+		}
+		result = next
+	}
+
+	ids := make([]int, 0, len(result))
+	for id := range result {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// RelatedBooks 与图书共有标签最多的其他图书，共有标签数相同时按ID排序
+func RelatedBooks(bookID, limit int) []*Book {
+	shared := make(map[int]int)
+	for _, t := range GetBookTags(bookID) {
+		ids, err := GetRepositories().Tags.GetBookIDs(t.ID)
+		if err != nil {
+			log.Printf("获取相关图书失败: %v", err)
+			return nil
+		}
+		for _, id := range ids {
+			if id != bookID {
+				shared[id]++
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(shared))
+	for id := range shared {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if shared[ids[i]] != shared[ids[j]] {
+			return shared[ids[i]] > shared[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	var books []*Book
+	for _, id := range ids {
+		if len(books) >= limit {
+			break
+		}
+		if book, err := GetBookByID(id); err == nil {
+			books = append(books, book)
+		}
+	}
+	return books
+}
+
+// reindexTagBooksLocked 标签名称变化后重建相关图书的索引，调用方需持有 bookMutex
+func reindexTagBooksLocked(bookIDs []int) {
+	for _, id := range bookIDs {
+		if book, err := GetRepositories().Books.GetByID(id); err == nil {
+			bookIndex.update(book)
+		}
+	}
+}
+
+// RenameTag 修改标签名称和类型，新名称与其他标签重复时需先合并
+func RenameTag(id int, name string, kind TagKind) (*Tag, error) {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	tagMutex.Lock()
+	defer tagMutex.Unlock()
+
+	t, err := GetRepositories().Tags.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	updated := *t
+	updated.Name, updated.Kind = name, kind
+	if err := validateTag(&updated); err != nil {
+		return nil, err
+	}
+	if existing, err := GetRepositories().Tags.GetByName(updated.Name); err == nil && existing.ID != id {
+		return nil, fmt.Errorf("标签\"%s\"已存在，请使用合并", existing.Name)
+	}
+	if err := GetRepositories().Tags.Update(&updated); err != nil {
+		return nil, err
+	}
+
+	bookIDs, err := GetRepositories().Tags.GetBookIDs(id)
+	if err != nil {
+		return nil, err
+	}
+	reindexTagBooksLocked(bookIDs)
+	return &updated, nil
+}
+
+// MergeTags 将标签 fromID 并入 intoID：图书改为关联 intoID，随后删除 fromID
+func MergeTags(fromID, intoID int) (*Tag, error) {
+	if fromID == intoID {
+		return nil, errors.New("不能将标签合并到其自身")
+	}
+
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	tagMutex.Lock()
+	defer tagMutex.Unlock()
+
+	if _, err := GetRepositories().Tags.GetByID(fromID); err != nil {
+		return nil, err
+	}
+	into, err := GetRepositories().Tags.GetByID(intoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := GetRepositories().Tags.Merge(fromID, intoID); err != nil {
+		return nil, err
+	}
+
+	bookIDs, err := GetRepositories().Tags.GetBookIDs(intoID)
+	if err != nil {
+		return nil, err
+	}
+	reindexTagBooksLocked(bookIDs)
+	return into, nil
+}
+
+// DeleteTag 删除标签及其全部图书关联
+func DeleteTag(id int) error {
+	bookMutex.Lock()
+	defer bookMutex.Unlock()
+	tagMutex.Lock()
+	defer tagMutex.Unlock()
+
+	bookIDs, err := GetRepositories().Tags.GetBookIDs(id)
+	if err != nil {
+		return err
+	}
+	if err := GetRepositories().Tags.Delete(id); err != nil {
+		return err
+	}
+	reindexTagBooksLocked(bookIDs)
+	return nil
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryTagRepository 基于内存切片的标签仓库实现
+type MemoryTagRepository struct {
+	mu     sync.RWMutex
+	tags   []*Tag
+	links  []*BookTag
+	nextID int
+}
+
+// NewMemoryTagRepository 创建内存标签仓库
+func NewMemoryTagRepository() *MemoryTagRepository {
+	return &MemoryTagRepository{nextID: 1}
+}
+
+// Create 添加标签并分配ID
+func (r *MemoryTagRepository) Create(t *Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t.ID = r.nextID
+	r.tags = append(r.tags, t)
+	r.nextID++
+	return nil
+}
+
+// Update 更新标签
+func (r *MemoryTagRepository) Update(t *Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.tags {
+		if existing.ID == t.ID {
+			r.tags[i] = t
+			return nil
+		}
+	}
+	return ErrTagNotFound
+}
+
+// Delete 删除标签及其关联
+func (r *MemoryTagRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, t := range r.tags {
+		if t.ID == id {
+			r.tags = append(r.tags[:i], r.tags[i+1:]...)
+			r.removeLinks(func(link *BookTag) bool { return link.TagID == id })
+			return nil
+		}
+	}
+	return ErrTagNotFound
+}
+
+// GetByID 根据ID获取标签
+func (r *MemoryTagRepository) GetByID(id int) (*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if t := r.tagByID(id); t != nil {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, ErrTagNotFound
+}
+
+// GetByName 根据名称获取标签，不区分大小写
+func (r *MemoryTagRepository) GetByName(name string) (*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tags {
+		if strings.EqualFold(t.Name, name) {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+// GetAll 获取全部标签并统计图书数量，按名称排序
+func (r *MemoryTagRepository) GetAll() ([]*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[int]int)
+	for _, link := range r.links {
+		counts[link.TagID]++
+	}
+	result := make([]*Tag, 0, len(r.tags))
+	for _, t := range r.tags {
+		copied := *t
+		copied.Books = counts[t.ID]
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// GetByBookID 获取图书的全部标签，按名称排序
+func (r *MemoryTagRepository) GetByBookID(bookID int) ([]*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Tag
+	for _, link := range r.links {
+		if link.BookID != bookID {
+			continue
+		}
+		if t := r.tagByID(link.TagID); t != nil {
+			copied := *t
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// GetAllBookTags 获取全部关联，附带标签名称和类型
+func (r *MemoryTagRepository) GetAllBookTags() ([]*BookTag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*BookTag, 0, len(r.links))
+	for _, link := range r.links {
+		if t := r.tagByID(link.TagID); t != nil {
+			result = append(result, &BookTag{BookID: link.BookID, TagID: t.ID, Name: t.Name, Kind: t.Kind})
+		}
+	}
+	return result, nil
+}
+
+// GetBookIDs 获取带有该标签的图书ID
+func (r *MemoryTagRepository) GetBookIDs(tagID int) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var ids []int
+	for _, link := range r.links {
+		if link.TagID == tagID {
+			ids = append(ids, link.BookID)
+		}
+	}
+	return ids, nil
+}
+
+// SetBookTags 替换图书某一类型的全部标签
+func (r *MemoryTagRepository) SetBookTags(bookID int, kind TagKind, tagIDs []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeLinks(func(link *BookTag) bool {
+		t := r.tagByID(link.TagID)
+		return link.BookID == bookID && t != nil && t.Kind == kind
+	})
+	for _, id := range tagIDs {
+		if !r.hasLink(bookID, id) {
+			r.links = append(r.links, &BookTag{BookID: bookID, TagID: id})
+		}
+	}
+	return nil
+}
+
+// DeleteByBookID 删除图书的全部标签关联
+func (r *MemoryTagRepository) DeleteByBookID(bookID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeLinks(func(link *BookTag) bool { return link.BookID == bookID })
+	return nil
+}
+
+// Merge 将 fromID 的图书关联转到 intoID 并删除 fromID
+func (r *MemoryTagRepository) Merge(fromID, intoID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tagByID(fromID) == nil || r.tagByID(intoID) == nil {
+		return ErrTagNotFound
+	}
+	for _, link := range r.links {
+		if link.TagID == fromID && !r.hasLink(link.BookID, intoID) {
+			link.TagID = intoID
+		}
+	}
+	r.removeLinks(func(link *BookTag) bool { return link.TagID == fromID })
+	for i, t := range r.tags {
+		if t.ID == fromID {
+			r.tags = append(r.tags[:i], r.tags[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// tagByID 查找标签，调用方需持有锁
+func (r *MemoryTagRepository) tagByID(id int) *Tag {
+	for _, t := range r.tags {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// hasLink 图书是否已关联标签，调用方需持有锁
+func (r *MemoryTagRepository) hasLink(bookID, tagID int) bool {
+	for _, link := range r.links {
+		if link.BookID == bookID && link.TagID == tagID {
+			return true
+		}
+	}
+	return false
+}
+
+// removeLinks 删除满足条件的关联，调用方需持有写锁
+func (r *MemoryTagRepository) removeLinks(match func(*BookTag) bool) {
+	kept := r.links[:0]
+	for _, link := range r.links {
+		if !match(link) {
+			kept = append(kept, link)
+		}
+	}
+	r.links = kept
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// tagColumns 标签表查询列
+const tagColumns = "id, name, kind, created_at"
+
+// MySQLTagRepository 基于MySQL的标签仓库实现
+type MySQLTagRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLTagRepository 创建MySQL标签仓库
+func NewMySQLTagRepository(db *sql.DB) *MySQLTagRepository {
+	return &MySQLTagRepository{db: db}
+}
+
+// Create 插入标签并回填ID
+func (r *MySQLTagRepository) Create(t *Tag) error {
+	result, err := r.db.Exec("INSERT INTO tags (name, kind, created_at) VALUES (?, ?, ?)",
+		t.Name, string(t.Kind), t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("创建标签失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取标签ID失败: %w", err)
+	}
+	t.ID = int(id)
+	return nil
+}
+
+// Update 更新标签
+func (r *MySQLTagRepository) Update(t *Tag) error {
+	_, err := r.db.Exec("UPDATE tags SET name = ?, kind = ? WHERE id = ?", t.Name, string(t.Kind), t.ID)
+	if err != nil {
+		return fmt.Errorf("更新标签失败: %w", err)
+	}
+	return nil
+}
+
+// Delete 删除标签，关联随外键级联删除
+func (r *MySQLTagRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除标签失败: %w", err)
+	}
+	return checkAffected(result, ErrTagNotFound)
+}
+
+// GetByID 根据ID获取标签
+func (r *MySQLTagRepository) GetByID(id int) (*Tag, error) {
+	return scanTag(r.db.QueryRow("SELECT "+tagColumns+" FROM tags WHERE id = ?", id))
+}
+
+// GetByName 根据名称获取标签，按列排序规则不区分大小写
+func (r *MySQLTagRepository) GetByName(name string) (*Tag, error) {
+	return scanTag(r.db.QueryRow("SELECT "+tagColumns+" FROM tags WHERE name = ?", name))
+}
+
+// GetAll 获取全部标签并统计图书数量，按名称排序
+func (r *MySQLTagRepository) GetAll() ([]*Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.name, t.kind, t.created_at, COUNT(bt.book_id)
+		FROM tags t LEFT JOIN book_tags bt ON bt.tag_id = t.id
+		GROUP BY t.id ORDER BY t.name`)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*Tag{}
+	for rows.Next() {
+		t := &Tag{}
+		var kind string
+		if err := rows.Scan(&t.ID, &t.Name, &kind, &t.CreatedAt, &t.Books); err != nil {
+			return nil, err
+		}
+		t.Kind = TagKind(kind)
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// GetByBookID 获取图书的全部标签，按名称排序
+func (r *MySQLTagRepository) GetByBookID(bookID int) ([]*Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.name, t.kind, t.created_at
+		FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.book_id = ? ORDER BY t.name`, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("查询图书标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []*Tag
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// GetAllBookTags 获取全部关联，附带标签名称和类型
+func (r *MySQLTagRepository) GetAllBookTags() ([]*BookTag, error) {
+	rows, err := r.db.Query(`
+		SELECT bt.book_id, bt.tag_id, t.name, t.kind
+		FROM book_tags bt JOIN tags t ON t.id = bt.tag_id ORDER BY bt.book_id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询图书标签失败: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*BookTag
+	for rows.Next() {
+		link := &BookTag{}
+		var kind string
+		if err := rows.Scan(&link.BookID, &link.TagID, &link.Name, &kind); err != nil {
+			return nil, err
+		}
+		link.Kind = TagKind(kind)
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// GetBookIDs 获取带有该标签的图书ID
+func (r *MySQLTagRepository) GetBookIDs(tagID int) ([]int, error) {
+	rows, err := r.db.Query("SELECT book_id FROM book_tags WHERE tag_id = ? ORDER BY book_id", tagID)
+	if err != nil {
+		return nil, fmt.Errorf("查询标签图书失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetBookTags 在事务中替换图书某一类型的全部标签
+func (r *MySQLTagRepository) SetBookTags(bookID int, kind TagKind, tagIDs []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("更新图书标签失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE bt FROM book_tags bt JOIN tags t ON t.id = bt.tag_id
+		WHERE bt.book_id = ? AND t.kind = ?`, bookID, string(kind))
+	if err != nil {
+		return fmt.Errorf("更新图书标签失败: %w", err)
+	}
+	for _, id := range tagIDs {
+		if _, err := tx.Exec("INSERT IGNORE INTO book_tags (book_id, tag_id) VALUES (?, ?)", bookID, id); err != nil {
+			return fmt.Errorf("更新图书标签失败: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("更新图书标签失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteByBookID 删除图书的全部标签关联
+func (r *MySQLTagRepository) DeleteByBookID(bookID int) error {
+	if _, err := r.db.Exec("DELETE FROM book_tags WHERE book_id = ?", bookID); err != nil {
+		return fmt.Errorf("删除图书标签失败: %w", err)
+	}
+	return nil
+}
+
+// Merge 在事务中将 fromID 的图书关联转到 intoID 并删除 fromID
+func (r *MySQLTagRepository) Merge(fromID, intoID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("合并标签失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT IGNORE INTO book_tags (book_id, tag_id)
+		SELECT book_id, ? FROM book_tags WHERE tag_id = ?`, intoID, fromID)
+	if err != nil {
+		return fmt.Errorf("合并标签失败: %w", err)
+	}
+	result, err := tx.Exec("DELETE FROM tags WHERE id = ?", fromID)
+	if err != nil {
+		return fmt.Errorf("合并标签失败: %w", err)
+	}
+	if err := checkAffected(result, ErrTagNotFound); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("合并标签失败: %w", err)
+	}
+	return nil
+}
+
+// scanTag 扫描一行标签数据
+func scanTag(row rowScanner) (*Tag, error) {
+	t := &Tag{}
+	var kind string
+	err := row.Scan(&t.ID, &t.Name, &kind, &t.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Kind = TagKind(kind)
+	return t, nil
+}
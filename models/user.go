@@ -1,10 +1,8 @@
 package models
 
 import (
-	"crypto/sha256"
 	"errors"
-	"fmt"
-	"strings"
+	"log"
 	"sync"
 )
 
@@ -26,17 +24,38 @@ type User struct {
 	Role         UserRole `json:"role"`
 }
 
-// Users 全局用户列表
-var (
-	Users      []*User
-	NextUserID = 1
-	userMutex  sync.Mutex
-)
+// userMutex 保证用户唯一性校验与写入的原子性
+var userMutex sync.Mutex
 
-// CheckPassword 检查密码是否正确
+// CheckPassword 检查密码是否正确，旧算法或过时参数生成的哈希在校验成功后自动升级
 func (u *User) CheckPassword(password string) bool {
-	hashedPassword := hashPassword(password)
-	return u.PasswordHash == hashedPassword
+	ok, rehash := verifyPassword(u.PasswordHash, password)
+	if !ok {
+		return false
+	}
+
+	if rehash {
+		if err := u.rehashPassword(password); err != nil {
+			log.Printf("升级用户 %s 的密码哈希失败: %v", u.Username, err)
+		}
+	}
+	return true
+}
+
+// rehashPassword 使用当前算法重新生成并保存密码哈希
+func (u *User) rehashPassword(password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	previous := u.PasswordHash
+	u.PasswordHash = hash
+	if err := GetRepositories().Users.Update(u); err != nil {
+		u.PasswordHash = previous
+		return err
+	}
+	return nil
 }
 
 // UpdateRole 更新用户角色
@@ -45,9 +64,14 @@ func (u *User) UpdateRole(role UserRole) error {
 	if role != RoleAdmin && role != RoleLibrarian && role != RoleReader {
 		return errors.New("无效的用户角色")
 	}
-	
+
 	// 更新角色
+	previous := u.Role
 	u.Role = role
+	if err := GetRepositories().Users.Update(u); err != nil {
+		u.Role = previous
+		return err
+	}
 	return nil
 }
 
@@ -55,122 +79,68 @@ func (u *User) UpdateRole(role UserRole) error {
 func CreateUser(username, email, password string, role UserRole) (*User, error) {
 	userMutex.Lock()
 	defer userMutex.Unlock()
-	
+
 	// 验证参数
 	if username == "" || email == "" || password == "" {
 		return nil, errors.New("用户名、邮箱和密码不能为空")
 	}
-	
+
+	// 验证角色是否有效
+	if role != RoleAdmin && role != RoleLibrarian && role != RoleReader {
+		return nil, errors.New("无效的用户角色")
+	}
+
 	// 检查用户名是否已存在
-	for _, user := range Users {
-		if strings.ToLower(user.Username) == strings.ToLower(username) {
-			return nil, errors.New("用户名已存在")
-		}
+	if existing, _ := GetRepositories().Users.GetByUsername(username); existing != nil {
+		return nil, errors.New("用户名已存在")
 	}
-	
+
 	// 检查邮箱是否已存在
-	for _, user := range Users {
-		if strings.ToLower(user.Email) == strings.ToLower(email) {
-			return nil, errors.New("邮箱已存在")
-		}
+	if existing, _ := GetRepositories().Users.GetByEmail(email); existing != nil {
+		return nil, errors.New("邮箱已存在")
 	}
-	
+
+	// 生成密码哈希
+	passwordHash, err := HashPassword(password)
+	if err != nil {
+		return nil, errors.New("密码处理失败")
+	}
+
 	// 创建用户
 	user := &User{
-		ID:           NextUserID,
 		Username:     username,
 		Email:        email,
-		PasswordHash: hashPassword(password),
+		PasswordHash: passwordHash,
 		Role:         role,
 	}
-	
-	// 添加到列表并递增ID
-	Users = append(Users, user)
-	NextUserID++
-	
+
+	if err := GetRepositories().Users.Create(user); err != nil {
+		return nil, err
+	}
+
 	return user, nil
 }
 
 // GetUserByID 根据ID获取用户
 func GetUserByID(id int) (*User, error) {
-	for _, user := range Users {
-		if user.ID == id {
-			return user, nil
-		}
-	}
-	return nil, errors.New("用户不存在")
+	return GetRepositories().Users.GetByID(id)
 }
 
 // GetUserByUsername 根据用户名获取用户
 func GetUserByUsername(username string) (*User, error) {
-	for _, user := range Users {
-		if strings.ToLower(user.Username) == strings.ToLower(username) {
-			return user, nil
-		}
-	}
-	return nil, errors.New("用户不存在")
+	return GetRepositories().Users.GetByUsername(username)
 }
 
 // GetUserByEmail 根据邮箱获取用户
 func GetUserByEmail(email string) (*User, error) {
-	for _, user := range Users {
-		if strings.ToLower(user.Email) == strings.ToLower(email) {
-			return user, nil
-		}
-	}
-	return nil, errors.New("用户不存在")
+	return GetRepositories().Users.GetByEmail(email)
 }
 
 // GetAllUsers 获取所有用户
 func GetAllUsers() []*User {
-	return Users
-}
-
-// 私有函数，用于密码哈希
-func hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-	return fmt.Sprintf("%x", hash)
-}
-
-// InitSampleUsers 初始化示例用户数据
-func InitSampleUsers() {
-	// 清空用户列表
-	userMutex.Lock()
-	defer userMutex.Unlock()
-	
-	Users = nil
-	NextUserID = 1
-	
-	// 创建管理员
-	admin := &User{
-		ID:           NextUserID,
-		Username:     "admin",
-		Email:        "admin@example.com",
-		PasswordHash: hashPassword("admin123"),
-		Role:         RoleAdmin,
-	}
-	Users = append(Users, admin)
-	NextUserID++
-	
-	// 创建图书管理员
-	librarian := &User{
-		ID:           NextUserID,
-		Username:     "librarian",
-		Email:        "librarian@example.com",
-		PasswordHash: hashPassword("librarian123"),
-		Role:         RoleLibrarian,
+	users, err := GetRepositories().Users.GetAll()
+	if err != nil {
+		log.Printf("获取用户列表失败: %v", err)
 	}
-	Users = append(Users, librarian)
-	NextUserID++
-	
-	// 创建读者
-	reader := &User{
-		ID:           NextUserID,
-		Username:     "reader",
-		Email:        "reader@example.com",
-		PasswordHash: hashPassword("reader123"),
-		Role:         RoleReader,
-	}
-	Users = append(Users, reader)
-	NextUserID++
-}
\ No newline at end of file
+	return users
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"strings"
+	"sync"
+)
+
+// MemoryUserRepository 基于内存切片的用户仓库实现
+type MemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  []*User
+	nextID int
+}
+
+// NewMemoryUserRepository 创建内存用户仓库
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{nextID: 1}
+}
+
+// Create 添加用户并分配ID
+func (r *MemoryUserRepository) Create(user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.ID = r.nextID
+	r.users = append(r.users, user)
+	r.nextID++
+	return nil
+}
+
+// Update 更新用户
+func (r *MemoryUserRepository) Update(user *User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, u := range r.users {
+		if u.ID == user.ID {
+			r.users[i] = user
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+// GetByID 根据ID获取用户
+func (r *MemoryUserRepository) GetByID(id int) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetByUsername 根据用户名获取用户（不区分大小写）
+func (r *MemoryUserRepository) GetByUsername(username string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if strings.EqualFold(user.Username, username) {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetByEmail 根据邮箱获取用户（不区分大小写）
+func (r *MemoryUserRepository) GetByEmail(email string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetAll 获取所有用户
+func (r *MemoryUserRepository) GetAll() ([]*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*User, len(r.users))
+	copy(result, r.users)
+	return result, nil
+}
+
+// InitSampleUsers 初始化示例用户数据
+func (r *MemoryUserRepository) InitSampleUsers() {
+	// 清空用户列表
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users = nil
+	r.nextID = 1
+
+	samples := []struct {
+		user     *User
+		password string
+	}{
+		// 创建管理员
+		{&User{Username: "admin", Email: "admin@example.com", Role: RoleAdmin}, "admin123"},
+		// 创建图书管理员
+		{&User{Username: "librarian", Email: "librarian@example.com", Role: RoleLibrarian}, "librarian123"},
+		// 创建读者
+		{&User{Username: "reader", Email: "reader@example.com", Role: RoleReader}, "reader123"},
+	}
+
+	for _, sample := range samples {
+		user := sample.user
+		user.PasswordHash, _ = HashPassword(sample.password)
+		user.ID = r.nextID
+		r.users = append(r.users, user)
+		r.nextID++
+	}
+}
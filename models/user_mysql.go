@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// userColumns 用户表查询列
+const userColumns = "id, username, email, password_hash, role"
+
+// MySQLUserRepository 基于MySQL的用户仓库实现
+type MySQLUserRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLUserRepository 创建MySQL用户仓库
+func NewMySQLUserRepository(db *sql.DB) *MySQLUserRepository {
+	return &MySQLUserRepository{db: db}
+}
+
+// Create 插入用户并回填ID
+func (r *MySQLUserRepository) Create(user *User) error {
+	result, err := r.db.Exec(
+		"INSERT INTO users (username, email, password_hash, role) VALUES (?, ?, ?, ?)",
+		user.Username, user.Email, user.PasswordHash, string(user.Role))
+	if err != nil {
+		return fmt.Errorf("创建用户失败: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("获取用户ID失败: %w", err)
+	}
+	user.ID = int(id)
+	return nil
+}
+
+// Update 更新用户
+func (r *MySQLUserRepository) Update(user *User) error {
+	_, err := r.db.Exec(
+		"UPDATE users SET username = ?, email = ?, password_hash = ?, role = ? WHERE id = ?",
+		user.Username, user.Email, user.PasswordHash, string(user.Role), user.ID)
+	if err != nil {
+		return fmt.Errorf("更新用户失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据ID获取用户
+func (r *MySQLUserRepository) GetByID(id int) (*User, error) {
+	return scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE id = ?", id))
+}
+
+// GetByUsername 根据用户名获取用户（依赖表的大小写不敏感排序规则）
+func (r *MySQLUserRepository) GetByUsername(username string) (*User, error) {
+	return scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE username = ?", username))
+}
+
+// GetByEmail 根据邮箱获取用户
+func (r *MySQLUserRepository) GetByEmail(email string) (*User, error) {
+	return scanUser(r.db.QueryRow("SELECT "+userColumns+" FROM users WHERE email = ?", email))
+}
+
+// GetAll 获取所有用户
+func (r *MySQLUserRepository) GetAll() ([]*User, error) {
+	rows, err := r.db.Query("SELECT " + userColumns + " FROM users ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// scanUser 扫描一行用户数据
+func scanUser(row rowScanner) (*User, error) {
+	user := &User{}
+	var role string
+	err := row.Scan(&user.ID, &user.Username, &user.Email, &user.PasswordHash, &role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	user.Role = UserRole(role)
+	return user, nil
+}
@@ -1,8 +1,10 @@
 package routes
 
 import (
+	htmltemplate "html/template"
 	"librarysystem/controllers"
 	"librarysystem/middleware"
+	"librarysystem/models"
 	"reflect"
 	"strings"
 	"text/template"
@@ -17,6 +19,7 @@ func SetupRouter() *gin.Engine {
     
     // 添加静态文件路由
     r.Static("/static", "./static")
+    r.GET("/covers/:name", controllers.CoverGet)
     
     r.SetFuncMap(template.FuncMap{
 		"isNil": func(i interface{}) bool {
@@ -47,6 +50,11 @@ func SetupRouter() *gin.Engine {
 		"add": func(a, b int) int {
 			return a + b
 		},
+		"formatAmount": models.FormatAmount,
+		"highlight": func(text, field, query string) htmltemplate.HTML {
+			// 检索结果高亮，Highlight 已对原文转义
+			return htmltemplate.HTML(models.Highlight(text, models.SearchField(field), query))
+		},
 		"formatDateTime": func(t time.Time) string {
 			return t.Format("2006-01-02 15:04") // 包含日期和时间
 		},
@@ -80,6 +88,11 @@ func SetupRouter() *gin.Engine {
 	r.GET("/", controllers.IndexGet)
 	r.GET("/books", controllers.BooksGet)
 	r.GET("/books/:id", controllers.BookDetailGet)
+	r.GET("/books/:id/shelf", controllers.BookShelfGet)
+	r.GET("/authors", controllers.AuthorsGet)
+	r.GET("/authors/:id", controllers.AuthorDetailGet)
+	r.GET("/series", controllers.SeriesListGet)
+	r.GET("/series/:id", controllers.SeriesDetailGet)
 	r.GET("/login", controllers.LoginGet)
 	r.POST("/login", controllers.LoginPost)
 	r.GET("/register", controllers.RegisterGet)
@@ -92,7 +105,67 @@ func SetupRouter() *gin.Engine {
 		api.GET("/books", controllers.APIBooksGet)
 		api.GET("/books/:id", controllers.APIBookGet)
 		api.GET("/categories", controllers.APICategoriesGet)
-		api.GET("/borrow-records", controllers.APIBorrowRecordsGet)
+		api.GET("/borrow-records", middleware.APIAuth(), middleware.APIRequireAuth(), controllers.APIBorrowRecordsGet)
+	}
+
+	// 版本化API路由，使用 Authorization: Bearer 或 X-API-Key 认证
+	v1 := r.Group("/api/v1")
+	v1.Use(middleware.APIAuth())
+	{
+		v1.POST("/auth/token", controllers.APIv1TokenPost)
+		v1.GET("/books", controllers.APIv1BooksGet)
+		v1.GET("/books/:id", controllers.APIv1BookGet)
+		v1.GET("/books/:id/shelf", controllers.APIv1BookShelfGet)
+		v1.GET("/books/:id/reviews", controllers.APIv1BookReviewsGet)
+		v1.GET("/categories", controllers.APIv1CategoriesGet)
+		v1.GET("/authors", controllers.APIv1AuthorsGet)
+		v1.GET("/authors/:id", controllers.APIv1AuthorGet)
+		v1.GET("/classifications", controllers.APIv1ClassificationsGet)
+		v1.GET("/tags", controllers.APIv1TagsGet)
+		v1.GET("/series", controllers.APIv1SeriesListGet)
+		v1.GET("/series/:id", controllers.APIv1SeriesGet)
+
+		authed := v1.Group("")
+		authed.Use(middleware.APIRequireAuth())
+		{
+			authed.GET("/auth/tokens", controllers.APIv1TokensGet)
+			authed.DELETE("/auth/tokens/:id", controllers.APIv1TokenDelete)
+			authed.GET("/me", controllers.APIv1MeGet)
+			authed.GET("/borrows", controllers.APIv1BorrowsGet)
+			authed.GET("/borrows/:id", controllers.APIv1BorrowGet)
+			authed.POST("/borrows", controllers.APIv1BorrowPost)
+			authed.POST("/borrows/:id/renew", controllers.APIv1RenewPost)
+			authed.POST("/books/:id/reviews", controllers.APIv1BookReviewPost)
+		}
+
+		librarianAPI := v1.Group("")
+		librarianAPI.Use(middleware.APIRequireLibrarian())
+		{
+			librarianAPI.POST("/borrows/:id/return", controllers.APIv1ReturnPost)
+			librarianAPI.GET("/reviews", controllers.APIv1ReviewsGet)
+			librarianAPI.PUT("/reviews/:id", controllers.APIv1ReviewPut)
+		}
+
+		adminAPI := v1.Group("")
+		adminAPI.Use(middleware.APIRequireAdmin())
+		{
+			adminAPI.GET("/books/export", controllers.APIv1BooksExportGet)
+			adminAPI.GET("/metadata/:isbn", controllers.APIv1MetadataGet)
+			adminAPI.POST("/books", controllers.APIv1BookPost)
+			adminAPI.PUT("/books/:id", controllers.APIv1BookPut)
+			adminAPI.DELETE("/books/:id", controllers.APIv1BookDelete)
+			adminAPI.PUT("/books/:id/cover", controllers.APIv1BookCoverPut)
+			adminAPI.DELETE("/books/:id/cover", controllers.APIv1BookCoverDelete)
+			adminAPI.PUT("/authors/:id", controllers.APIv1AuthorPut)
+			adminAPI.PUT("/tags/:id", controllers.APIv1TagPut)
+			adminAPI.POST("/tags/:id/merge", controllers.APIv1TagMergePost)
+			adminAPI.PUT("/series/:id", controllers.APIv1SeriesPut)
+			adminAPI.DELETE("/series/:id", controllers.APIv1SeriesDelete)
+			adminAPI.GET("/users", controllers.APIv1UsersGet)
+			adminAPI.GET("/users/:id", controllers.APIv1UserGet)
+			adminAPI.POST("/users", controllers.APIv1UserPost)
+			adminAPI.PUT("/users/:id/role", controllers.APIv1UserRolePut)
+		}
 	}
 
 	// 需要登录的路由
@@ -110,10 +183,34 @@ func SetupRouter() *gin.Engine {
 		admin.GET("/users", controllers.AdminUsersGet)
 		admin.GET("/add-book", controllers.AdminAddBookGet)
 		admin.POST("/add-book", controllers.AdminAddBookPost)
+		admin.GET("/book-metadata", controllers.AdminBookMetadataGet)
+		admin.GET("/export-books", controllers.AdminExportBooksGet)
+		admin.GET("/import-books", controllers.AdminImportBooksGet)
+		admin.POST("/import-books", controllers.AdminImportBooksPost)
+		admin.POST("/import-books/confirm", controllers.AdminImportBooksConfirmPost)
 		admin.GET("/edit-book/:id", controllers.AdminEditBookGet)
 		admin.POST("/edit-book/:id", controllers.AdminEditBookPost)
+		admin.GET("/edit-author/:id", controllers.AdminEditAuthorGet)
+		admin.POST("/edit-author/:id", controllers.AdminEditAuthorPost)
 		admin.GET("/delete-book/:id", controllers.AdminDeleteBookGet)
 		admin.GET("/change-user-role/:id/:role", controllers.AdminChangeUserRoleGet)
+		admin.GET("/policies", controllers.AdminPoliciesGet)
+		admin.GET("/add-policy", controllers.AdminAddPolicyGet)
+		admin.POST("/add-policy", controllers.AdminAddPolicyPost)
+		admin.GET("/edit-policy/:id", controllers.AdminEditPolicyGet)
+		admin.POST("/edit-policy/:id", controllers.AdminEditPolicyPost)
+		admin.GET("/delete-policy/:id", controllers.AdminDeletePolicyGet)
+		admin.GET("/classifications", controllers.AdminClassificationsGet)
+		admin.POST("/classifications", controllers.AdminAddClassificationPost)
+		admin.POST("/classifications/:id", controllers.AdminEditClassificationPost)
+		admin.POST("/classifications/:id/delete", controllers.AdminDeleteClassificationPost)
+		admin.GET("/tags", controllers.AdminTagsGet)
+		admin.POST("/tags/:id", controllers.AdminEditTagPost)
+		admin.POST("/tags/:id/merge", controllers.AdminMergeTagPost)
+		admin.POST("/tags/:id/delete", controllers.AdminDeleteTagPost)
+		admin.GET("/series", controllers.AdminSeriesGet)
+		admin.POST("/series/:id", controllers.AdminEditSeriesPost)
+		admin.POST("/series/:id/delete", controllers.AdminDeleteSeriesPost)
 	}
 
 	// 图书管理员路由
@@ -124,6 +221,18 @@ func SetupRouter() *gin.Engine {
 		librarian.GET("/borrow", controllers.LibrarianBorrowGet)
 		librarian.POST("/create-borrow", controllers.LibrarianCreateBorrowPost)
 		librarian.GET("/return-book/:id", controllers.LibrarianReturnBookGet)
+		librarian.GET("/renew/:id", controllers.LibrarianRenewGet)
+		librarian.GET("/holds", controllers.LibrarianHoldsGet)
+		librarian.GET("/cancel-hold/:id", controllers.LibrarianCancelHoldGet)
+		librarian.GET("/expire-holds", controllers.LibrarianExpireHoldsGet)
+		librarian.GET("/copies/:id", controllers.LibrarianCopiesGet)
+		librarian.POST("/copies/:id", controllers.LibrarianAddCopyPost)
+		librarian.POST("/copies/:id/shelving", controllers.LibrarianShelvingPost)
+		librarian.POST("/copy/:id", controllers.LibrarianUpdateCopyPost)
+		librarian.GET("/fines", controllers.LibrarianFinesGet)
+		librarian.POST("/fines/:id", controllers.LibrarianFinesPost)
+		librarian.GET("/reviews", controllers.LibrarianReviewsGet)
+		librarian.POST("/reviews/:id", controllers.LibrarianModerateReviewPost)
 	}
 
 	// 读者路由
@@ -134,6 +243,12 @@ func SetupRouter() *gin.Engine {
 		reader.GET("/borrow/:id", controllers.ReaderBorrowGet)
 		reader.GET("/borrowed", controllers.ReaderBorrowedGet)
 		reader.GET("/return-book/:id", controllers.ReaderReturnBookGet)
+		reader.GET("/renew/:id", controllers.ReaderRenewGet)
+		reader.GET("/hold/:id", controllers.ReaderHoldGet)
+		reader.GET("/holds", controllers.ReaderHoldsGet)
+		reader.GET("/cancel-hold/:id", controllers.ReaderCancelHoldGet)
+		reader.GET("/fines", controllers.ReaderFinesGet)
+		reader.POST("/review/:id", controllers.ReaderReviewPost)
 	}
 
 	return r
@@ -4,8 +4,10 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
-	"time"
 	"log"
+	"sync"
+	"time"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,11 +31,12 @@ type Session interface {
 	Save()
 }
 
-// MemorySession 内存会话实现
+// MemorySession 会话实现，数据保存在所属的 SessionStore 中
 type MemorySession struct {
 	id     string
 	data   map[string]interface{}
 	expiry time.Time
+	store  SessionStore
 }
 
 // SessionStore 会话存储接口
@@ -50,21 +53,52 @@ type SessionManager struct {
 	ctx   *gin.Context
 }
 
+// 会话有效期
+const sessionLifetime = 24 * time.Hour
+
+// contextKeySessionID 当前请求会话ID在gin上下文中的键名
+const contextKeySessionID = "_session_id"
+
+var (
+	defaultStore      SessionStore = NewMemorySessionStore()
+	defaultStoreMutex sync.RWMutex
+)
+
+// SetSessionStore 设置全局会话存储（程序启动时调用）
+func SetSessionStore(store SessionStore) {
+	defaultStoreMutex.Lock()
+	defer defaultStoreMutex.Unlock()
+	defaultStore = store
+}
+
+// GetSessionStore 获取全局会话存储
+func GetSessionStore() SessionStore {
+	defaultStoreMutex.RLock()
+	defer defaultStoreMutex.RUnlock()
+	return defaultStore
+}
+
 // NewSessionManager 创建会话管理器
 func NewSessionManager(c *gin.Context) *SessionManager {
 	return &SessionManager{
-		store: &MemorySessionStore{
-			Sessions: make(map[string]*SessionItem),
-		},
-		ctx: c,
+		store: GetSessionStore(),
+		ctx:   c,
 	}
 }
 
 // MemorySessionStore 内存会话存储实现
 type MemorySessionStore struct {
+	mu       sync.RWMutex
 	Sessions map[string]*SessionItem
 }
 
+// NewMemorySessionStore 创建内存会话存储
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		Sessions: make(map[string]*SessionItem),
+	}
+}
+
 // SessionItem struct
 type SessionItem struct {
 	Data   map[string]interface{}
@@ -73,26 +107,38 @@ type SessionItem struct {
 
 // 会话存储相关方法实现
 func (s *MemorySessionStore) Get(sessionID string) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	if item, exists := s.Sessions[sessionID]; exists && time.Now().Before(item.Expiry) {
-		return item.Data, nil
+		return copySessionData(item.Data), nil
 	}
 	return nil, fmt.Errorf("session not found or expired")
 }
 
 func (s *MemorySessionStore) Save(sessionID string, data map[string]interface{}, expiry time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.Sessions[sessionID] = &SessionItem{
-		Data:   data,
+		Data:   copySessionData(data),
 		Expiry: expiry,
 	}
 	return nil
 }
 
 func (s *MemorySessionStore) Delete(sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	delete(s.Sessions, sessionID)
 	return nil
 }
 
 func (s *MemorySessionStore) ClearExpired() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	now := time.Now()
 	for id, session := range s.Sessions {
 		if now.After(session.Expiry) {
@@ -102,6 +148,15 @@ func (s *MemorySessionStore) ClearExpired() error {
 	return nil
 }
 
+// copySessionData 复制会话数据，避免请求间共享同一个map
+func copySessionData(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+	return result
+}
+
 // MemorySession方法实现
 func (s *MemorySession) Get(key string) (interface{}, bool) {
 	val, exists := s.data[key]
@@ -121,15 +176,24 @@ func (s *MemorySession) Clear() {
 }
 
 func (s *MemorySession) Save() {
-	// 实现保存逻辑
+	s.expiry = time.Now().Add(sessionLifetime)
+	if err := s.store.Save(s.id, s.data, s.expiry); err != nil {
+		log.Printf("保存会话失败: %v\n", err)
+	}
 }
 
 // GetSession 获取当前会话
 func (sm *SessionManager) GetSession(c *gin.Context) Session {
-	sessionID, err := c.Cookie("session_id")
-	if err != nil || sessionID == "" {
-		sessionID = generateSessionID()
-		c.SetCookie("session_id", sessionID, 86400, "/", "", false, true)
+	sessionID := c.GetString(contextKeySessionID)
+	if sessionID == "" {
+		cookie, err := c.Cookie("session_id")
+		if err != nil || cookie == "" {
+			// 同一请求内复用新生成的会话ID，避免多次下发Cookie
+			cookie = generateSessionID()
+			c.SetCookie("session_id", cookie, int(sessionLifetime.Seconds()), "/", "", false, true)
+		}
+		sessionID = cookie
+		c.Set(contextKeySessionID, sessionID)
 	}
 
 	data, err := sm.store.Get(sessionID)
@@ -140,7 +204,8 @@ func (sm *SessionManager) GetSession(c *gin.Context) Session {
 	session := &MemorySession{
 		id:     sessionID,
 		data:   data,
-		expiry: time.Now().Add(24 * time.Hour),
+		expiry: time.Now().Add(sessionLifetime),
+		store:  sm.store,
 	}
 
 	return session
@@ -256,19 +321,17 @@ func (sm *SessionManager) GetFlashMessage(c *gin.Context, key string) string {
 	return message.(string)
 }
 
-// generateSessionID 生成随机会话ID
+// generateSessionID 生成随机会话ID（URL安全编码，便于存入Cookie和数据库）
 func generateSessionID() string {
 	b := make([]byte, 32)
 	rand.Read(b)
-	return base64.StdEncoding.EncodeToString(b)
+	return base64.RawURLEncoding.EncodeToString(b)
 }
 
 // CleanupExpiredSessions 清理过期的会话
 func CleanupExpiredSessions() {
-	// 获取默认存储实例
-	store := &MemorySessionStore{
-		Sessions: make(map[string]*SessionItem),
-	}
+	// 获取全局存储实例
+	store := GetSessionStore()
 
 	// 调用清理方法
 	if err := store.ClearExpired(); err != nil {
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MySQLSessionStore 基于MySQL sessions 表的会话存储实现，
+// 会话数据使用 gob 编码，可在重启和多实例之间共享
+type MySQLSessionStore struct {
+	db *sql.DB
+}
+
+// NewMySQLSessionStore 创建MySQL会话存储
+func NewMySQLSessionStore(db *sql.DB) *MySQLSessionStore {
+	return &MySQLSessionStore{db: db}
+}
+
+// Get 读取未过期的会话数据
+func (s *MySQLSessionStore) Get(sessionID string) (map[string]interface{}, error) {
+	var payload []byte
+	err := s.db.QueryRow(
+		"SELECT data FROM sessions WHERE id = ? AND expiry > ?",
+		sessionID, time.Now()).Scan(&payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("session not found or expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{})
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&data); err != nil {
+		return nil, fmt.Errorf("解码会话数据失败: %w", err)
+	}
+	return data, nil
+}
+
+// Save 写入或覆盖会话数据
+func (s *MySQLSessionStore) Save(sessionID string, data map[string]interface{}, expiry time.Time) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return fmt.Errorf("编码会话数据失败: %w", err)
+	}
+
+	_, err := s.db.Exec(`
+		INSERT INTO sessions (id, data, expiry) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE data = VALUES(data), expiry = VALUES(expiry)`,
+		sessionID, buf.Bytes(), expiry)
+	return err
+}
+
+// Delete 删除会话
+func (s *MySQLSessionStore) Delete(sessionID string) error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE id = ?", sessionID)
+	return err
+}
+
+// ClearExpired 删除所有过期会话
+func (s *MySQLSessionStore) ClearExpired() error {
+	_, err := s.db.Exec("DELETE FROM sessions WHERE expiry <= ?", time.Now())
+	return err
+}